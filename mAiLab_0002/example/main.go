@@ -1,22 +1,38 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 
 	"github.com/oneleo/LeNetPractice/mAiLab_0002/myrandom"
+	"github.com/oneleo/LeNetPractice/mAiLab_0002/rand_fromgo"
 )
 
 func main() {
-	ran := myrandom.MultiRdmFlt64(0, 1, 5)
-	m1, s1 := myrandom.RdmFlt64(-1, 1, 100)
-	m2, s2 := myrandom.MyRdmFlt64(-1, 1, 100)
+	// -seed 不為 0 時以固定的種子產生亂數，每次執行的結果都相同；為 0 時以目前時間作為種子。
+	seed := flag.Int64("seed", 0, "random seed, 0 to seed from the current time")
+	flag.Parse()
+
+	var ran []float64
+	var m1, s1, m2, s2 float64
+	if *seed != 0 {
+		rnd := myrandom.NewRand(rand_fromgo.NewSource(*seed))
+		ran = myrandom.MultiRdmFlt64With(rnd, 0, 1, 5)
+		m1, s1 = myrandom.RdmFlt64With(rnd, -1, 1, 100)
+		// 第 4 題使用自己的亂數產生器，種子與 MyRdmFlt64 一樣加上 1357，不與第 1、2 題共用同一個數列。
+		m2, s2 = myrandom.MyRdmFlt64With(rand_fromgo.NewSource(*seed+1357), -1, 1, 100)
+	} else {
+		ran = myrandom.MultiRdmFlt64(0, 1, 5)
+		m1, s1 = myrandom.RdmFlt64(-1, 1, 100)
+		m2, s2 = myrandom.MyRdmFlt64(-1, 1, 100)
+	}
 
 	fmt.Println("1、產生五個亂數，並將其輸出。")
-	fmt.Println(ran, "\n")
+	fmt.Print(ran, "\n\n")
 
 	fmt.Println("2、產生 N 個介於 -1 與 1 之間的亂數，計算其平均值與標準差並輸出，每個亂數的值則不用輸出。")
-	fmt.Println(m1, s1, "\n")
+	fmt.Print(m1, " ", s1, "\n\n")
 
 	fmt.Println("4、自己寫一個亂數產生器。")
-	fmt.Println(m2, s2, "\n")
+	fmt.Print(m2, " ", s2, "\n\n")
 }
@@ -0,0 +1,254 @@
+package myrandom
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/image/bmp"
+)
+
+// region type
+
+// Density 是機率密度函數，用來在直方圖上疊加預期的分佈。
+type Density func(x float64) float64
+
+// endregion type
+
+// region struct
+
+// Histogram 將樣本分到 [Min, Max) 之間等寬的區間並計數，同時以 Stats 累計所有樣本的統計量。
+type Histogram struct {
+	Min    float64
+	Max    float64
+	Counts []int64
+	// Under、Over 為小於 Min 與不小於 Max（包含 NaN）的樣本數。
+	Under int64
+	Over  int64
+	Stats Stats
+}
+
+// endregion struct
+
+// region function
+
+// NewHistogram 函數會建立一個將 [min, max) 分成 bins 個等寬區間的直方圖。
+func NewHistogram(min, max float64, bins int) (h *Histogram, err error) {
+	if bins < 1 {
+		return nil, errors.New("Error: histogram needs at least one bin")
+	}
+	if !(min < max) || math.IsInf(min, 0) || math.IsInf(max, 0) {
+		return nil, fmt.Errorf("Error: invalid histogram range [%g, %g)", min, max)
+	}
+	return &Histogram{Min: min, Max: max, Counts: make([]int64, bins)}, nil
+}
+
+// UniformDensity 函數會回傳 [min, max) 均勻分佈的機率密度函數。
+func UniformDensity(min, max float64) Density {
+	return func(x float64) float64 {
+		if x < min || x >= max {
+			return 0
+		}
+		return 1 / (max - min)
+	}
+}
+
+// NormalDensity 函數會回傳平均值 mean、標準差 std 的常態分佈機率密度函數。
+func NormalDensity(mean, std float64) Density {
+	return func(x float64) float64 {
+		z := (x - mean) / std
+		return math.Exp(-z*z/2) / (std * math.Sqrt(2*math.Pi))
+	}
+}
+
+// WriteImage 函數是將 img 灰階影像依 dstFile 的副檔名（.bmp 或 .png）編碼後儲存成檔案。
+func WriteImage(dstFile string, img *image.Gray) (err error) {
+	// 根據作業系統調整路徑的正反鈄線，以及將目錄路徑轉換成絕對路徑。
+	dstFile, err = filepath.Abs(dstFile)
+	if err != nil {
+		fmt.Println("Error while finding absolute path", dstFile, "-", err)
+		return err
+	}
+	var encode func(w io.Writer, m image.Image) error
+	switch strings.ToLower(filepath.Ext(dstFile)) {
+	case ".bmp":
+		encode = bmp.Encode
+	case ".png":
+		encode = png.Encode
+	default:
+		return fmt.Errorf("Error: unsupported image format %q", filepath.Ext(dstFile))
+	}
+
+	// 建立一個新檔作為將上述灰階影像變數存取成目標檔。
+	file, err := os.Create(dstFile)
+	if err != nil {
+		fmt.Println("Error while creating", dstFile, "-", err)
+		return err
+	}
+	if err = encode(file, img); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// endregion function
+
+// region method
+
+// Add 會加入一個樣本。
+func (h *Histogram) Add(x float64) {
+	h.Stats.Add(x)
+	switch {
+	case x < h.Min:
+		h.Under++
+	case !(x < h.Max):
+		h.Over++
+	default:
+		i := int((x - h.Min) / h.BinWidth())
+		// 浮點數誤差可能讓非常接近 Max 的樣本算到最後一格之外。
+		if i >= len(h.Counts) {
+			i = len(h.Counts) - 1
+		}
+		h.Counts[i]++
+	}
+}
+
+// Fill 會呼叫 sample 產生 num 個樣本並加入直方圖，例如 h.Fill(rnd.NormFloat64, 100000)。
+func (h *Histogram) Fill(sample func() float64, num int) {
+	for n := 0; n < num; n++ {
+		h.Add(sample())
+	}
+}
+
+// N 會回傳加入的樣本總數（包含範圍外的樣本）。
+func (h *Histogram) N() int64 { return h.Stats.N() }
+
+// BinWidth 會回傳每一個區間的寬度。
+func (h *Histogram) BinWidth() float64 {
+	return (h.Max - h.Min) / float64(len(h.Counts))
+}
+
+// Bin 會回傳第 i 個區間的範圍 [lo, hi)。
+func (h *Histogram) Bin(i int) (lo, hi float64) {
+	w := h.BinWidth()
+	return h.Min + float64(i)*w, h.Min + float64(i+1)*w
+}
+
+// Density 會回傳第 i 個區間的估計機率密度（計數 / (樣本總數 × 區間寬度)），可以直接與 Density 函數比較。
+func (h *Histogram) Density(i int) float64 {
+	if h.N() == 0 {
+		return 0
+	}
+	return float64(h.Counts[i]) / (float64(h.N()) * h.BinWidth())
+}
+
+// Expected 會回傳以 density 計算的第 i 個區間預期機率密度（以 Simpson 法則取區間平均）。
+func (h *Histogram) Expected(i int, density Density) float64 {
+	lo, hi := h.Bin(i)
+	// 使用略小於 hi 的點，讓均勻分佈在右端點為 0 時不會低估最後一格。
+	end := hi - (hi-lo)*1e-9
+	return (density(lo) + 4*density((lo+hi)/2) + density(end)) / 6
+}
+
+// WriteBars 會將直方圖以文字長條圖寫入 w，每一行為一個區間，最長的長條為 width 個字元。
+// expected 不為 nil 時，以 "|" 標出每個區間的預期位置，並在行尾列出預期的樣本數。width 小於 1 時回傳錯誤。
+func (h *Histogram) WriteBars(w io.Writer, width int, expected Density) (err error) {
+	if width < 1 {
+		return fmt.Errorf("Error: bar width must be at least 1, got %d", width)
+	}
+	scale := h.maxDensity(expected)
+	for i, c := range h.Counts {
+		lo, hi := h.Bin(i)
+		bar := []byte(strings.Repeat(" ", width+1))
+		n := 0
+		if scale > 0 {
+			n = int(math.Round(h.Density(i) / scale * float64(width)))
+		}
+		for k := 0; k < n; k++ {
+			bar[k] = '#'
+		}
+		note := ""
+		if expected != nil {
+			e := h.Expected(i, expected)
+			if scale > 0 {
+				bar[int(math.Round(e/scale*float64(width)))] = '|'
+			}
+			note = fmt.Sprintf(" (expected %.1f)", e*float64(h.N())*h.BinWidth())
+		}
+		if _, err = fmt.Fprintf(w, "[%10.4g, %10.4g) %s %d%s\n", lo, hi, bar, c, note); err != nil {
+			return err
+		}
+	}
+	if h.Under > 0 || h.Over > 0 {
+		_, err = fmt.Fprintf(w, "out of range: %d below, %d above\n", h.Under, h.Over)
+	}
+	return err
+}
+
+// Image 會將直方圖畫成 width x height 的灰階影像：白色背景、灰色長條，
+// expected 不為 nil 時以黑色曲線疊加預期的機率密度。
+func (h *Histogram) Image(width, height int, expected Density) *image.Gray {
+	img := image.NewGray(image.Rect(0, 0, width, height))
+	for i := range img.Pix {
+		img.Pix[i] = 255
+	}
+	scale := h.maxDensity(expected)
+	// 曲線以每一欄中心的密度繪製，其最大值可能略高於區間平均，也要納入比例尺以免超出影像。
+	center := func(x int) float64 { return h.Min + (float64(x)+0.5)/float64(width)*(h.Max-h.Min) }
+	for x := 0; expected != nil && x < width; x++ {
+		scale = math.Max(scale, expected(center(x)))
+	}
+	if scale == 0 {
+		return img
+	}
+	// y 函數會將機率密度換算成影像的列，密度 0 在最下面一列，scale 在最上面一列。
+	y := func(d float64) int {
+		return height - 1 - int(math.Round(d/scale*float64(height-1)))
+	}
+	bar := color.Gray{Y: 160}
+	for x := 0; x < width; x++ {
+		i := x * len(h.Counts) / width
+		for r := y(h.Density(i)); r < height; r++ {
+			img.SetGray(x, r, bar)
+		}
+	}
+	if expected != nil {
+		prev := -1
+		for x := 0; x < width; x++ {
+			r := y(expected(center(x)))
+			// 以垂直線段連接相鄰兩欄的點，讓陡峭處的曲線也是連續的。
+			from, to := r, r
+			if prev >= 0 && prev < r {
+				from = prev
+			} else if prev > r {
+				to = prev
+			}
+			for k := from; k <= to; k++ {
+				img.SetGray(x, k, color.Gray{Y: 0})
+			}
+			prev = r
+		}
+	}
+	return img
+}
+
+// maxDensity 會回傳直方圖與預期密度中最大的機率密度，作為長條圖的比例尺。
+func (h *Histogram) maxDensity(expected Density) (m float64) {
+	for i := range h.Counts {
+		m = math.Max(m, h.Density(i))
+		if expected != nil {
+			m = math.Max(m, h.Expected(i, expected))
+		}
+	}
+	return m
+}
+
+// endregion method
@@ -0,0 +1,159 @@
+package myrandom
+
+import (
+	"bytes"
+	"image/png"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/oneleo/LeNetPractice/mAiLab_0002/rand_fromgo"
+)
+
+// Test_Histogram 是測試樣本會計入正確的區間，範圍外與 NaN 的樣本計入 Under、Over。
+func Test_Histogram(t *testing.T) {
+	h, err := NewHistogram(0, 1, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, x := range []float64{0, 0.1, 0.25, 0.3, 0.74, 0.75, 0.999, -0.1, 1, math.NaN()} {
+		h.Add(x)
+	}
+	want := []int64{2, 2, 1, 2}
+	for i := range want {
+		if h.Counts[i] != want[i] {
+			t.Errorf("Error counts %v, should be %v.", h.Counts, want)
+			break
+		}
+	}
+	if h.Under != 1 || h.Over != 2 || h.N() != 10 {
+		t.Errorf("Error under %d, over %d, n %d, should be 1, 2 and 10.", h.Under, h.Over, h.N())
+	}
+	if lo, hi := h.Bin(2); lo != 0.5 || hi != 0.75 {
+		t.Errorf("Error bin 2 = [%g, %g), should be [0.5, 0.75).", lo, hi)
+	}
+
+	// 定義測試集 Struct，不合法的參數都應回傳錯誤。
+	var tests = []struct {
+		min, max float64
+		bins     int
+	}{
+		{0, 1, 0},
+		{1, 1, 10},
+		{2, 1, 10},
+		{math.Inf(-1), 1, 10},
+	}
+	for _, test := range tests {
+		if _, err := NewHistogram(test.min, test.max, test.bins); err == nil {
+			t.Errorf("Error NewHistogram(%g, %g, %d) should return an error.", test.min, test.max, test.bins)
+		}
+	}
+}
+
+// Test_HistogramDensity 是測試均勻與常態亂數的直方圖密度接近預期的機率密度。
+func Test_HistogramDensity(t *testing.T) {
+	rnd := rand_fromgo.New(rand_fromgo.NewSource(1))
+	// 定義測試集 Struct。
+	var tests = []struct {
+		name     string
+		min, max float64
+		sample   func() float64
+		density  Density
+	}{
+		{"uniform", -1, 1, func() float64 { return rnd.Uniform(-1, 1) }, UniformDensity(-1, 1)},
+		{"normal", -4, 4, rnd.NormFloat64, NormalDensity(0, 1)},
+	}
+	for _, test := range tests {
+		h, _ := NewHistogram(test.min, test.max, 20)
+		h.Fill(test.sample, 200000)
+		for i := range h.Counts {
+			got, want := h.Density(i), h.Expected(i, test.density)
+			// 容許約 5 個標準誤，標準誤以二項分佈估計。
+			p := want * h.BinWidth()
+			tol := 5 * math.Sqrt(p*(1-p)/float64(h.N())) / h.BinWidth()
+			if math.Abs(got-want) > tol+1e-3 {
+				t.Errorf("Error %s bin %d density %g, should be %g ± %g.", test.name, i, got, want, tol)
+			}
+		}
+	}
+}
+
+// Test_WriteBars 是測試文字長條圖每個區間一行，最長的長條為 width 個字元，並標出預期位置。
+func Test_WriteBars(t *testing.T) {
+	h, _ := NewHistogram(-3, 3, 12)
+	rnd := rand_fromgo.New(rand_fromgo.NewSource(2))
+	h.Fill(rnd.NormFloat64, 10000)
+	var buf bytes.Buffer
+	if err := h.WriteBars(&buf, 40, NormalDensity(0, 1)); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimRight(buf.String(), "\n"), "\n")
+	// 12 個區間，加上一行範圍外的樣本數。
+	if len(lines) != 13 || !strings.HasPrefix(lines[12], "out of range") {
+		t.Fatalf("Error bar chart:\n%s", buf.String())
+	}
+	longest := 0
+	for _, line := range lines[:12] {
+		if !strings.Contains(line, "|") || !strings.Contains(line, "expected") {
+			t.Errorf("Error line should mark the expected density: %q", line)
+		}
+		longest = max(longest, strings.Count(line, "#")+strings.Count(line, "|")-1)
+	}
+	if longest < 38 || longest > 40 {
+		t.Errorf("Error longest bar is %d characters, should be about 40:\n%s", longest, buf.String())
+	}
+	// 寬度小於 1 時回傳錯誤，而不是 panic。
+	for _, width := range []int{0, -5} {
+		if err := h.WriteBars(&buf, width, nil); err == nil {
+			t.Errorf("Error width %d should return an error.", width)
+		}
+	}
+}
+
+// Test_HistogramImage 是測試影像的長條與預期密度曲線，以及 BMP、PNG 檔案的寫入。
+func Test_HistogramImage(t *testing.T) {
+	h, _ := NewHistogram(0, 1, 10)
+	rnd := rand_fromgo.New(rand_fromgo.NewSource(3))
+	h.Fill(rnd.Float64, 10000)
+	img := h.Image(200, 100, UniformDensity(0, 1))
+	if b := img.Bounds(); b.Dx() != 200 || b.Dy() != 100 {
+		t.Fatalf("Error image size %v, should be 200x100.", b)
+	}
+	// 左上角為背景，最下面一列為長條，中間某一列有黑色的預期密度曲線。
+	if img.GrayAt(0, 0).Y != 255 || img.GrayAt(100, 99).Y != 160 {
+		t.Errorf("Error background %d or bar %d.", img.GrayAt(0, 0).Y, img.GrayAt(100, 99).Y)
+	}
+	black := 0
+	for r := 0; r < 100; r++ {
+		if img.GrayAt(100, r).Y == 0 {
+			black++
+		}
+	}
+	if black != 1 {
+		t.Errorf("Error column 100 has %d curve pixels, should be 1.", black)
+	}
+
+	dir := t.TempDir()
+	for _, name := range []string{"hist.bmp", "hist.png"} {
+		if err := WriteImage(filepath.Join(dir, name), img); err != nil {
+			t.Fatal(err)
+		}
+	}
+	file, err := os.Open(filepath.Join(dir, "hist.png"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	decoded, err := png.Decode(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r, _, _, _ := decoded.At(100, 99).RGBA(); r>>8 != 160 {
+		t.Errorf("Error decoded PNG pixel %d, should be 160.", r>>8)
+	}
+	if err = WriteImage(filepath.Join(dir, "hist.jpg"), img); err == nil {
+		t.Errorf("Error unsupported format should return an error.")
+	}
+}
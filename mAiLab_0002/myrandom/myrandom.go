@@ -9,9 +9,17 @@ import (
 	"github.com/oneleo/LeNetPractice/mAiLab_0002/rand_fromgo"
 )
 
+// region const
+
+// DefaultSeed 為 NewRand 在沒有指定 Source 時使用的種子，以 DefaultSeed 產生的結果每次執行都相同。
+const DefaultSeed int64 = 1
+
+// endregion const
+
 // region function
 
 // MultiRdmFlt64 會回傳一個亂數 Slice，Slice 元素個數為 num 個 [min, max) 範圍內的亂數。
+// 每次呼叫都會以目前時間重設 math/rand 的全域種子，結果無法重現；需要重現時請改用 MultiRdmFlt64With。
 func MultiRdmFlt64(min, max float64, num uint8) (out []float64) {
 	// 以目前時間作為亂度種子。
 	rand.Seed(time.Now().UnixNano() + 1234)
@@ -25,6 +33,7 @@ func MultiRdmFlt64(min, max float64, num uint8) (out []float64) {
 }
 
 // RdmFlt64 會回傳計算 num 個亂數的 mean 值以及 sigma 值。
+// 每次呼叫都會以目前時間重設 math/rand 的全域種子，結果無法重現；需要重現時請改用 RdmFlt64With。
 func RdmFlt64(min, max float64, num uint8) (mean, sigma float64) {
 	// 以目前時間作為亂度種子。
 	rand.Seed(time.Now().UnixNano() + 2468)
@@ -45,13 +54,14 @@ func RdmFlt64(min, max float64, num uint8) (mean, sigma float64) {
 		// 計算差平方，並加總。
 		sigma += math.Pow(rnd[n]-mean, 2)
 	}
-	// 計算標準差。
-	sigma = math.Sqrt(sigma / float64(num-1))
+	// 計算標準差，樣本數小於 2 時為 NaN。
+	sigma = sampleStd(sigma, int64(num))
 
 	return mean, sigma
 }
 
 // MyRdmFlt64 會回傳計算 num 個亂數的 mean 值以及 sigma 值。
+// 每次呼叫都會以目前時間重設 rand_fromgo 的全域種子，結果無法重現；需要重現時請改用 MyRdmFlt64With。
 func MyRdmFlt64(min, max float64, num uint8) (mean, sigma float64) {
 	// 以目前時間作為亂度種子。
 	rand_fromgo.Seed(time.Now().UnixNano() + 1357)
@@ -72,10 +82,61 @@ func MyRdmFlt64(min, max float64, num uint8) (mean, sigma float64) {
 		// 計算差平方，並加總。
 		sigma += math.Pow(rnd[n]-mean, 2)
 	}
-	// 計算標準差。
-	sigma = math.Sqrt(sigma / float64(num-1))
+	// 計算標準差，樣本數小於 2 時為 NaN。
+	sigma = sampleStd(sigma, int64(num))
 
 	return mean, sigma
 }
 
+// NewRand 函數會回傳以 src 產生亂數的 *rand_fromgo.Rand；src 為 nil 時使用以 DefaultSeed 為種子的 rand_fromgo.NewSource。
+func NewRand(src rand_fromgo.Source) *rand_fromgo.Rand {
+	if src == nil {
+		src = rand_fromgo.NewSource(DefaultSeed)
+	}
+	return rand_fromgo.New(src)
+}
+
+// MultiRdmFlt64With 與 MultiRdmFlt64 相同，但以 rnd 產生亂數且不會重設任何種子；rnd 為 nil 時使用 NewRand(nil)。
+// 相同狀態的 rnd 會得到相同的結果。
+func MultiRdmFlt64With(rnd *rand_fromgo.Rand, min, max float64, num uint8) (out []float64) {
+	if rnd == nil {
+		rnd = NewRand(nil)
+	}
+	out = make([]float64, num)
+	for n := uint8(0); n < num; n++ {
+		// 產生一個 [min, max) 範圍內的亂數。
+		out[n] = (max-min)*rnd.Float64() + min
+	}
+	return out
+}
+
+// RdmFlt64With 與 RdmFlt64 相同，但以 rnd 產生亂數且不會重設任何種子；rnd 為 nil 時使用 NewRand(nil)。
+// 相同狀態的 rnd 會得到相同的結果。
+func RdmFlt64With(rnd *rand_fromgo.Rand, min, max float64, num uint8) (mean, sigma float64) {
+	rands := MultiRdmFlt64With(rnd, min, max, num)
+	// 定義一個加總用變數。
+	sum := float64(0.0)
+	for _, r := range rands {
+		sum += r
+	}
+	// 計算平均值。
+	mean = sum / float64(num)
+
+	sigma = 0.0
+	for _, r := range rands {
+		// 計算差平方，並加總。
+		sigma += math.Pow(r-mean, 2)
+	}
+	// 計算標準差，樣本數小於 2 時為 NaN。
+	sigma = sampleStd(sigma, int64(num))
+
+	return mean, sigma
+}
+
+// MyRdmFlt64With 與 MyRdmFlt64 相同，但以 src 建立自己的 rand_fromgo 亂數產生器，不會重設 rand_fromgo 的全域種子；
+// src 為 nil 時使用 NewRand(nil)。與 RdmFlt64With 使用不同的 src，兩者的亂數就互不相關，相同狀態的 src 會得到相同的結果。
+func MyRdmFlt64With(src rand_fromgo.Source, min, max float64, num uint8) (mean, sigma float64) {
+	return RdmFlt64With(NewRand(src), min, max, num)
+}
+
 // endregion function
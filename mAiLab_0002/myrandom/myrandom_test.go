@@ -18,6 +18,8 @@ package myrandom
 
 import (
 	"testing"
+
+	"github.com/oneleo/LeNetPractice/mAiLab_0002/rand_fromgo"
 )
 
 // Test_MultiRdmFlt64 是測試 MultiRdmFlt64 函數所產生出來的亂數平均值及標準差要在合理範圍內。
@@ -102,6 +104,80 @@ func Test_MyRdmFlt64(t *testing.T) {
 	}
 }
 
+// Test_RdmFlt64With 是測試指定 Source 或使用預設種子時結果可以重現，且不會重設全域的亂數種子。
+func Test_RdmFlt64With(t *testing.T) {
+	// 定義測試集 Struct，每一組的 rnd 每次呼叫都會建立狀態相同的 *rand_fromgo.Rand，應得到相同的結果。
+	var tests = []struct {
+		name string
+		rnd  func() *rand_fromgo.Rand
+	}{
+		// 測試 1：nil 使用 DefaultSeed。
+		{"nil", func() *rand_fromgo.Rand { return nil }},
+		// 測試 2：指定種子。
+		{"seed 42", func() *rand_fromgo.Rand { return NewRand(rand_fromgo.NewSource(42)) }},
+		// 測試 3：其他 Source。
+		{"PCG", func() *rand_fromgo.Rand { return NewRand(rand_fromgo.NewPCG(1, 2)) }},
+	}
+	for _, test := range tests {
+		ra, rb := MultiRdmFlt64With(test.rnd(), -1, 1, 50), MultiRdmFlt64With(test.rnd(), -1, 1, 50)
+		for i := range ra {
+			// 如果兩次產生的亂數不同或不在 [min, max) 內，測試失敗。
+			if ra[i] != rb[i] {
+				t.Fatalf("Error %s: value %d = %g and %g, should be equal.", test.name, i, ra[i], rb[i])
+			}
+			if ra[i] < -1 || ra[i] >= 1 {
+				t.Errorf("Error range: %g, should in [-1, 1).", ra[i])
+			}
+		}
+		m1, s1 := RdmFlt64With(test.rnd(), 100, 1000, 100)
+		m2, s2 := RdmFlt64With(test.rnd(), 100, 1000, 100)
+		if m1 != m2 || s1 != s2 || m1 < 100 || m1 >= 1000 || s1 <= 0 {
+			t.Errorf("Error %s: mean, sigma = (%g, %g) and (%g, %g).", test.name, m1, s1, m2, s2)
+		}
+	}
+	// nil 與以 DefaultSeed 建立的 rand_fromgo.NewSource 相同。
+	a := MultiRdmFlt64With(nil, 0, 1, 1)
+	b := MultiRdmFlt64With(rand_fromgo.New(rand_fromgo.NewSource(DefaultSeed)), 0, 1, 1)
+	if a[0] != b[0] {
+		t.Errorf("Error nil uses %g, should be %g.", a[0], b[0])
+	}
+
+	// 呼叫 RdmFlt64With 不會影響全域亂數產生器的數列。
+	rand_fromgo.Seed(7)
+	want := rand_fromgo.Float64()
+	rand_fromgo.Seed(7)
+	RdmFlt64With(nil, -1, 1, 100)
+	if got := rand_fromgo.Float64(); got != want {
+		t.Errorf("Error global generator changed: %g, should be %g.", got, want)
+	}
+}
+
+// Test_MyRdmFlt64With 是測試 MyRdmFlt64With 以指定的 Source 產生可重現的結果，且不會重設 rand_fromgo 的全域種子。
+func Test_MyRdmFlt64With(t *testing.T) {
+	m1, s1 := MyRdmFlt64With(rand_fromgo.NewSource(42), -1, 1, 100)
+	m2, s2 := MyRdmFlt64With(rand_fromgo.NewSource(42), -1, 1, 100)
+	if m1 != m2 || s1 != s2 || m1 < -1 || m1 >= 1 || s1 <= 0 {
+		t.Errorf("Error seed 42: mean, sigma = (%g, %g) and (%g, %g).", m1, s1, m2, s2)
+	}
+	// 不同的 Source 會得到不同的結果。
+	if m3, _ := MyRdmFlt64With(rand_fromgo.NewSource(43), -1, 1, 100); m3 == m1 {
+		t.Errorf("Error different sources give the same mean %g.", m3)
+	}
+	// nil 與 RdmFlt64With(nil) 相同。
+	m4, _ := MyRdmFlt64With(nil, -1, 1, 100)
+	if m5, _ := RdmFlt64With(nil, -1, 1, 100); m4 != m5 {
+		t.Errorf("Error nil source mean %g, should be %g.", m4, m5)
+	}
+
+	rand_fromgo.Seed(7)
+	want := rand_fromgo.Float64()
+	rand_fromgo.Seed(7)
+	MyRdmFlt64With(nil, -1, 1, 100)
+	if got := rand_fromgo.Float64(); got != want {
+		t.Errorf("Error global generator changed: %g, should be %g.", got, want)
+	}
+}
+
 // Benchmark_MultiRdmFlt64 為計算 MultiRdmFlt64 函數的效能評估，計算執行 b.N 次所花費的時間。
 func Benchmark_MultiRdmFlt64(b *testing.B) {
 	// b.N 執行次數會依系統進行自動調整。
@@ -123,3 +199,11 @@ func Benchmark_MyRdmFlt64(b *testing.B) {
 		MyRdmFlt64(-1, 1, 100)
 	}
 }
+
+// Benchmark_RdmFlt64With 為計算 RdmFlt64With 函數的效能評估，計算執行 b.N 次所花費的時間。
+func Benchmark_RdmFlt64With(b *testing.B) {
+	rnd := NewRand(nil)
+	for i := 0; i < b.N; i++ {
+		RdmFlt64With(rnd, -1, 1, 100)
+	}
+}
@@ -0,0 +1,138 @@
+package myrandom
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/oneleo/LeNetPractice/mAiLab_0002/rand_fromgo"
+)
+
+// region struct
+
+// Stats 以 Welford 的線上演算法累計樣本的統計量，每加入一個樣本只需 O(1) 的時間與記憶體，
+// 不需要保存所有樣本。零值即為沒有任何樣本的 Stats，可以直接使用。
+type Stats struct {
+	n    int64
+	mean float64
+	min  float64
+	max  float64
+	// m2、m3、m4 為與平均值之差的 2、3、4 次方和。
+	m2 float64
+	m3 float64
+	m4 float64
+}
+
+// endregion struct
+
+// region function
+
+// StatsFlt64 函數會以 rnd 逐一產生 num 個 [min, max) 範圍內的亂數並累計其統計量，num 沒有上限；
+// rnd 為 nil 時使用 NewRand(nil)。num 小於等於 0 時回傳沒有樣本的 Stats。
+func StatsFlt64(rnd *rand_fromgo.Rand, min, max float64, num int) (s Stats) {
+	if rnd == nil {
+		rnd = NewRand(nil)
+	}
+	for n := 0; n < num; n++ {
+		// 產生一個 [min, max) 範圍內的亂數，不保存，直接累計。
+		s.Add((max-min)*rnd.Float64() + min)
+	}
+	return s
+}
+
+// sampleStd 函數會以差平方和 sumSq 與樣本數 n 計算樣本標準差，n 小於 2 時回傳 NaN。
+func sampleStd(sumSq float64, n int64) float64 {
+	if n < 2 {
+		return math.NaN()
+	}
+	return math.Sqrt(sumSq / float64(n-1))
+}
+
+// endregion function
+
+// region method
+
+// Add 會加入一個樣本（Terriberry 對 Welford 演算法的高階動差延伸）。
+func (s *Stats) Add(x float64) {
+	if s.n == 0 || x < s.min {
+		s.min = x
+	}
+	if s.n == 0 || x > s.max {
+		s.max = x
+	}
+	n1 := float64(s.n)
+	s.n++
+	n := float64(s.n)
+	delta := x - s.mean
+	deltaN := delta / n
+	deltaN2 := deltaN * deltaN
+	term1 := delta * deltaN * n1
+	s.mean += deltaN
+	// 必須先更新 m4、m3 再更新 m2，因為它們使用的是加入此樣本前的 m2、m3。
+	s.m4 += term1*deltaN2*(n*n-3*n+3) + 6*deltaN2*s.m2 - 4*deltaN*s.m3
+	s.m3 += term1*deltaN*(n-2) - 3*deltaN*s.m2
+	s.m2 += term1
+}
+
+// N 會回傳樣本數。
+func (s Stats) N() int64 { return s.n }
+
+// Mean 會回傳平均值，沒有樣本時為 NaN。
+func (s Stats) Mean() float64 {
+	if s.n == 0 {
+		return math.NaN()
+	}
+	return s.mean
+}
+
+// Min 會回傳最小值，沒有樣本時為 NaN。
+func (s Stats) Min() float64 {
+	if s.n == 0 {
+		return math.NaN()
+	}
+	return s.min
+}
+
+// Max 會回傳最大值，沒有樣本時為 NaN。
+func (s Stats) Max() float64 {
+	if s.n == 0 {
+		return math.NaN()
+	}
+	return s.max
+}
+
+// Variance 會回傳樣本變異數（除以 n-1，與 RdmFlt64 的 sigma 相同），樣本數小於 2 時為 NaN。
+func (s Stats) Variance() float64 {
+	if s.n < 2 {
+		return math.NaN()
+	}
+	return s.m2 / float64(s.n-1)
+}
+
+// Std 會回傳樣本標準差，樣本數小於 2 時為 NaN。
+func (s Stats) Std() float64 {
+	return sampleStd(s.m2, s.n)
+}
+
+// Skewness 會回傳偏度 g1 = √n·m3 / m2^1.5，對稱分佈為 0；樣本數小於 2 或所有樣本相同時為 NaN。
+func (s Stats) Skewness() float64 {
+	if s.n < 2 || s.m2 == 0 {
+		return math.NaN()
+	}
+	return math.Sqrt(float64(s.n)) * s.m3 / math.Pow(s.m2, 1.5)
+}
+
+// Kurtosis 會回傳超額峰度 g2 = n·m4 / m2² - 3，常態分佈為 0、均勻分佈為 -1.2；樣本數小於 2 或所有樣本相同時為 NaN。
+func (s Stats) Kurtosis() float64 {
+	if s.n < 2 || s.m2 == 0 {
+		return math.NaN()
+	}
+	return float64(s.n)*s.m4/(s.m2*s.m2) - 3
+}
+
+// String 會回傳所有統計量。
+func (s Stats) String() string {
+	return fmt.Sprintf("n %d, mean %g, std %g, min %g, max %g, skewness %g, kurtosis %g",
+		s.n, s.Mean(), s.Std(), s.Min(), s.Max(), s.Skewness(), s.Kurtosis())
+}
+
+// endregion method
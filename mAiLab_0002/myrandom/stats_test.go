@@ -0,0 +1,123 @@
+package myrandom
+
+import (
+	"math"
+	"testing"
+
+	"github.com/oneleo/LeNetPractice/mAiLab_0002/rand_fromgo"
+)
+
+// twoPass 函數以保存所有樣本的兩階段算法計算平均值、樣本變異數、偏度與超額峰度，作為 Stats 的對照。
+func twoPass(x []float64) (mean, variance, skew, kurt float64) {
+	n := float64(len(x))
+	for _, v := range x {
+		mean += v
+	}
+	mean /= n
+	var m2, m3, m4 float64
+	for _, v := range x {
+		d := v - mean
+		m2 += d * d
+		m3 += d * d * d
+		m4 += d * d * d * d
+	}
+	return mean, m2 / (n - 1), math.Sqrt(n) * m3 / math.Pow(m2, 1.5), n*m4/(m2*m2) - 3
+}
+
+// near 函數會回傳 a 與 b 的相對誤差是否小於 tol。
+func near(a, b, tol float64) bool {
+	return math.Abs(a-b) <= tol*math.Max(1, math.Abs(b))
+}
+
+// Test_Stats 是測試 Stats 的統計量與兩階段算法的結果相同，包含平均值很大時的數值穩定性。
+func Test_Stats(t *testing.T) {
+	rnd := rand_fromgo.New(rand_fromgo.NewSource(1))
+	skewed := make([]float64, 1000)
+	for i := range skewed {
+		skewed[i] = rnd.ExpFloat64()
+	}
+	shifted := make([]float64, 1000)
+	for i := range shifted {
+		shifted[i] = 1e9 + rnd.Float64()
+	}
+	// 定義測試集 Struct。
+	var tests = []struct {
+		name string
+		data []float64
+		tol  float64
+	}{
+		// 測試 1：教科書範例，平均值 5、樣本變異數 32/7。
+		{"textbook", []float64{2, 4, 4, 4, 5, 5, 7, 9}, 1e-9},
+		// 測試 2：右偏的指數分佈。
+		{"exponential", skewed, 1e-9},
+		// 測試 3：平均值遠大於標準差，直接以 Σx² - n·mean² 計算會完全失去精確度，Welford 仍接近兩階段算法。
+		{"shifted", shifted, 1e-5},
+	}
+	for _, test := range tests {
+		var s Stats
+		lo, hi := math.Inf(1), math.Inf(-1)
+		for _, v := range test.data {
+			s.Add(v)
+			lo, hi = math.Min(lo, v), math.Max(hi, v)
+		}
+		mean, variance, skew, kurt := twoPass(test.data)
+		if s.N() != int64(len(test.data)) || s.Min() != lo || s.Max() != hi {
+			t.Errorf("Error %s: n %d, min %g, max %g, should be %d, %g, %g.", test.name, s.N(), s.Min(), s.Max(), len(test.data), lo, hi)
+		}
+		if !near(s.Mean(), mean, 1e-12) || !near(s.Variance(), variance, test.tol) || !near(s.Std(), math.Sqrt(variance), test.tol) {
+			t.Errorf("Error %s: mean %g, variance %g, should be %g, %g.", test.name, s.Mean(), s.Variance(), mean, variance)
+		}
+		if !near(s.Skewness(), skew, test.tol) || !near(s.Kurtosis(), kurt, test.tol) {
+			t.Errorf("Error %s: skewness %g, kurtosis %g, should be %g, %g.", test.name, s.Skewness(), s.Kurtosis(), skew, kurt)
+		}
+	}
+}
+
+// Test_StatsFlt64 是測試樣本數可以超過 255，且均勻分佈的統計量接近理論值。
+func Test_StatsFlt64(t *testing.T) {
+	s := StatsFlt64(NewRand(nil), -1, 1, 1000000)
+	if s.N() != 1000000 || s.Min() < -1 || s.Max() >= 1 {
+		t.Errorf("Error n %d, min %g, max %g.", s.N(), s.Min(), s.Max())
+	}
+	// [-1, 1) 均勻分佈：平均值 0、變異數 1/3、偏度 0、超額峰度 -1.2。
+	if math.Abs(s.Mean()) > 0.005 || math.Abs(s.Variance()-1.0/3) > 0.005 ||
+		math.Abs(s.Skewness()) > 0.01 || math.Abs(s.Kurtosis()+1.2) > 0.01 {
+		t.Errorf("Error uniform statistics: %v.", s)
+	}
+	// 相同種子得到相同的結果，nil 使用 DefaultSeed。
+	if a, b := StatsFlt64(nil, 0, 1, 1000), StatsFlt64(NewRand(rand_fromgo.NewSource(DefaultSeed)), 0, 1, 1000); a != b {
+		t.Errorf("Error %v and %v should be equal.", a, b)
+	}
+}
+
+// Test_StatsEmpty 是測試樣本數不足時回傳 NaN，而不是因為 num-1 溢位得到錯誤的值。
+func Test_StatsEmpty(t *testing.T) {
+	for _, num := range []int{-1, 0, 1} {
+		s := StatsFlt64(nil, 0, 1, num)
+		if !math.IsNaN(s.Variance()) || !math.IsNaN(s.Std()) || !math.IsNaN(s.Skewness()) || !math.IsNaN(s.Kurtosis()) {
+			t.Errorf("Error num %d: %v, variance and higher moments should be NaN.", num, s)
+		}
+		if num <= 0 && (!math.IsNaN(s.Mean()) || !math.IsNaN(s.Min()) || !math.IsNaN(s.Max())) {
+			t.Errorf("Error num %d: %v, mean, min and max should be NaN.", num, s)
+		}
+	}
+	for _, num := range []uint8{0, 1} {
+		if _, sigma := RdmFlt64(0, 1, num); !math.IsNaN(sigma) {
+			t.Errorf("Error RdmFlt64 num %d: sigma = %g, should be NaN.", num, sigma)
+		}
+		if _, sigma := MyRdmFlt64(0, 1, num); !math.IsNaN(sigma) {
+			t.Errorf("Error MyRdmFlt64 num %d: sigma = %g, should be NaN.", num, sigma)
+		}
+		if _, sigma := RdmFlt64With(nil, 0, 1, num); !math.IsNaN(sigma) {
+			t.Errorf("Error RdmFlt64With num %d: sigma = %g, should be NaN.", num, sigma)
+		}
+	}
+}
+
+// Benchmark_StatsFlt64 為計算 StatsFlt64 函數累計 1000 個亂數的效能評估。
+func Benchmark_StatsFlt64(b *testing.B) {
+	rnd := NewRand(nil)
+	for i := 0; i < b.N; i++ {
+		StatsFlt64(rnd, -1, 1, 1000)
+	}
+}
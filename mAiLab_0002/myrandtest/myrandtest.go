@@ -0,0 +1,378 @@
+package myrandtest
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"text/tabwriter"
+
+	"github.com/oneleo/LeNetPractice/mAiLab_0002/rand_fromgo"
+)
+
+// 每一個檢定都從 rand_fromgo.Source 取值並回傳檢定統計量與 p 值：p 值為「亂數產生器完全隨機」的假設下，
+// 得到與此次結果一樣極端或更極端的機率，p 值非常小代表產生器的輸出有可被偵測的規律。
+// 一個好的產生器在重複執行時，p 值應大致均勻分佈在 (0, 1) 之間，因此偶爾出現小的 p 值是正常的。
+// 參數超出範圍時檢定不會取用任何亂數，並回傳錯誤。
+
+// region struct
+
+// Result 是一個檢定的結果。
+type Result struct {
+	// Name 為檢定名稱。
+	Name string
+	// Statistic 為檢定統計量（卡方值、KS 的 D 值、標準常態 z 值或重複次數）。
+	Statistic float64
+	// PValue 為 p 值。
+	PValue float64
+}
+
+// endregion struct
+
+// region function
+
+// ChiSquare 函數會將 n 個 [0, 1) 的亂數分到 bins 個等寬的區間，以卡方適合度檢定其是否均勻分佈。
+// n 必須至少為 1，bins 必須至少為 2。
+func ChiSquare(src rand_fromgo.Source, n, bins int) (r Result, err error) {
+	if n < 1 || bins < 2 {
+		return r, fmt.Errorf("Error: chi-square needs n >= 1 and bins >= 2, got n = %d and bins = %d", n, bins)
+	}
+	rnd := rand_fromgo.New(src)
+	counts := make([]int, bins)
+	for i := 0; i < n; i++ {
+		counts[int(rnd.Float64()*float64(bins))]++
+	}
+	expected := make([]float64, bins)
+	for i := range expected {
+		expected[i] = float64(n) / float64(bins)
+	}
+	chi2 := chiSquareStat(counts, expected)
+	return Result{Name: "chi-square", Statistic: chi2, PValue: ChiSquareSF(chi2, float64(bins-1))}, nil
+}
+
+// KolmogorovSmirnov 函數會以 n 個 [0, 1) 的亂數的經驗分佈與均勻分佈的最大差距 D 做 Kolmogorov-Smirnov 檢定，
+// n 必須至少為 2。
+func KolmogorovSmirnov(src rand_fromgo.Source, n int) (r Result, err error) {
+	if n < 2 {
+		return r, fmt.Errorf("Error: kolmogorov-smirnov needs n >= 2, got %d", n)
+	}
+	rnd := rand_fromgo.New(src)
+	x := make([]float64, n)
+	for i := range x {
+		x[i] = rnd.Float64()
+	}
+	sort.Float64s(x)
+	d := 0.0
+	for i, v := range x {
+		d = math.Max(d, math.Max(float64(i+1)/float64(n)-v, v-float64(i)/float64(n)))
+	}
+	return Result{Name: "kolmogorov-smirnov", Statistic: d, PValue: KolmogorovSF(d, n)}, nil
+}
+
+// Runs 函數會計算 n 個亂數中連續遞增與連續遞減的段數（runs up and down），
+// 隨機數列的段數平均值為 (2n-1)/3、變異數為 (16n-29)/90，以常態近似做雙尾檢定。
+// n 小於 2 時變異數不是正數，因此 n 必須至少為 2。
+func Runs(src rand_fromgo.Source, n int) (r Result, err error) {
+	if n < 2 {
+		return r, fmt.Errorf("Error: runs needs n >= 2, got %d", n)
+	}
+	rnd := rand_fromgo.New(src)
+	runs, prev, up := 0, rnd.Float64(), false
+	for i := 1; i < n; i++ {
+		v := rnd.Float64()
+		if dir := v > prev; i == 1 || dir != up {
+			runs++
+			up = dir
+		}
+		prev = v
+	}
+	mean := (2*float64(n) - 1) / 3
+	sd := math.Sqrt((16*float64(n) - 29) / 90)
+	z := (float64(runs) - mean) / sd
+	return Result{Name: "runs", Statistic: z, PValue: NormalTwoSided(z)}, nil
+}
+
+// SerialCorrelation 函數會計算 n 個亂數與其相隔 lag 個位置的值之間的相關係數 ρ，
+// 隨機數列的 ρ√n 近似標準常態分佈，以此做雙尾檢定；Statistic 為 z = ρ√n。
+// n 必須至少為 2，lag 必須至少為 1。
+func SerialCorrelation(src rand_fromgo.Source, n, lag int) (r Result, err error) {
+	if n < 2 || lag < 1 {
+		return r, fmt.Errorf("Error: serial correlation needs n >= 2 and lag >= 1, got n = %d and lag = %d", n, lag)
+	}
+	rnd := rand_fromgo.New(src)
+	x := make([]float64, n+lag)
+	for i := range x {
+		x[i] = rnd.Float64()
+	}
+	var sa, sb, saa, sbb, sab float64
+	for i := 0; i < n; i++ {
+		a, b := x[i], x[i+lag]
+		sa += a
+		sb += b
+		saa += a * a
+		sbb += b * b
+		sab += a * b
+	}
+	fn := float64(n)
+	rho := (fn*sab - sa*sb) / math.Sqrt((fn*saa-sa*sa)*(fn*sbb-sb*sb))
+	z := rho * math.Sqrt(fn)
+	return Result{Name: fmt.Sprintf("serial correlation (lag %d)", lag), Statistic: z, PValue: NormalTwoSided(z)}, nil
+}
+
+// BirthdaySpacings 函數是 Marsaglia 的生日間距檢定：在 2^bits 天中隨機選 m 個生日，排序後計算相鄰生日的間距，
+// 一年視為環狀，最後一個生日到下一年第一個生日的間距為 days[0] + 2^bits - days[m-1]。
+// 間距中重複出現的個數近似平均值 λ = m³/(4·2^bits) 的 Poisson 分佈。重複 trials 次後加總，以 Poisson 分佈做雙尾檢定。
+// 生日取自 Uint64 的最高 bits 個位元，bits 必須介於 1 與 64 之間，m 必須至少為 2，trials 必須至少為 1。
+func BirthdaySpacings(src rand_fromgo.Source, m, bits, trials int) (r Result, err error) {
+	if m < 2 || bits < 1 || bits > 64 || trials < 1 {
+		return r, fmt.Errorf("Error: birthday spacings needs m >= 2, 1 <= bits <= 64 and trials >= 1, got m = %d, bits = %d and trials = %d", m, bits, trials)
+	}
+	rnd := rand_fromgo.New(src)
+	days := make([]uint64, m)
+	spacings := make([]uint64, m)
+	total := 0
+	for t := 0; t < trials; t++ {
+		for i := range days {
+			days[i] = rnd.Uint64() >> uint(64-bits)
+		}
+		sort.Slice(days, func(i, j int) bool { return days[i] < days[j] })
+		// bits 為 64 時 1<<64 為 0，uint64 的溢位正好是對 2^64 取餘數，結果仍然正確。
+		spacings[0] = days[0] + 1<<uint(bits) - days[m-1]
+		for i := 1; i < m; i++ {
+			spacings[i] = days[i] - days[i-1]
+		}
+		sort.Slice(spacings, func(i, j int) bool { return spacings[i] < spacings[j] })
+		for i := 1; i < m; i++ {
+			if spacings[i] == spacings[i-1] {
+				total++
+			}
+		}
+	}
+	lambda := float64(trials) * math.Pow(float64(m), 3) / (4 * math.Exp2(float64(bits)))
+	return Result{Name: "birthday spacings", Statistic: float64(total), PValue: PoissonTwoSided(total, lambda)}, nil
+}
+
+// Gap 函數是 Knuth 的間隔檢定：落在 [alpha, beta) 內的亂數視為命中，計算相鄰兩次命中之間未命中的個數（間隔），
+// 收集 n 個間隔後，將長度 0～t-1 與 t 以上的間隔次數以卡方適合度檢定其是否符合幾何分佈。
+// 必須 0 <= alpha < beta <= 1，否則可能永遠不會命中；n 與 t 必須至少為 1。
+func Gap(src rand_fromgo.Source, n int, alpha, beta float64, t int) (r Result, err error) {
+	if !(0 <= alpha && alpha < beta && beta <= 1) || n < 1 || t < 1 {
+		return r, fmt.Errorf("Error: gap needs 0 <= alpha < beta <= 1, n >= 1 and t >= 1, got alpha = %g, beta = %g, n = %d and t = %d", alpha, beta, n, t)
+	}
+	rnd := rand_fromgo.New(src)
+	counts := make([]int, t+1)
+	for g := 0; g < n; g++ {
+		length := 0
+		for v := rnd.Float64(); v < alpha || v >= beta; v = rnd.Float64() {
+			length++
+		}
+		if length > t {
+			length = t
+		}
+		counts[length]++
+	}
+	p := beta - alpha
+	expected := make([]float64, t+1)
+	for k := 0; k < t; k++ {
+		expected[k] = float64(n) * p * math.Pow(1-p, float64(k))
+	}
+	expected[t] = float64(n) * math.Pow(1-p, float64(t))
+	chi2 := chiSquareStat(counts, expected)
+	return Result{Name: "gap", Statistic: chi2, PValue: ChiSquareSF(chi2, float64(t))}, nil
+}
+
+// Suite 函數會以約 n 個亂數為規模，依序對 src 執行所有檢定，n 建議至少 10000。
+// 間隔檢定收集 n/5 個間隔，因此 n 必須至少為 5。
+func Suite(src rand_fromgo.Source, n int) (results []Result, err error) {
+	if n < 5 {
+		return nil, fmt.Errorf("Error: suite needs n >= 5, got %d", n)
+	}
+	trials := n / 1000
+	if trials < 1 {
+		trials = 1
+	}
+	var tests = []func() (Result, error){
+		func() (Result, error) { return ChiSquare(src, n, 100) },
+		func() (Result, error) { return KolmogorovSmirnov(src, n) },
+		func() (Result, error) { return Runs(src, n) },
+		func() (Result, error) { return SerialCorrelation(src, n, 1) },
+		// 512 個生日、2^24 天時 λ = 2，與 Marsaglia 的 Diehard 設定相同。
+		func() (Result, error) { return BirthdaySpacings(src, 512, 24, trials) },
+		func() (Result, error) { return Gap(src, n/5, 0, 0.5, 10) },
+	}
+	results = make([]Result, len(tests))
+	for i, test := range tests {
+		if results[i], err = test(); err != nil {
+			return nil, err
+		}
+	}
+	return results, nil
+}
+
+// WriteTable 函數會將多個產生器的 Suite 結果以表格寫入 w，每一列為一個檢定，每一欄為一個產生器的 p 值。
+// p 值小於 alpha 的欄位會加上 "*" 標記。results 不可為空，names 與 results 的長度必須相同，
+// 且每一個產生器的檢定個數必須相同，否則回傳錯誤。
+func WriteTable(w io.Writer, names []string, results [][]Result, alpha float64) (err error) {
+	if len(results) == 0 {
+		return errors.New("Error: no results to write")
+	}
+	if len(names) != len(results) {
+		return fmt.Errorf("Error: %d names for %d results", len(names), len(results))
+	}
+	for i, rs := range results {
+		if len(rs) != len(results[0]) {
+			return fmt.Errorf("Error: %s has %d results, should be %d", names[i], len(rs), len(results[0]))
+		}
+	}
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprint(tw, "test")
+	for _, name := range names {
+		fmt.Fprintf(tw, "\t%s", name)
+	}
+	fmt.Fprintln(tw)
+	for i := range results[0] {
+		fmt.Fprint(tw, results[0][i].Name)
+		for _, rs := range results {
+			mark := ""
+			if !rs[i].Pass(alpha) {
+				mark = " *"
+			}
+			fmt.Fprintf(tw, "\t%.4f%s", rs[i].PValue, mark)
+		}
+		fmt.Fprintln(tw)
+	}
+	return tw.Flush()
+}
+
+// chiSquareStat 函數會回傳觀察次數 counts 與期望次數 expected 的卡方統計量。
+func chiSquareStat(counts []int, expected []float64) (chi2 float64) {
+	for i, c := range counts {
+		d := float64(c) - expected[i]
+		chi2 += d * d / expected[i]
+	}
+	return chi2
+}
+
+// ChiSquareSF 函數會回傳自由度 df 的卡方分佈大於 x 的機率（右尾機率）。
+func ChiSquareSF(x, df float64) float64 {
+	if x <= 0 {
+		return 1
+	}
+	return GammaQ(df/2, x/2)
+}
+
+// KolmogorovSF 函數會回傳樣本數 n 時 Kolmogorov-Smirnov 統計量大於 d 的近似機率（Stephens 的修正）。
+func KolmogorovSF(d float64, n int) float64 {
+	sn := math.Sqrt(float64(n))
+	lambda := (sn + 0.12 + 0.11/sn) * d
+	if lambda < 0.2 {
+		return 1
+	}
+	sum, sign := 0.0, 1.0
+	for j := 1; j <= 100; j++ {
+		term := sign * math.Exp(-2*float64(j*j)*lambda*lambda)
+		sum += term
+		if math.Abs(term) < 1e-12*math.Abs(sum) {
+			break
+		}
+		sign = -sign
+	}
+	return math.Max(0, math.Min(1, 2*sum))
+}
+
+// NormalTwoSided 函數會回傳標準常態分佈的絕對值大於 |z| 的機率。
+func NormalTwoSided(z float64) float64 {
+	return math.Erfc(math.Abs(z) / math.Sqrt2)
+}
+
+// PoissonTwoSided 函數會回傳平均值 lambda 的 Poisson 分佈中，得到與 k 一樣極端或更極端的雙尾機率。
+func PoissonTwoSided(k int, lambda float64) float64 {
+	// P(X <= k) = Q(k+1, λ)，P(X >= k) = P(k, λ)。
+	lower := GammaQ(float64(k)+1, lambda)
+	upper := 1.0
+	if k > 0 {
+		upper = GammaP(float64(k), lambda)
+	}
+	return math.Min(1, 2*math.Min(lower, upper))
+}
+
+// GammaP 函數會回傳正規化的下不完全 Gamma 函數 P(a, x)。
+func GammaP(a, x float64) float64 {
+	if x <= 0 {
+		return 0
+	}
+	if x < a+1 {
+		return gammaSeries(a, x)
+	}
+	return 1 - gammaFraction(a, x)
+}
+
+// GammaQ 函數會回傳正規化的上不完全 Gamma 函數 Q(a, x) = 1 - P(a, x)。
+func GammaQ(a, x float64) float64 {
+	if x <= 0 {
+		return 1
+	}
+	if x < a+1 {
+		return 1 - gammaSeries(a, x)
+	}
+	return gammaFraction(a, x)
+}
+
+// gammaSeries 函數以級數計算 P(a, x)，適用於 x < a+1。
+func gammaSeries(a, x float64) float64 {
+	lg, _ := math.Lgamma(a)
+	sum, term := 1/a, 1/a
+	for n := 1; n < 10000; n++ {
+		term *= x / (a + float64(n))
+		sum += term
+		if math.Abs(term) < math.Abs(sum)*1e-15 {
+			break
+		}
+	}
+	return sum * math.Exp(-x+a*math.Log(x)-lg)
+}
+
+// gammaFraction 函數以連分數（修正的 Lentz 法）計算 Q(a, x)，適用於 x >= a+1。
+func gammaFraction(a, x float64) float64 {
+	const tiny = 1e-300
+	lg, _ := math.Lgamma(a)
+	b := x + 1 - a
+	c, d := 1/tiny, 1/b
+	h := d
+	for i := 1; i < 10000; i++ {
+		an := -float64(i) * (float64(i) - a)
+		b += 2
+		d = an*d + b
+		if math.Abs(d) < tiny {
+			d = tiny
+		}
+		c = b + an/c
+		if math.Abs(c) < tiny {
+			c = tiny
+		}
+		d = 1 / d
+		delta := d * c
+		h *= delta
+		if math.Abs(delta-1) < 1e-15 {
+			break
+		}
+	}
+	return math.Exp(-x+a*math.Log(x)-lg) * h
+}
+
+// endregion function
+
+// region method
+
+// Pass 會回傳 p 值是否不小於顯著水準 alpha（例如 0.01），即此檢定沒有偵測到規律。
+func (r Result) Pass(alpha float64) bool {
+	return r.PValue >= alpha
+}
+
+// String 會回傳檢定名稱、統計量與 p 值。
+func (r Result) String() string {
+	return fmt.Sprintf("%s: statistic %.4g, p-value %.4f", r.Name, r.Statistic, r.PValue)
+}
+
+// endregion method
@@ -0,0 +1,281 @@
+// How to use:
+//
+// 1. Testing
+// (1) > cd "%GOPATH%\src\github.com\oneleo\LeNetPractice\mAiLab_0002\myrandtest"
+// or (1) $ cd "$GOPATH/src/github.com/oneleo/LeNetPractice/mAiLab_0002/myrandtest"
+// (2) $> go test -v
+//
+// 2. Benchmark:
+// (1) > cd "%GOPATH%\src\github.com\oneleo\LeNetPractice\mAiLab_0002\myrandtest"
+// or (1) $ cd "$GOPATH/src/github.com/oneleo/LeNetPractice/mAiLab_0002/myrandtest"
+// (2) $> go test -bench={Mathod Name} -v
+// such as: $> go test -bench=Benchmark_Suite -v
+//
+// 3. Test all:
+// $> go test -bench=. -v
+
+package myrandtest
+
+import (
+	"bytes"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/oneleo/LeNetPractice/mAiLab_0002/rand_fromgo"
+)
+
+// minSource 每次回傳兩個亂數中較小者，分佈偏向 0。
+type minSource struct{ rand_fromgo.Source }
+
+func (s minSource) Int63() int64 {
+	a, b := s.Source.Int63(), s.Source.Int63()
+	if a < b {
+		return a
+	}
+	return b
+}
+
+// stickySource 每個亂數都會重複回傳兩次，相鄰的值高度相關。
+type stickySource struct {
+	rand_fromgo.Source
+	last int64
+	n    int
+}
+
+func (s *stickySource) Int63() int64 {
+	if s.n%2 == 0 {
+		s.last = s.Source.Int63()
+	}
+	s.n++
+	return s.last
+}
+
+// coarseSource 只保留最高 16 個位元，可能的值只有 2^16 種。
+type coarseSource struct{ rand_fromgo.Source }
+
+func (s coarseSource) Int63() int64 { return s.Source.Int63() &^ (1<<47 - 1) }
+
+// Test_Distributions 是測試 p 值計算所用的分佈函數與已知的值相符。
+func Test_Distributions(t *testing.T) {
+	var tests = []struct {
+		name string
+		got  float64
+		want float64
+	}{
+		// 自由度 2 的卡方分佈右尾機率為 e^(-x/2)。
+		{"ChiSquareSF(3, 2)", ChiSquareSF(3, 2), math.Exp(-1.5)},
+		// 卡方分佈表：自由度 10 的 0.05 臨界值為 18.307，自由度 99 的 0.01 臨界值為 134.642。
+		{"ChiSquareSF(18.307, 10)", ChiSquareSF(18.307, 10), 0.05},
+		{"ChiSquareSF(134.642, 99)", ChiSquareSF(134.642, 99), 0.01},
+		// 自由度 1 的卡方分佈即標準常態的平方。
+		{"ChiSquareSF(1.5, 1)", ChiSquareSF(1.5, 1), NormalTwoSided(math.Sqrt(1.5))},
+		{"NormalTwoSided(1.959964)", NormalTwoSided(1.959964), 0.05},
+		// Kolmogorov 分佈：λ = 1.3581 時右尾機率為 0.05。
+		{"KolmogorovSF", KolmogorovSF(1.3581/(math.Sqrt(1e6)+0.12+0.11/1e3), 1e6), 0.05},
+		// Poisson(2)：P(X <= 0) = e^-2，雙尾取兩倍。
+		{"PoissonTwoSided(0, 2)", PoissonTwoSided(0, 2), 2 * math.Exp(-2)},
+		{"PoissonTwoSided(2, 2)", PoissonTwoSided(2, 2), 1},
+		{"GammaP(1, 2)", GammaP(1, 2), 1 - math.Exp(-2)},
+		{"GammaQ(5, 20)", GammaQ(5, 20), math.Exp(-20) * (1 + 20 + 200 + 8000.0/6 + 160000.0/24)},
+	}
+	for _, test := range tests {
+		if math.Abs(test.got-test.want) > 1e-4*math.Max(1, test.want) && math.Abs(test.got-test.want) > 1e-5 {
+			t.Errorf("Error %s = %g, should be %g.", test.name, test.got, test.want)
+		}
+	}
+}
+
+// Test_GoodSources 是測試所有 rand_fromgo 的產生器都能通過每一個檢定。
+func Test_GoodSources(t *testing.T) {
+	var tests = []struct {
+		name string
+		src  rand_fromgo.Source
+	}{
+		{"rngSource", rand_fromgo.NewSource(1)},
+		{"SplitMix64", rand_fromgo.NewSplitMix64(1)},
+		{"Xoshiro256", rand_fromgo.NewXoshiro256(1)},
+		{"PCG", rand_fromgo.NewPCG(1, 2)},
+		{"ChaCha8", rand_fromgo.NewChaCha8([32]byte{1})},
+	}
+	for _, test := range tests {
+		results, err := Suite(test.src, 100000)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, r := range results {
+			// 種子固定，結果是確定的；以很小的顯著水準避免更換種子時偶然失敗。
+			if !r.Pass(1e-4) {
+				t.Errorf("Error %s fails %v.", test.name, r)
+			}
+		}
+	}
+}
+
+// Test_BadSources 是測試每一種有缺陷的產生器都會被對應的檢定偵測出來。
+func Test_BadSources(t *testing.T) {
+	var tests = []struct {
+		name string
+		run  func(src rand_fromgo.Source) (Result, error)
+		src  rand_fromgo.Source
+	}{
+		{"chi-square on min", func(s rand_fromgo.Source) (Result, error) { return ChiSquare(s, 10000, 100) }, minSource{rand_fromgo.NewSource(2)}},
+		{"KS on min", func(s rand_fromgo.Source) (Result, error) { return KolmogorovSmirnov(s, 10000) }, minSource{rand_fromgo.NewSource(2)}},
+		{"runs on sticky", func(s rand_fromgo.Source) (Result, error) { return Runs(s, 10000) }, &stickySource{Source: rand_fromgo.NewSource(3)}},
+		{"serial on sticky", func(s rand_fromgo.Source) (Result, error) { return SerialCorrelation(s, 10000, 1) }, &stickySource{Source: rand_fromgo.NewSource(3)}},
+		{"gap on sticky", func(s rand_fromgo.Source) (Result, error) { return Gap(s, 10000, 0, 0.5, 10) }, &stickySource{Source: rand_fromgo.NewSource(3)}},
+		{"birthday on coarse", func(s rand_fromgo.Source) (Result, error) { return BirthdaySpacings(s, 512, 24, 50) }, coarseSource{rand_fromgo.NewSource(4)}},
+	}
+	for _, test := range tests {
+		r, err := test.run(test.src)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if r.Pass(1e-4) {
+			t.Errorf("Error %s should fail, got %v.", test.name, r)
+		}
+	}
+}
+
+// countSource 會記錄被取用的次數，用來確認參數錯誤時檢定沒有取用任何亂數。
+type countSource struct {
+	rand_fromgo.Source
+	n int
+}
+
+func (s *countSource) Int63() int64 {
+	s.n++
+	return s.Source.Int63()
+}
+
+// Test_InvalidArguments 是測試參數超出範圍時回傳錯誤，而不是 panic、無窮迴圈或回傳 NaN。
+func Test_InvalidArguments(t *testing.T) {
+	var tests = []struct {
+		name string
+		run  func(src rand_fromgo.Source) (Result, error)
+	}{
+		{"chi-square with 0 bins", func(s rand_fromgo.Source) (Result, error) { return ChiSquare(s, 1000, 0) }},
+		{"chi-square with 1 bin", func(s rand_fromgo.Source) (Result, error) { return ChiSquare(s, 1000, 1) }},
+		{"chi-square with n = 0", func(s rand_fromgo.Source) (Result, error) { return ChiSquare(s, 0, 10) }},
+		{"KS with n = 1", func(s rand_fromgo.Source) (Result, error) { return KolmogorovSmirnov(s, 1) }},
+		{"KS with n = 0", func(s rand_fromgo.Source) (Result, error) { return KolmogorovSmirnov(s, 0) }},
+		{"runs with n = 1", func(s rand_fromgo.Source) (Result, error) { return Runs(s, 1) }},
+		{"serial with lag 0", func(s rand_fromgo.Source) (Result, error) { return SerialCorrelation(s, 1000, 0) }},
+		{"serial with n = 1", func(s rand_fromgo.Source) (Result, error) { return SerialCorrelation(s, 1, 1) }},
+		{"birthday with m = 1", func(s rand_fromgo.Source) (Result, error) { return BirthdaySpacings(s, 1, 24, 1) }},
+		{"birthday with 65 bits", func(s rand_fromgo.Source) (Result, error) { return BirthdaySpacings(s, 512, 65, 1) }},
+		{"birthday with 0 trials", func(s rand_fromgo.Source) (Result, error) { return BirthdaySpacings(s, 512, 24, 0) }},
+		{"gap with alpha = beta", func(s rand_fromgo.Source) (Result, error) { return Gap(s, 100, 0.5, 0.5, 10) }},
+		{"gap with alpha > beta", func(s rand_fromgo.Source) (Result, error) { return Gap(s, 100, 0.6, 0.4, 10) }},
+		{"gap with beta > 1", func(s rand_fromgo.Source) (Result, error) { return Gap(s, 100, 1, 2, 10) }},
+		{"gap with n = 0", func(s rand_fromgo.Source) (Result, error) { return Gap(s, 0, 0, 0.5, 10) }},
+		{"suite with n = 4", func(s rand_fromgo.Source) (Result, error) { _, err := Suite(s, 4); return Result{}, err }},
+	}
+	for _, test := range tests {
+		src := &countSource{Source: rand_fromgo.NewSource(6)}
+		if _, err := test.run(src); err == nil {
+			t.Errorf("Error %s should return an error.", test.name)
+		}
+		if src.n != 0 {
+			t.Errorf("Error %s used %d random numbers.", test.name, src.n)
+		}
+	}
+	// 邊界上合法的參數應該得到有限的統計量與 p 值。
+	var valid = []struct {
+		name string
+		run  func(src rand_fromgo.Source) (Result, error)
+	}{
+		{"KS with n = 2", func(s rand_fromgo.Source) (Result, error) { return KolmogorovSmirnov(s, 2) }},
+		{"runs with n = 2", func(s rand_fromgo.Source) (Result, error) { return Runs(s, 2) }},
+		{"birthday with 64 bits", func(s rand_fromgo.Source) (Result, error) { return BirthdaySpacings(s, 2, 64, 1) }},
+		{"suite with n = 5", func(s rand_fromgo.Source) (Result, error) {
+			rs, err := Suite(s, 5)
+			if err != nil {
+				return Result{}, err
+			}
+			return rs[len(rs)-1], nil
+		}},
+	}
+	for _, test := range valid {
+		r, err := test.run(rand_fromgo.NewSource(7))
+		if err != nil {
+			t.Errorf("Error %s: %v", test.name, err)
+			continue
+		}
+		if math.IsNaN(r.Statistic) || math.IsNaN(r.PValue) {
+			t.Errorf("Error %s got %v.", test.name, r)
+		}
+	}
+}
+
+// fixedSource 依序回傳 values 中的值，用來產生已知的生日。
+type fixedSource struct {
+	rand_fromgo.Source
+	values []uint64
+}
+
+func (s *fixedSource) Uint64() (v uint64) {
+	v, s.values = s.values[0], s.values[1:]
+	return v
+}
+
+// Test_BirthdayWrap 是測試生日間距包含跨年的間距 days[0] + 2^bits - days[m-1]。
+func Test_BirthdayWrap(t *testing.T) {
+	// 4 位元（16 天）中的生日 1、5、9、13：相鄰間距為 4、4、4，跨年間距 1 + 16 - 13 = 4，共 3 個重複。
+	// 若第一個間距誤用 days[0] = 1，則只有 2 個重複。
+	src := &fixedSource{Source: rand_fromgo.NewSource(8), values: []uint64{13 << 60, 1 << 60, 9 << 60, 5 << 60}}
+	r, err := BirthdaySpacings(src, 4, 4, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Statistic != 3 {
+		t.Errorf("Error birthday spacings found %g repeats, should be 3.", r.Statistic)
+	}
+}
+
+// Test_WriteTable 是測試比較表格的每一列為一個檢定，並標記未通過的 p 值。
+func Test_WriteTable(t *testing.T) {
+	good, err := Suite(rand_fromgo.NewPCG(1, 2), 20000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bad, err := Suite(&stickySource{Source: rand_fromgo.NewSource(5)}, 20000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := WriteTable(&buf, []string{"PCG", "sticky"}, [][]Result{good, bad}, 1e-4); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 1+len(good) || !strings.Contains(lines[0], "PCG") || !strings.Contains(lines[0], "sticky") {
+		t.Fatalf("Error table:\n%s", buf.String())
+	}
+	if !strings.Contains(buf.String(), "*") {
+		t.Errorf("Error table should mark the failing sticky source:\n%s", buf.String())
+	}
+	var errTests = []struct {
+		name    string
+		names   []string
+		results [][]Result
+	}{
+		{"empty results", nil, nil},
+		{"missing names", []string{"PCG"}, [][]Result{good, bad}},
+		{"different lengths", []string{"PCG", "sticky"}, [][]Result{good, bad[:2]}},
+	}
+	for _, test := range errTests {
+		if err := WriteTable(&buf, test.names, test.results, 1e-4); err == nil {
+			t.Errorf("Error %s should return an error.", test.name)
+		}
+	}
+}
+
+// Benchmark_Suite 是測試以 10 萬個亂數執行所有檢定的效能。
+func Benchmark_Suite(b *testing.B) {
+	src := rand_fromgo.NewSource(1)
+	for i := 0; i < b.N; i++ {
+		if _, err := Suite(src, 100000); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,262 @@
+package rand_fromgo
+
+import (
+	"math"
+)
+
+/*
+ * Parameterized distributions built on Float64, NormFloat64 and ExpFloat64.
+ * Every sampler panics on invalid parameters, like Intn does.
+ */
+
+// region struct
+
+// Alias is a precomputed table for drawing from a fixed categorical
+// distribution in O(1) per sample (Vose's alias method).
+type Alias struct {
+	prob  []float64
+	alias []int
+}
+
+// endregion struct
+
+// region function
+
+// NewAlias builds an alias table for the categorical distribution whose
+// probabilities are proportional to weights. It panics if a weight is
+// negative or not finite, or if no weight is positive.
+func NewAlias(weights []float64) *Alias {
+	sum := checkWeights(weights, "NewAlias")
+	n := len(weights)
+	a := &Alias{prob: make([]float64, n), alias: make([]int, n)}
+	scaled := make([]float64, n)
+	var small, large []int
+	for i, w := range weights {
+		scaled[i] = w * float64(n) / sum
+		if scaled[i] < 1 {
+			small = append(small, i)
+		} else {
+			large = append(large, i)
+		}
+	}
+	for len(small) > 0 && len(large) > 0 {
+		s, l := small[len(small)-1], large[len(large)-1]
+		small = small[:len(small)-1]
+		a.prob[s], a.alias[s] = scaled[s], l
+		scaled[l] -= 1 - scaled[s]
+		if scaled[l] < 1 {
+			large = large[:len(large)-1]
+			small = append(small, l)
+		}
+	}
+	// Whatever is left over is 1 up to rounding error.
+	for _, i := range append(small, large...) {
+		a.prob[i], a.alias[i] = 1, i
+	}
+	return a
+}
+
+// checkWeights returns the sum of weights and panics if they do not
+// describe a categorical distribution.
+func checkWeights(weights []float64, name string) (sum float64) {
+	for _, w := range weights {
+		if w < 0 || math.IsNaN(w) || math.IsInf(w, 0) {
+			panic("invalid weight in " + name)
+		}
+		sum += w
+	}
+	if sum <= 0 || math.IsInf(sum, 0) {
+		panic("invalid argument to " + name)
+	}
+	return sum
+}
+
+// chopDown samples a discrete distribution on [lo, hi] by inversion,
+// starting at the mode m with probability pm and walking outwards one step
+// at a time. down(k) must return P(k-1)/P(k) and up(k) must return
+// P(k+1)/P(k). The expected number of steps is about one standard deviation.
+func chopDown(u float64, m, lo, hi int, pm float64, down, up func(k int) float64) int {
+	if u -= pm; u <= 0 {
+		return m
+	}
+	l, h, pl, ph := m, m, pm, pm
+	for l > lo || (h < hi && ph > 0) {
+		if l > lo {
+			pl *= down(l)
+			l--
+			if u -= pl; u <= 0 {
+				return l
+			}
+		}
+		if h < hi && ph > 0 {
+			ph *= up(h)
+			h++
+			if u -= ph; u <= 0 {
+				return h
+			}
+		}
+	}
+	// Only reachable through rounding error in the probabilities.
+	return m
+}
+
+// endregion function
+
+// region method
+
+// Normal returns a normally distributed float64 with the given mean and
+// standard deviation. It panics if stddev < 0.
+func (r *Rand) Normal(mean, stddev float64) float64 {
+	if stddev < 0 {
+		panic("invalid argument to Normal")
+	}
+	return mean + stddev*r.NormFloat64()
+}
+
+// TruncNormal returns a float64 drawn from the normal distribution with the
+// given mean and standard deviation, conditioned on lying in [lo, hi].
+// It uses the exact rejection samplers of Robert (1995), so it stays fast
+// even when [lo, hi] is far out in a tail. It panics if stddev <= 0 or lo >= hi.
+func (r *Rand) TruncNormal(mean, stddev, lo, hi float64) float64 {
+	if stddev <= 0 || !(lo < hi) {
+		panic("invalid argument to TruncNormal")
+	}
+	a, b := (lo-mean)/stddev, (hi-mean)/stddev
+	var z float64
+	switch {
+	case a >= 0:
+		z = r.truncTail(a, b)
+	case b <= 0:
+		z = -r.truncTail(-b, -a)
+	case b-a >= math.Sqrt(2*math.Pi):
+		// The interval contains 0 and is at least sqrt(2*pi) wide, so in the
+		// worst case (one end at 0) it still holds Phi(sqrt(2*pi)) - 0.5,
+		// about 49% of the mass. Plain rejection from the normal then needs
+		// at most about two draws on average, which beats the uniform
+		// proposal below on wide intervals.
+		for z = r.NormFloat64(); z < a || z > b; z = r.NormFloat64() {
+		}
+	default:
+		// Uniform proposal on a narrow interval that contains 0.
+		for {
+			z = a + (b-a)*r.Float64()
+			if r.Float64() <= math.Exp(-z*z/2) {
+				break
+			}
+		}
+	}
+	return mean + stddev*z
+}
+
+// truncTail samples a standard normal restricted to [a, b] with 0 <= a < b.
+func (r *Rand) truncTail(a, b float64) float64 {
+	alpha := (a + math.Sqrt(a*a+4)) / 2
+	if b-a > 1/alpha {
+		// Translated exponential proposal.
+		for {
+			z := a + r.ExpFloat64()/alpha
+			d := z - alpha
+			if z <= b && r.Float64() <= math.Exp(-d*d/2) {
+				return z
+			}
+		}
+	}
+	// Uniform proposal on a narrow interval.
+	for {
+		z := a + (b-a)*r.Float64()
+		if r.Float64() <= math.Exp((a*a-z*z)/2) {
+			return z
+		}
+	}
+}
+
+// Uniform returns a uniformly distributed float64 in [lo, hi).
+// It panics if lo > hi.
+func (r *Rand) Uniform(lo, hi float64) float64 {
+	if lo > hi {
+		panic("invalid argument to Uniform")
+	}
+	return lo + (hi-lo)*r.Float64()
+}
+
+// Bernoulli returns true with probability p.
+// It panics if p is not in [0, 1].
+func (r *Rand) Bernoulli(p float64) bool {
+	if !(p >= 0 && p <= 1) {
+		panic("invalid argument to Bernoulli")
+	}
+	return r.Float64() < p
+}
+
+// Binomial returns the number of successes in n independent trials that
+// each succeed with probability p. It panics if n < 0 or p is not in [0, 1].
+func (r *Rand) Binomial(n int, p float64) int {
+	if n < 0 || !(p >= 0 && p <= 1) {
+		panic("invalid argument to Binomial")
+	}
+	if p == 0 || n == 0 {
+		return 0
+	}
+	if p == 1 {
+		return n
+	}
+	if p > 0.5 {
+		return n - r.Binomial(n, 1-p)
+	}
+	q := 1 - p
+	m := int(float64(n+1) * p) // mode
+	lg := func(x int) float64 { v, _ := math.Lgamma(float64(x) + 1); return v }
+	pm := math.Exp(lg(n) - lg(m) - lg(n-m) + float64(m)*math.Log(p) + float64(n-m)*math.Log(q))
+	return chopDown(r.Float64(), m, 0, n, pm,
+		func(k int) float64 { return float64(k) / float64(n-k+1) * q / p },
+		func(k int) float64 { return float64(n-k) / float64(k+1) * p / q })
+}
+
+// Poisson returns a Poisson distributed int with mean lambda.
+// It panics if lambda < 0 or lambda is not finite.
+func (r *Rand) Poisson(lambda float64) int {
+	if !(lambda >= 0) || math.IsInf(lambda, 0) {
+		panic("invalid argument to Poisson")
+	}
+	if lambda == 0 {
+		return 0
+	}
+	m := int(lambda) // mode
+	lgm, _ := math.Lgamma(float64(m) + 1)
+	pm := math.Exp(float64(m)*math.Log(lambda) - lambda - lgm)
+	return chopDown(r.Float64(), m, 0, math.MaxInt, pm,
+		func(k int) float64 { return float64(k) / lambda },
+		func(k int) float64 { return lambda / float64(k+1) })
+}
+
+// Categorical returns an index i in [0, len(weights)) with probability
+// weights[i]/sum(weights). It takes O(len(weights)) time; use NewAlias
+// when drawing many samples from the same weights. It panics if a weight is
+// negative or not finite, or if no weight is positive.
+func (r *Rand) Categorical(weights []float64) int {
+	u := r.Float64() * checkWeights(weights, "Categorical")
+	last := 0
+	for i, w := range weights {
+		if w == 0 {
+			continue
+		}
+		if u < w {
+			return i
+		}
+		u -= w
+		last = i
+	}
+	// Only reachable through rounding error in the sum.
+	return last
+}
+
+// Sample draws an index from the alias table using r.
+func (a *Alias) Sample(r *Rand) int {
+	i := r.Intn(len(a.prob))
+	if r.Float64() < a.prob[i] {
+		return i
+	}
+	return a.alias[i]
+}
+
+// endregion method
@@ -0,0 +1,164 @@
+package rand_fromgo
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+// moments 會回傳 n 個樣本的平均值與變異數。
+func moments(n int, sample func() float64) (mean, variance float64) {
+	sum, sq := 0.0, 0.0
+	for i := 0; i < n; i++ {
+		v := sample()
+		sum += v
+		sq += v * v
+	}
+	mean = sum / float64(n)
+	return mean, sq/float64(n) - mean*mean
+}
+
+// Test_NormExpMatchMathRand 是測試 NormFloat64 與 ExpFloat64 和 math/rand 產生相同的數列。
+func Test_NormExpMatchMathRand(t *testing.T) {
+	ours, std := New(NewSource(9)), rand.New(rand.NewSource(9))
+	for n := 0; n < 1000; n++ {
+		if a, b := ours.NormFloat64(), std.NormFloat64(); a != b {
+			t.Fatalf("Error NormFloat64 call %d = %g, should be %g.", n, a, b)
+		}
+		if a, b := ours.ExpFloat64(), std.ExpFloat64(); a != b {
+			t.Fatalf("Error ExpFloat64 call %d = %g, should be %g.", n, a, b)
+		}
+	}
+}
+
+// Test_Moments 是測試各分佈樣本的平均值與變異數接近理論值（容許約 5 個標準誤）。
+func Test_Moments(t *testing.T) {
+	r := New(NewSource(10))
+	// 標準常態截在 [0, ∞) 的半常態分佈：平均 sqrt(2/π)、變異數 1-2/π。
+	half := math.Sqrt(2 / math.Pi)
+	var tests = []struct {
+		name     string
+		sample   func() float64
+		mean     float64
+		variance float64
+	}{
+		{"Normal", func() float64 { return r.Normal(3, 2) }, 3, 4},
+		{"Uniform", func() float64 { return r.Uniform(-1, 3) }, 1, 16.0 / 12},
+		{"Exp", r.ExpFloat64, 1, 1},
+		{"Bernoulli", func() float64 {
+			if r.Bernoulli(0.3) {
+				return 1
+			}
+			return 0
+		}, 0.3, 0.21},
+		{"Binomial small", func() float64 { return float64(r.Binomial(10, 0.3)) }, 3, 2.1},
+		{"Binomial large", func() float64 { return float64(r.Binomial(100000, 0.7)) }, 70000, 21000},
+		{"Poisson small", func() float64 { return float64(r.Poisson(0.5)) }, 0.5, 0.5},
+		{"Poisson large", func() float64 { return float64(r.Poisson(1000)) }, 1000, 1000},
+		{"TruncNormal half", func() float64 { return r.TruncNormal(0, 1, 0, 1e9) }, half, 1 - 2/math.Pi},
+		{"TruncNormal symmetric", func() float64 { return r.TruncNormal(5, 1, 4.9, 5.1) }, 5, 0.04 / 12},
+	}
+	const n = 50000
+	for _, test := range tests {
+		mean, variance := moments(n, test.sample)
+		se := math.Sqrt(test.variance / n)
+		if math.Abs(mean-test.mean) > 5*se {
+			t.Errorf("Error %s mean = %g, should be about %g.", test.name, mean, test.mean)
+		}
+		if math.Abs(variance-test.variance) > 0.05*test.variance {
+			t.Errorf("Error %s variance = %g, should be about %g.", test.name, variance, test.variance)
+		}
+	}
+}
+
+// Test_TruncNormal 是測試截尾常態分佈的樣本都落在範圍內，包含範圍遠在尾端的情況。
+func Test_TruncNormal(t *testing.T) {
+	r := New(NewSource(11))
+	var tests = []struct {
+		lo, hi float64
+	}{
+		{-2, 2},
+		{-0.1, 0.2},
+		{8, 9},
+		{8, 8.01},
+		{-30, -20},
+	}
+	for _, test := range tests {
+		for n := 0; n < 10000; n++ {
+			if v := r.TruncNormal(0, 1, test.lo, test.hi); v < test.lo || v > test.hi {
+				t.Fatalf("Error TruncNormal in [%g, %g] returned %g.", test.lo, test.hi, v)
+			}
+		}
+	}
+	// 遠在右尾 [8, ∞) 時，超出 8 的部分近似平均為 1/8 的指數分佈。
+	mean, _ := moments(20000, func() float64 { return r.TruncNormal(0, 1, 8, math.Inf(1)) - 8 })
+	if math.Abs(mean-0.1231) > 0.005 {
+		t.Errorf("Error tail excess mean = %g, should be about 0.123.", mean)
+	}
+}
+
+// Test_Categorical 是測試 Categorical 與 Alias 的抽樣頻率符合權重，權重為 0 的類別不會被抽到。
+func Test_Categorical(t *testing.T) {
+	r := New(NewSource(12))
+	weights := []float64{1, 0, 2, 7}
+	alias := NewAlias(weights)
+	const n = 100000
+	var a, b [4]int
+	for i := 0; i < n; i++ {
+		a[r.Categorical(weights)]++
+		b[alias.Sample(r)]++
+	}
+	for i, w := range weights {
+		p := w / 10
+		se := math.Sqrt(p*(1-p)/n) + 1e-12
+		for _, c := range [][4]int{a, b} {
+			if math.Abs(float64(c[i])/n-p) > 5*se {
+				t.Errorf("Error category %d frequency %g, should be %g.", i, float64(c[i])/n, p)
+			}
+		}
+	}
+}
+
+// Test_DistPanics 是測試不合法的參數會 panic。
+func Test_DistPanics(t *testing.T) {
+	r := New(NewSource(1))
+	var tests = []struct {
+		name string
+		f    func()
+	}{
+		{"Normal", func() { r.Normal(0, -1) }},
+		{"TruncNormal", func() { r.TruncNormal(0, 1, 2, 1) }},
+		{"Uniform", func() { r.Uniform(1, 0) }},
+		{"Bernoulli", func() { r.Bernoulli(1.5) }},
+		{"Binomial", func() { r.Binomial(-1, 0.5) }},
+		{"Poisson", func() { r.Poisson(math.NaN()) }},
+		{"Categorical", func() { r.Categorical([]float64{0, 0}) }},
+		{"NewAlias", func() { NewAlias([]float64{1, -1}) }},
+	}
+	for _, test := range tests {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("Error %s should panic.", test.name)
+				}
+			}()
+			test.f()
+		}()
+	}
+}
+
+// Benchmark_NormFloat64 是測試 NormFloat64 的效能。
+func Benchmark_NormFloat64(b *testing.B) {
+	r := New(NewSource(1))
+	for i := 0; i < b.N; i++ {
+		r.NormFloat64()
+	}
+}
+
+// Benchmark_Poisson 是測試平均值為 1000 的 Poisson 抽樣效能。
+func Benchmark_Poisson(b *testing.B) {
+	r := New(NewSource(1))
+	for i := 0; i < b.N; i++ {
+		r.Poisson(1000)
+	}
+}
@@ -0,0 +1,159 @@
+// How to use:
+//
+// 1. Testing
+// (1) > cd "%GOPATH%\src\github.com\oneleo\LeNetPractice\mAiLab_0002\rand_fromgo"
+// or (1) $ cd "$GOPATH/src/github.com/oneleo/LeNetPractice/mAiLab_0002/rand_fromgo"
+// (2) $> go test -v
+//
+// 2. Benchmark:
+// (1) > cd "%GOPATH%\src\github.com\oneleo\LeNetPractice\mAiLab_0002\rand_fromgo"
+// or (1) $ cd "$GOPATH/src/github.com/oneleo/LeNetPractice/mAiLab_0002/rand_fromgo"
+// (2) $> go test -bench={Mathod Name} -v
+// such as: $> go test -bench=Benchmark_Intn -v
+//
+// 3. Test all:
+// $> go test -bench=. -v
+
+package rand_fromgo
+
+import (
+	"bytes"
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+// Test_MatchMathRand 是測試 rand_fromgo 與標準函式庫 math/rand 在相同種子下產生完全相同的數列。
+func Test_MatchMathRand(t *testing.T) {
+	// 定義測試集 Struct，每一個測試以同樣的方法各自從 rand_fromgo 與 math/rand 取值。
+	var tests = []struct {
+		name string
+		ours func(r *Rand) interface{}
+		std  func(r *rand.Rand) interface{}
+	}{
+		{"Int63", func(r *Rand) interface{} { return r.Int63() }, func(r *rand.Rand) interface{} { return r.Int63() }},
+		{"Uint32", func(r *Rand) interface{} { return r.Uint32() }, func(r *rand.Rand) interface{} { return r.Uint32() }},
+		{"Uint64", func(r *Rand) interface{} { return r.Uint64() }, func(r *rand.Rand) interface{} { return r.Uint64() }},
+		{"Int31", func(r *Rand) interface{} { return r.Int31() }, func(r *rand.Rand) interface{} { return r.Int31() }},
+		{"Int", func(r *Rand) interface{} { return r.Int() }, func(r *rand.Rand) interface{} { return r.Int() }},
+		{"Int63n", func(r *Rand) interface{} { return r.Int63n(1e18 + 7) }, func(r *rand.Rand) interface{} { return r.Int63n(1e18 + 7) }},
+		{"Int63n pow2", func(r *Rand) interface{} { return r.Int63n(1 << 40) }, func(r *rand.Rand) interface{} { return r.Int63n(1 << 40) }},
+		{"Int31n", func(r *Rand) interface{} { return r.Int31n(1e9 + 7) }, func(r *rand.Rand) interface{} { return r.Int31n(1e9 + 7) }},
+		{"Intn", func(r *Rand) interface{} { return r.Intn(10) }, func(r *rand.Rand) interface{} { return r.Intn(10) }},
+		{"Intn large", func(r *Rand) interface{} { return r.Intn(1 << 40) }, func(r *rand.Rand) interface{} { return r.Intn(1 << 40) }},
+		{"Float64", func(r *Rand) interface{} { return r.Float64() }, func(r *rand.Rand) interface{} { return r.Float64() }},
+		{"Perm", func(r *Rand) interface{} { return r.Perm(20) }, func(r *rand.Rand) interface{} { return r.Perm(20) }},
+		{"Shuffle", func(r *Rand) interface{} {
+			s := []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}
+			r.Shuffle(len(s), func(i, j int) { s[i], s[j] = s[j], s[i] })
+			return s
+		}, func(r *rand.Rand) interface{} {
+			s := []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}
+			r.Shuffle(len(s), func(i, j int) { s[i], s[j] = s[j], s[i] })
+			return s
+		}},
+		{"Read", func(r *Rand) interface{} {
+			p := make([]byte, 13)
+			r.Read(p)
+			return p
+		}, func(r *rand.Rand) interface{} {
+			p := make([]byte, 13)
+			r.Read(p)
+			return p
+		}},
+	}
+	for _, test := range tests {
+		for _, seed := range []int64{1, 42, -7} {
+			ours, std := New(NewSource(seed)), rand.New(rand.NewSource(seed))
+			for n := 0; n < 100; n++ {
+				if a, b := test.ours(ours), test.std(std); !reflect.DeepEqual(a, b) {
+					t.Fatalf("Error %s seed %d call %d: got %v, should be %v.", test.name, seed, n, a, b)
+				}
+			}
+		}
+	}
+}
+
+// Test_Read 是測試 Read 分多次呼叫時會接續上一次未用完的位元組，且 Seed 會重設讀取位置。
+func Test_Read(t *testing.T) {
+	r := New(NewSource(3))
+	whole := make([]byte, 20)
+	r.Read(whole)
+	r.Seed(3)
+	parts := make([]byte, 0, 20)
+	for _, n := range []int{3, 5, 1, 11} {
+		p := make([]byte, n)
+		if got, err := r.Read(p); got != n || err != nil {
+			t.Fatalf("Error Read returned %d, %v.", got, err)
+		}
+		parts = append(parts, p...)
+	}
+	if !bytes.Equal(whole, parts) {
+		t.Errorf("Error split reads %v differ from a single read %v.", parts, whole)
+	}
+}
+
+// Test_Panics 是測試不合法的參數會 panic。
+func Test_Panics(t *testing.T) {
+	r := New(NewSource(1))
+	var tests = []struct {
+		name string
+		f    func()
+	}{
+		{"Intn(0)", func() { r.Intn(0) }},
+		{"Int31n(-1)", func() { r.Int31n(-1) }},
+		{"Int63n(0)", func() { r.Int63n(0) }},
+		{"Shuffle(-1)", func() { r.Shuffle(-1, func(i, j int) {}) }},
+	}
+	for _, test := range tests {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("Error %s should panic.", test.name)
+				}
+			}()
+			test.f()
+		}()
+	}
+}
+
+// Test_Global 是測試套件層級的函數使用同一個預設亂數來源，且可以在多個 goroutine 中同時呼叫。
+func Test_Global(t *testing.T) {
+	Seed(5)
+	want := New(NewSource(5))
+	if a, b := Intn(1000), want.Intn(1000); a != b {
+		t.Errorf("Error Intn = %d, should be %d.", a, b)
+	}
+	done := make(chan bool)
+	for g := 0; g < 4; g++ {
+		go func() {
+			p := make([]byte, 8)
+			for n := 0; n < 100; n++ {
+				Uint64()
+				Read(p)
+				Perm(5)
+			}
+			done <- true
+		}()
+	}
+	for g := 0; g < 4; g++ {
+		<-done
+	}
+}
+
+// Benchmark_Intn 是測試 Intn 的效能。
+func Benchmark_Intn(b *testing.B) {
+	r := New(NewSource(1))
+	for i := 0; i < b.N; i++ {
+		r.Intn(1000)
+	}
+}
+
+// Benchmark_Shuffle 是測試打亂 1000 個元素的效能。
+func Benchmark_Shuffle(b *testing.B) {
+	r := New(NewSource(1))
+	s := make([]int, 1000)
+	for i := 0; i < b.N; i++ {
+		r.Shuffle(len(s), func(i, j int) { s[i], s[j] = s[j], s[i] })
+	}
+}
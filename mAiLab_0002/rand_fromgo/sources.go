@@ -0,0 +1,331 @@
+package rand_fromgo
+
+import (
+	"encoding/binary"
+	"math/bits"
+)
+
+/*
+ * Alternative Source64 implementations. Unlike rngSource, whose state is a
+ * 607-word table, these keep between 8 and a few hundred bytes of state and
+ * are cheap to create, copy and seed. None of them are safe for concurrent
+ * use by multiple goroutines.
+ *
+ * PCG and ChaCha8 produce exactly the same streams as the types of the same
+ * name in Go's math/rand/v2 for the same seeds.
+ */
+
+// region struct
+
+// SplitMix64 is Steele, Lea and Flood's SplitMix64 generator: a Weyl
+// sequence passed through a 64-bit mixing function. It has a 64-bit state
+// and a period of 2^64, and is mainly used to expand a single seed into
+// the larger states of the other generators.
+type SplitMix64 struct {
+	state uint64
+}
+
+// Xoshiro256 is Blackman and Vigna's xoshiro256** generator, with a 256-bit
+// state and a period of 2^256-1.
+type Xoshiro256 struct {
+	s [4]uint64
+}
+
+// PCG is a PCG generator with 128 bits of state and the DXSM output
+// function, as used by Go's math/rand/v2.
+type PCG struct {
+	hi uint64
+	lo uint64
+}
+
+// ChaCha8 is a ChaCha8-based cryptographically strong generator, as used by
+// Go's math/rand/v2 and runtime. Every 16 blocks it reseeds itself from its
+// own output, so a captured state does not reveal earlier values.
+type ChaCha8 struct {
+	seed [4]uint64
+	buf  [32]uint64
+	i    uint32 // next value in buf
+	n    uint32 // number of usable values in buf
+	c    uint32 // block counter
+}
+
+// endregion struct
+
+// region function
+
+// NewSplitMix64 returns a SplitMix64 source seeded with seed.
+func NewSplitMix64(seed int64) *SplitMix64 {
+	return &SplitMix64{state: uint64(seed)}
+}
+
+// NewXoshiro256 returns a xoshiro256** source whose state is filled from a
+// SplitMix64 generator seeded with seed, as recommended by its authors.
+func NewXoshiro256(seed int64) *Xoshiro256 {
+	x := new(Xoshiro256)
+	x.Seed(seed)
+	return x
+}
+
+// NewPCG returns a PCG source with state (seed1, seed2).
+func NewPCG(seed1, seed2 uint64) *PCG {
+	return &PCG{hi: seed1, lo: seed2}
+}
+
+// NewChaCha8 returns a ChaCha8 source seeded with the 32-byte seed.
+func NewChaCha8(seed [32]byte) *ChaCha8 {
+	c := new(ChaCha8)
+	c.SeedBytes(seed)
+	return c
+}
+
+// splitmix64 advances the SplitMix64 state x and returns the next value.
+func splitmix64(x *uint64) uint64 {
+	*x += 0x9e3779b97f4a7c15
+	z := *x
+	z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+	z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+	return z ^ (z >> 31)
+}
+
+// ChaCha8 parameters: each block call produces 32 words, the counter
+// advances by 4 per block call, and after 16 the generator reseeds itself
+// with the last 4 words of the final block.
+const (
+	chachaCtrInc = 4
+	chachaCtrMax = 16
+	chachaChunk  = 32
+	chachaReseed = 4
+)
+
+// chachaBlock computes four interleaved ChaCha8 blocks for seed and
+// counters counter..counter+3, and stores them in buf in the same word order
+// as Go's internal/chacha8rand on a little-endian machine.
+func chachaBlock(seed *[4]uint64, buf *[32]uint64, counter uint32) {
+	var b [16][4]uint32
+	for i := 0; i < 4; i++ {
+		b[0][i] = 0x61707865
+		b[1][i] = 0x3320646e
+		b[2][i] = 0x79622d32
+		b[3][i] = 0x6b206574
+		for k := 0; k < 4; k++ {
+			b[4+2*k][i] = uint32(seed[k])
+			b[5+2*k][i] = uint32(seed[k] >> 32)
+		}
+		b[12][i] = counter + uint32(i)
+	}
+
+	for i := range b[0] {
+		b0, b1, b2, b3 := b[0][i], b[1][i], b[2][i], b[3][i]
+		b4, b5, b6, b7 := b[4][i], b[5][i], b[6][i], b[7][i]
+		b8, b9, b10, b11 := b[8][i], b[9][i], b[10][i], b[11][i]
+		b12, b13, b14, b15 := b[12][i], b[13][i], b[14][i], b[15][i]
+
+		for round := 0; round < 4; round++ {
+			b0, b4, b8, b12 = chachaQR(b0, b4, b8, b12)
+			b1, b5, b9, b13 = chachaQR(b1, b5, b9, b13)
+			b2, b6, b10, b14 = chachaQR(b2, b6, b10, b14)
+			b3, b7, b11, b15 = chachaQR(b3, b7, b11, b15)
+
+			b0, b5, b10, b15 = chachaQR(b0, b5, b10, b15)
+			b1, b6, b11, b12 = chachaQR(b1, b6, b11, b12)
+			b2, b7, b8, b13 = chachaQR(b2, b7, b8, b13)
+			b3, b4, b9, b14 = chachaQR(b3, b4, b9, b14)
+		}
+
+		// Only the key rows get the input added back, as in chacha8rand;
+		// the constant and counter rows are known to an attacker anyway.
+		b[0][i], b[1][i], b[2][i], b[3][i] = b0, b1, b2, b3
+		b[4][i] += b4
+		b[5][i] += b5
+		b[6][i] += b6
+		b[7][i] += b7
+		b[8][i] += b8
+		b[9][i] += b9
+		b[10][i] += b10
+		b[11][i] += b11
+		b[12][i], b[13][i], b[14][i], b[15][i] = b12, b13, b14, b15
+	}
+
+	for k := range buf {
+		w := 2 * k
+		buf[k] = uint64(b[w/4][w%4]) | uint64(b[(w+1)/4][(w+1)%4])<<32
+	}
+}
+
+// chachaQR is the ChaCha quarter round.
+func chachaQR(a, b, c, d uint32) (uint32, uint32, uint32, uint32) {
+	a += b
+	d ^= a
+	d = bits.RotateLeft32(d, 16)
+	c += d
+	b ^= c
+	b = bits.RotateLeft32(b, 12)
+	a += b
+	d ^= a
+	d = bits.RotateLeft32(d, 8)
+	c += d
+	b ^= c
+	b = bits.RotateLeft32(b, 7)
+	return a, b, c, d
+}
+
+// endregion function
+
+// region method
+
+// Seed sets the state to seed.
+func (s *SplitMix64) Seed(seed int64) { s.state = uint64(seed) }
+
+// Uint64 returns a pseudo-random 64-bit value.
+func (s *SplitMix64) Uint64() uint64 { return splitmix64(&s.state) }
+
+// Int63 returns a non-negative pseudo-random 63-bit integer.
+func (s *SplitMix64) Int63() int64 { return int64(s.Uint64() & (1<<63 - 1)) }
+
+// Seed fills the state from a SplitMix64 generator seeded with seed.
+// The resulting state is never all zero.
+func (x *Xoshiro256) Seed(seed int64) {
+	sm := uint64(seed)
+	for i := range x.s {
+		x.s[i] = splitmix64(&sm)
+	}
+	if x.s == [4]uint64{} {
+		x.s[0] = 1
+	}
+}
+
+// Uint64 returns a pseudo-random 64-bit value.
+func (x *Xoshiro256) Uint64() uint64 {
+	s := &x.s
+	result := bits.RotateLeft64(s[1]*5, 7) * 9
+	t := s[1] << 17
+	s[2] ^= s[0]
+	s[3] ^= s[1]
+	s[1] ^= s[2]
+	s[0] ^= s[3]
+	s[2] ^= t
+	s[3] = bits.RotateLeft64(s[3], 45)
+	return result
+}
+
+// Int63 returns a non-negative pseudo-random 63-bit integer.
+func (x *Xoshiro256) Int63() int64 { return int64(x.Uint64() & (1<<63 - 1)) }
+
+// Seed sets the state to two values from a SplitMix64 generator seeded
+// with seed. Use SeedPCG to set the state directly.
+func (p *PCG) Seed(seed int64) {
+	sm := uint64(seed)
+	p.hi = splitmix64(&sm)
+	p.lo = splitmix64(&sm)
+}
+
+// SeedPCG sets the state to (seed1, seed2), like math/rand/v2's PCG.Seed.
+func (p *PCG) SeedPCG(seed1, seed2 uint64) {
+	p.hi, p.lo = seed1, seed2
+}
+
+// next advances the 128-bit LCG state and returns it.
+func (p *PCG) next() (hi, lo uint64) {
+	// https://github.com/imneme/pcg-cpp/blob/428802d1a5/include/pcg_random.hpp#L161
+	//
+	// Numpy's PCG multiplies by the 64-bit value cheapMul
+	// instead of the 128-bit value used here and in the official PCG code.
+	// This does not seem worthwhile, at the cost of losing compatibility
+	// with math/rand/v2.
+	const (
+		mulHi = 2549297995355413924
+		mulLo = 4865540595714422341
+		incHi = 6364136223846793005
+		incLo = 1442695040888963407
+	)
+
+	// state = state * mul + inc
+	hi, lo = bits.Mul64(p.lo, mulLo)
+	hi += p.hi*mulLo + p.lo*mulHi
+	lo, c := bits.Add64(lo, incLo, 0)
+	hi, _ = bits.Add64(hi, incHi, c)
+	p.lo = lo
+	p.hi = hi
+	return hi, lo
+}
+
+// Uint64 returns a pseudo-random 64-bit value using the DXSM output function.
+func (p *PCG) Uint64() uint64 {
+	hi, lo := p.next()
+
+	// XSL-RR would be
+	//	hi, lo := p.next()
+	//	return bits.RotateLeft64(lo^hi, -int(hi>>58))
+	// but Numpy uses DXSM and O'Neill suggests doing the same.
+	// See https://github.com/golang/go/issues/21835#issuecomment-739065688
+	// and following comments.
+
+	// DXSM "double xorshift multiply"
+	// https://github.com/imneme/pcg-cpp/blob/428802d1a5/include/pcg_random.hpp#L1015
+
+	// https://github.com/imneme/pcg-cpp/blob/428802d1a5/include/pcg_random.hpp#L176
+	const cheapMul = 0xda942042e4dd58b5
+	hi ^= hi >> 32
+	hi *= cheapMul
+	hi ^= hi >> 48
+	hi *= (lo | 1)
+	return hi
+}
+
+// Int63 returns a non-negative pseudo-random 63-bit integer.
+func (p *PCG) Int63() int64 { return int64(p.Uint64() & (1<<63 - 1)) }
+
+// Seed seeds the generator with four values from a SplitMix64 generator
+// seeded with seed. Use SeedBytes to set the 32-byte seed directly.
+func (c *ChaCha8) Seed(seed int64) {
+	var key [32]byte
+	sm := uint64(seed)
+	for i := 0; i < 4; i++ {
+		binary.LittleEndian.PutUint64(key[8*i:], splitmix64(&sm))
+	}
+	c.SeedBytes(key)
+}
+
+// SeedBytes seeds the generator with a 32-byte seed, like math/rand/v2's ChaCha8.Seed.
+func (c *ChaCha8) SeedBytes(seed [32]byte) {
+	for i := range c.seed {
+		c.seed[i] = binary.LittleEndian.Uint64(seed[8*i:])
+	}
+	chachaBlock(&c.seed, &c.buf, 0)
+	c.c = 0
+	c.i = 0
+	c.n = chachaChunk
+}
+
+// refill computes the next blocks, reseeding from the output every
+// chachaCtrMax counter values.
+func (c *ChaCha8) refill() {
+	c.c += chachaCtrInc
+	if c.c == chachaCtrMax {
+		// The last chachaReseed words of the previous buffer were
+		// withheld from the output and become the new seed.
+		copy(c.seed[:], c.buf[len(c.buf)-chachaReseed:])
+		c.c = 0
+	}
+	chachaBlock(&c.seed, &c.buf, c.c)
+	c.i = 0
+	c.n = uint32(len(c.buf))
+	if c.c == chachaCtrMax-chachaCtrInc {
+		c.n = uint32(len(c.buf)) - chachaReseed
+	}
+}
+
+// Uint64 returns a pseudo-random 64-bit value.
+func (c *ChaCha8) Uint64() uint64 {
+	if c.i >= c.n {
+		c.refill()
+	}
+	x := c.buf[c.i]
+	c.i++
+	return x
+}
+
+// Int63 returns a non-negative pseudo-random 63-bit integer.
+func (c *ChaCha8) Int63() int64 { return int64(c.Uint64() & (1<<63 - 1)) }
+
+// endregion method
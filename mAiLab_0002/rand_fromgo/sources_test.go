@@ -0,0 +1,126 @@
+package rand_fromgo
+
+import (
+	randv2 "math/rand/v2"
+	"testing"
+)
+
+// 編譯時期確認每一種來源都實作 Source64。
+var (
+	_ Source64 = (*rngSource)(nil)
+	_ Source64 = (*SplitMix64)(nil)
+	_ Source64 = (*Xoshiro256)(nil)
+	_ Source64 = (*PCG)(nil)
+	_ Source64 = (*ChaCha8)(nil)
+)
+
+// Test_KnownAnswers 是測試 SplitMix64 與 xoshiro256** 產生與參考實作相同的數列。
+func Test_KnownAnswers(t *testing.T) {
+	var tests = []struct {
+		name string
+		src  Source64
+		want []uint64
+	}{
+		// 參考實作 splitmix64.c，種子為 0。
+		{"SplitMix64", NewSplitMix64(0), []uint64{
+			0xe220a8397b1dcdaf, 0x6e789e6aa1b965f4, 0x06c45d188009454f, 0xf88bb8a8724c81ec,
+		}},
+		// 參考實作 xoshiro256starstar.c，狀態為 {1, 2, 3, 4}。
+		{"Xoshiro256", &Xoshiro256{s: [4]uint64{1, 2, 3, 4}}, []uint64{
+			11520, 0, 1509978240, 1215971899390074240, 1216172134540287360,
+			607988272756665600, 16172922978634559625, 8476171486693032832,
+			10595114339597558777, 2904607092377533576,
+		}},
+	}
+	for _, test := range tests {
+		for n, want := range test.want {
+			if got := test.src.Uint64(); got != want {
+				t.Fatalf("Error %s call %d = %#x, should be %#x.", test.name, n, got, want)
+			}
+		}
+	}
+}
+
+// Test_MatchRandV2 是測試 PCG 與 ChaCha8 和 math/rand/v2 在相同種子下產生完全相同的數列。
+func Test_MatchRandV2(t *testing.T) {
+	var key [32]byte
+	copy(key[:], "chacha8 seed for rand_fromgo....")
+	var tests = []struct {
+		name string
+		ours Source64
+		std  randv2.Source
+	}{
+		{"PCG", NewPCG(1, 2), randv2.NewPCG(1, 2)},
+		{"PCG zero", NewPCG(0, 0), randv2.NewPCG(0, 0)},
+		{"ChaCha8", NewChaCha8(key), randv2.NewChaCha8(key)},
+		{"ChaCha8 zero", NewChaCha8([32]byte{}), randv2.NewChaCha8([32]byte{})},
+	}
+	for _, test := range tests {
+		// ChaCha8 每 16 個區塊（約 496 個值）會以自己的輸出重新設定種子，取足夠多的值以涵蓋數次重新設定。
+		for n := 0; n < 5000; n++ {
+			if a, b := test.ours.Uint64(), test.std.Uint64(); a != b {
+				t.Fatalf("Error %s call %d = %#x, should be %#x.", test.name, n, a, b)
+			}
+		}
+	}
+}
+
+// Test_SourceSeed 是測試 Seed 會重設數列，且每一種來源都可以交給 New 使用。
+func Test_SourceSeed(t *testing.T) {
+	var tests = []struct {
+		name string
+		src  Source64
+	}{
+		{"SplitMix64", NewSplitMix64(1)},
+		{"Xoshiro256", NewXoshiro256(1)},
+		{"PCG", NewPCG(1, 2)},
+		{"ChaCha8", NewChaCha8([32]byte{1})},
+	}
+	for _, test := range tests {
+		test.src.Seed(42)
+		first := make([]int64, 600)
+		for i := range first {
+			first[i] = test.src.Int63()
+			if first[i] < 0 {
+				t.Fatalf("Error %s Int63 = %d, should be non-negative.", test.name, first[i])
+			}
+		}
+		test.src.Seed(42)
+		for i, want := range first {
+			if got := test.src.Int63(); got != want {
+				t.Fatalf("Error %s after Seed, call %d = %d, should be %d.", test.name, i, got, want)
+			}
+		}
+		test.src.Seed(43)
+		if test.src.Int63() == first[0] {
+			t.Errorf("Error %s gives the same value for seeds 42 and 43.", test.name)
+		}
+		// Rand 會使用 Source64 的 Uint64，其結果的平均值應接近 0.5。
+		r := New(test.src)
+		if mean, _ := moments(100000, r.Float64); mean < 0.49 || mean > 0.51 {
+			t.Errorf("Error %s Float64 mean = %g, should be about 0.5.", test.name, mean)
+		}
+	}
+}
+
+// benchmarkSource 是測試 src 產生 Uint64 的效能。
+func benchmarkSource(b *testing.B, src Source64) {
+	for i := 0; i < b.N; i++ {
+		src.Uint64()
+	}
+}
+
+// Benchmark_RngSource 是測試原本的 rngSource 作為比較基準。
+func Benchmark_RngSource(b *testing.B) { benchmarkSource(b, NewSource(1).(Source64)) }
+
+// Benchmark_SplitMix64 是測試 SplitMix64 的效能。
+func Benchmark_SplitMix64(b *testing.B) { benchmarkSource(b, NewSplitMix64(1)) }
+
+// Benchmark_Xoshiro256 是測試 xoshiro256** 的效能。
+func Benchmark_Xoshiro256(b *testing.B) { benchmarkSource(b, NewXoshiro256(1)) }
+
+// Benchmark_PCG 是測試 PCG 的效能。
+func Benchmark_PCG(b *testing.B) { benchmarkSource(b, NewPCG(1, 2)) }
+
+// Benchmark_ChaCha8 是測試 ChaCha8 的效能。
+func Benchmark_ChaCha8(b *testing.B) { benchmarkSource(b, NewChaCha8([32]byte{1})) }
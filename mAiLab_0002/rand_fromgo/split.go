@@ -0,0 +1,107 @@
+package rand_fromgo
+
+import (
+	"sync"
+)
+
+/*
+ * Splittable streams. The global Rand serializes every call behind a mutex;
+ * for parallel work each goroutine should instead own a child generator.
+ * Children are xoshiro256** generators derived from one root by jump-ahead:
+ * child i starts exactly i*2^128 values after the root, so the streams of
+ * up to 2^128 children never overlap, and the whole run is determined by the
+ * root seed alone.
+ */
+
+// region struct
+
+// Splitter hands out non-overlapping child generators derived from a root
+// seed. Split is safe for concurrent use, but the child it returns depends
+// on the order of the calls; use Streams, or call Split from a single
+// goroutine, when the assignment of streams to workers must be deterministic.
+type Splitter struct {
+	mu   sync.Mutex
+	next Xoshiro256
+}
+
+// endregion struct
+
+// region variable
+
+// Jump polynomials from the reference implementation of xoshiro256**.
+var (
+	xoshiroJump     = [4]uint64{0x180ec6d33cfd0aba, 0xd5a61266f0c9392c, 0xa9582618e03fc9aa, 0x39abdc4529b1661c}
+	xoshiroLongJump = [4]uint64{0x76e15d3efefdcbbf, 0xc5004e441c522fb3, 0x77710069854ee241, 0x39109bb02acbe635}
+)
+
+// endregion variable
+
+// region function
+
+// NewSplitter returns a Splitter whose first child starts at the beginning
+// of the xoshiro256** stream for seed (see NewXoshiro256).
+func NewSplitter(seed int64) *Splitter {
+	s := new(Splitter)
+	s.next.Seed(seed)
+	return s
+}
+
+// Streams returns n independent generators derived from seed, for example
+// one per worker goroutine. Stream i is the same for a given seed no matter
+// how large n is. Each generator is not safe for concurrent use.
+func Streams(seed int64, n int) []*Rand {
+	s := NewSplitter(seed)
+	rs := make([]*Rand, n)
+	for i := range rs {
+		rs[i] = s.Split()
+	}
+	return rs
+}
+
+// endregion function
+
+// region method
+
+// jump advances x by the number of steps encoded in the polynomial poly.
+func (x *Xoshiro256) jump(poly *[4]uint64) {
+	var s [4]uint64
+	for _, p := range poly {
+		for b := 0; b < 64; b++ {
+			if p&(1<<uint(b)) != 0 {
+				s[0] ^= x.s[0]
+				s[1] ^= x.s[1]
+				s[2] ^= x.s[2]
+				s[3] ^= x.s[3]
+			}
+			x.Uint64()
+		}
+	}
+	x.s = s
+}
+
+// Jump advances the generator by 2^128 values. It can be used to generate
+// 2^128 non-overlapping subsequences for parallel computations.
+func (x *Xoshiro256) Jump() { x.jump(&xoshiroJump) }
+
+// LongJump advances the generator by 2^192 values. It can be used to
+// generate 2^64 starting points, from each of which Jump will generate
+// 2^64 non-overlapping subsequences.
+func (x *Xoshiro256) LongJump() { x.jump(&xoshiroLongJump) }
+
+// Split returns a new child generator. The first child starts at the root
+// of the stream and every later child 2^128 values after the previous one.
+func (s *Splitter) Split() *Rand {
+	return New(s.SplitSource())
+}
+
+// SplitSource is like Split but returns the bare source, which can be
+// marshaled or wrapped by the caller.
+func (s *Splitter) SplitSource() *Xoshiro256 {
+	s.mu.Lock()
+	child := s.next
+	s.next.Jump()
+	s.mu.Unlock()
+	return &child
+}
+
+// endregion method
@@ -0,0 +1,125 @@
+package rand_fromgo
+
+import (
+	"sync"
+	"testing"
+)
+
+// Test_Jump 是測試 jump 以多項式 x^k 前進時與呼叫 k 次 Uint64 相同，以及 Jump、LongJump 的結果與前進前不同。
+func Test_Jump(t *testing.T) {
+	for _, k := range []int{0, 1, 5, 63, 64, 200} {
+		var poly [4]uint64
+		poly[k/64] = 1 << uint(k%64)
+		a, b := NewXoshiro256(3), NewXoshiro256(3)
+		a.jump(&poly)
+		for i := 0; i < k; i++ {
+			b.Uint64()
+		}
+		if a.s != b.s {
+			t.Errorf("Error jump by x^%d = %x, should be %x.", k, a.s, b.s)
+		}
+	}
+	// 多項式 x^3 + x^7 代表前進 3 步與前進 7 步的狀態相加（GF(2) 上），等同於兩次 jump 的組合。
+	var poly [4]uint64
+	poly[0] = 1<<3 | 1<<7
+	a, b, c := NewXoshiro256(4), NewXoshiro256(4), NewXoshiro256(4)
+	a.jump(&poly)
+	for i := 0; i < 3; i++ {
+		b.Uint64()
+	}
+	for i := 0; i < 7; i++ {
+		c.Uint64()
+	}
+	for i := range b.s {
+		if a.s[i] != b.s[i]^c.s[i] {
+			t.Fatalf("Error jump is not linear over GF(2).")
+		}
+	}
+
+	x, y := NewXoshiro256(5), NewXoshiro256(5)
+	x.Jump()
+	y.LongJump()
+	if x.s == NewXoshiro256(5).s || y.s == x.s {
+		t.Errorf("Error Jump or LongJump did not move the state.")
+	}
+}
+
+// Test_Streams 是測試子數列與產生的順序無關、前幾個值互不重複，且可以在多個 goroutine 中同時使用。
+func Test_Streams(t *testing.T) {
+	few, many := Streams(9, 2), Streams(9, 8)
+	for i := range few {
+		if a, b := few[i].Uint64(), many[i].Uint64(); a != b {
+			t.Errorf("Error stream %d depends on the number of streams: %d and %d.", i, a, b)
+		}
+	}
+	// 第一個子數列與根種子的 xoshiro256** 數列相同。
+	root := NewXoshiro256(9)
+	root.Uint64()
+	if a, b := root.Uint64(), many[0].Uint64(); a != b {
+		t.Errorf("Error first stream = %d, should be %d.", b, a)
+	}
+
+	// 每一個 goroutine 使用自己的子數列，不需要鎖；結果只由種子與編號決定。
+	run := func() (out [][]uint64) {
+		streams := Streams(11, 8)
+		out = make([][]uint64, len(streams))
+		var wg sync.WaitGroup
+		for i, r := range streams {
+			wg.Add(1)
+			go func(i int, r *Rand) {
+				defer wg.Done()
+				for n := 0; n < 1000; n++ {
+					out[i] = append(out[i], r.Uint64())
+				}
+			}(i, r)
+		}
+		wg.Wait()
+		return out
+	}
+	first, second := run(), run()
+	seen := make(map[uint64]int)
+	for i := range first {
+		for n, v := range first[i] {
+			if second[i][n] != v {
+				t.Fatalf("Error stream %d value %d differs between runs.", i, n)
+			}
+			if j, ok := seen[v]; ok && j != i {
+				t.Errorf("Error streams %d and %d share the value %d.", j, i, v)
+			}
+			seen[v] = i
+		}
+	}
+}
+
+// Test_SplitterConcurrent 是測試多個 goroutine 同時呼叫 Split 時，每一個子數列都不相同。
+func Test_SplitterConcurrent(t *testing.T) {
+	s := NewSplitter(13)
+	firsts := make([]uint64, 64)
+	var wg sync.WaitGroup
+	for i := range firsts {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			firsts[i] = s.Split().Uint64()
+		}(i)
+	}
+	wg.Wait()
+	want := make(map[uint64]bool)
+	for _, r := range Streams(13, len(firsts)) {
+		want[r.Uint64()] = true
+	}
+	for _, v := range firsts {
+		if !want[v] {
+			t.Fatalf("Error concurrent Split returned an unexpected stream.")
+		}
+		delete(want, v)
+	}
+}
+
+// Benchmark_Split 是測試產生一個子數列（一次 Jump）的效能。
+func Benchmark_Split(b *testing.B) {
+	s := NewSplitter(1)
+	for i := 0; i < b.N; i++ {
+		s.Split()
+	}
+}
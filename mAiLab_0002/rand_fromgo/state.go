@@ -0,0 +1,197 @@
+package rand_fromgo
+
+import (
+	"encoding"
+	"encoding/binary"
+	"errors"
+)
+
+/*
+ * Binary encodings of generator state, so a stream can be checkpointed and
+ * resumed bit-exactly. Every encoding starts with a short type prefix, so
+ * restoring the state of one generator into another fails instead of
+ * silently producing a different stream. All integers are big-endian,
+ * except the ChaCha8 seed words, which use the same layout as Go's
+ * math/rand/v2; the PCG and ChaCha8 encodings are interchangeable with it.
+ */
+
+// region variable
+
+var (
+	errUnmarshalRng        = errors.New("Error: invalid rngSource encoding")
+	errUnmarshalSplitMix64 = errors.New("Error: invalid SplitMix64 encoding")
+	errUnmarshalXoshiro256 = errors.New("Error: invalid Xoshiro256 encoding")
+	errUnmarshalPCG        = errors.New("Error: invalid PCG encoding")
+	errUnmarshalChaCha8    = errors.New("Error: invalid ChaCha8 encoding")
+	errUnmarshalRand       = errors.New("Error: invalid Rand encoding")
+)
+
+// endregion variable
+
+// region function
+
+// hasPrefix reports whether data is prefix followed by exactly n bytes.
+func hasPrefix(data []byte, prefix string, n int) bool {
+	return len(data) == len(prefix)+n && string(data[:len(prefix)]) == prefix
+}
+
+// endregion function
+
+// region method
+
+// MarshalBinary implements encoding.BinaryMarshaler.
+func (rng *rngSource) MarshalBinary() ([]byte, error) {
+	b := make([]byte, 0, 4+2*4+_LEN*8)
+	b = append(b, "rng:"...)
+	b = binary.BigEndian.AppendUint32(b, uint32(rng.tap))
+	b = binary.BigEndian.AppendUint32(b, uint32(rng.feed))
+	for _, v := range rng.vec {
+		b = binary.BigEndian.AppendUint64(b, uint64(v))
+	}
+	return b, nil
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler.
+func (rng *rngSource) UnmarshalBinary(data []byte) error {
+	if !hasPrefix(data, "rng:", 2*4+_LEN*8) {
+		return errUnmarshalRng
+	}
+	data = data[4:]
+	tap, feed := binary.BigEndian.Uint32(data), binary.BigEndian.Uint32(data[4:])
+	if tap >= _LEN || feed >= _LEN {
+		return errUnmarshalRng
+	}
+	rng.tap, rng.feed = int(tap), int(feed)
+	for i := range rng.vec {
+		rng.vec[i] = int64(binary.BigEndian.Uint64(data[8+8*i:]))
+	}
+	return nil
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler.
+func (s *SplitMix64) MarshalBinary() ([]byte, error) {
+	return binary.BigEndian.AppendUint64([]byte("splitmix64:"), s.state), nil
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler.
+func (s *SplitMix64) UnmarshalBinary(data []byte) error {
+	if !hasPrefix(data, "splitmix64:", 8) {
+		return errUnmarshalSplitMix64
+	}
+	s.state = binary.BigEndian.Uint64(data[11:])
+	return nil
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler.
+func (x *Xoshiro256) MarshalBinary() ([]byte, error) {
+	b := []byte("xoshiro256:")
+	for _, v := range x.s {
+		b = binary.BigEndian.AppendUint64(b, v)
+	}
+	return b, nil
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler.
+// It rejects the all-zero state, from which xoshiro256** only produces zeros.
+func (x *Xoshiro256) UnmarshalBinary(data []byte) error {
+	if !hasPrefix(data, "xoshiro256:", 4*8) {
+		return errUnmarshalXoshiro256
+	}
+	var s [4]uint64
+	for i := range s {
+		s[i] = binary.BigEndian.Uint64(data[11+8*i:])
+	}
+	if s == [4]uint64{} {
+		return errUnmarshalXoshiro256
+	}
+	x.s = s
+	return nil
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler.
+func (p *PCG) MarshalBinary() ([]byte, error) {
+	b := binary.BigEndian.AppendUint64([]byte("pcg:"), p.hi)
+	return binary.BigEndian.AppendUint64(b, p.lo), nil
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler.
+func (p *PCG) UnmarshalBinary(data []byte) error {
+	if !hasPrefix(data, "pcg:", 2*8) {
+		return errUnmarshalPCG
+	}
+	p.hi = binary.BigEndian.Uint64(data[4:])
+	p.lo = binary.BigEndian.Uint64(data[12:])
+	return nil
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler. Only the seed and the
+// number of values used since the last reseed are stored; the buffer is
+// recomputed on restore.
+func (c *ChaCha8) MarshalBinary() ([]byte, error) {
+	used := (c.c/chachaCtrInc)*chachaChunk + c.i
+	b := binary.BigEndian.AppendUint64([]byte("chacha8:"), uint64(used))
+	for _, v := range c.seed {
+		b = binary.LittleEndian.AppendUint64(b, v)
+	}
+	return b, nil
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler.
+func (c *ChaCha8) UnmarshalBinary(data []byte) error {
+	if !hasPrefix(data, "chacha8:", 5*8) {
+		return errUnmarshalChaCha8
+	}
+	used := binary.BigEndian.Uint64(data[8:])
+	if used > (chachaCtrMax/chachaCtrInc)*chachaChunk-chachaReseed {
+		return errUnmarshalChaCha8
+	}
+	for i := range c.seed {
+		c.seed[i] = binary.LittleEndian.Uint64(data[16+8*i:])
+	}
+	c.c = chachaCtrInc * (uint32(used) / chachaChunk)
+	chachaBlock(&c.seed, &c.buf, c.c)
+	c.i = uint32(used) % chachaChunk
+	c.n = chachaChunk
+	if c.c == chachaCtrMax-chachaCtrInc {
+		c.n = chachaChunk - chachaReseed
+	}
+	return nil
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler. It stores the state of
+// the underlying source, which must implement encoding.BinaryMarshaler
+// itself, together with the bytes left over from the last Read call.
+func (r *Rand) MarshalBinary() ([]byte, error) {
+	m, ok := r.src.(encoding.BinaryMarshaler)
+	if !ok {
+		return nil, errors.New("Error: source does not implement encoding.BinaryMarshaler")
+	}
+	state, err := m.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	b := append([]byte("rand:"), byte(r.readPos))
+	b = binary.BigEndian.AppendUint64(b, uint64(r.readVal))
+	return append(b, state...), nil
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler. The state is
+// restored into the existing source, which must be of the same type as the
+// one that was marshaled.
+func (r *Rand) UnmarshalBinary(data []byte) error {
+	if len(data) < 5+1+8 || string(data[:5]) != "rand:" || data[5] > 7 {
+		return errUnmarshalRand
+	}
+	u, ok := r.src.(encoding.BinaryUnmarshaler)
+	if !ok {
+		return errors.New("Error: source does not implement encoding.BinaryUnmarshaler")
+	}
+	if err := u.UnmarshalBinary(data[14:]); err != nil {
+		return err
+	}
+	r.readPos = int8(data[5])
+	r.readVal = int64(binary.BigEndian.Uint64(data[6:]))
+	return nil
+}
+
+// endregion method
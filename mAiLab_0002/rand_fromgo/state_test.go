@@ -0,0 +1,176 @@
+package rand_fromgo
+
+import (
+	"bytes"
+	"encoding"
+	randv2 "math/rand/v2"
+	"testing"
+)
+
+// stateSource 是可以保存與還原狀態的 Source64。
+type stateSource interface {
+	Source64
+	encoding.BinaryMarshaler
+	encoding.BinaryUnmarshaler
+}
+
+// Test_MarshalSources 是測試每一種來源在數列的任何位置保存狀態後，還原的來源會接續產生完全相同的數列。
+func Test_MarshalSources(t *testing.T) {
+	var tests = []struct {
+		name  string
+		src   stateSource
+		fresh func() stateSource
+	}{
+		{"rngSource", NewSource(1).(stateSource), func() stateSource { return NewSource(2).(stateSource) }},
+		{"SplitMix64", NewSplitMix64(1), func() stateSource { return NewSplitMix64(2) }},
+		{"Xoshiro256", NewXoshiro256(1), func() stateSource { return NewXoshiro256(2) }},
+		{"PCG", NewPCG(1, 2), func() stateSource { return NewPCG(3, 4) }},
+		{"ChaCha8", NewChaCha8([32]byte{1}), func() stateSource { return NewChaCha8([32]byte{2}) }},
+	}
+	for _, test := range tests {
+		// 0～700 涵蓋 rngSource 的 tap、feed 繞回，以及 ChaCha8 的每一個區塊與重新設定種子的位置。
+		for used := 0; used < 700; used++ {
+			state, err := test.src.MarshalBinary()
+			if err != nil {
+				t.Fatal(err)
+			}
+			restored := test.fresh()
+			if err = restored.UnmarshalBinary(state); err != nil {
+				t.Fatalf("Error %s unmarshal after %d values: %v", test.name, used, err)
+			}
+			// 以複本往後比較，不影響原本來源的位置。
+			want, _ := test.src.MarshalBinary()
+			probe := test.fresh()
+			probe.UnmarshalBinary(want)
+			for n := 0; n < 40; n++ {
+				if a, b := restored.Uint64(), probe.Uint64(); a != b {
+					t.Fatalf("Error %s restored after %d values differs at call %d.", test.name, used, n)
+				}
+			}
+			test.src.Uint64()
+		}
+	}
+}
+
+// Test_MarshalResume 是測試保存狀態前後的數列接起來與未中斷的數列完全相同。
+func Test_MarshalResume(t *testing.T) {
+	for _, seed := range []int64{0, 1, -5} {
+		var want []uint64
+		r := New(NewSource(seed))
+		for i := 0; i < 2000; i++ {
+			want = append(want, r.Uint64())
+		}
+		r = New(NewSource(seed))
+		for i := 0; i < 1000; i++ {
+			r.Uint64()
+		}
+		state, err := r.MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+		resumed := New(NewSource(seed + 1))
+		if err = resumed.UnmarshalBinary(state); err != nil {
+			t.Fatal(err)
+		}
+		for i := 1000; i < 2000; i++ {
+			if got := resumed.Uint64(); got != want[i] {
+				t.Fatalf("Error seed %d resumed call %d = %d, should be %d.", seed, i, got, want[i])
+			}
+		}
+	}
+}
+
+// Test_MarshalRandRead 是測試 Rand 的狀態包含上一次 Read 剩下的 bytes。
+func Test_MarshalRandRead(t *testing.T) {
+	r := New(NewPCG(5, 6))
+	r.Read(make([]byte, 3))
+	state, err := r.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := make([]byte, 20)
+	r.Read(want)
+
+	resumed := New(NewPCG(0, 0))
+	if err = resumed.UnmarshalBinary(state); err != nil {
+		t.Fatal(err)
+	}
+	got := make([]byte, 20)
+	resumed.Read(got)
+	if !bytes.Equal(got, want) {
+		t.Errorf("Error resumed Read = %v, should be %v.", got, want)
+	}
+}
+
+// Test_MarshalRandV2 是測試 PCG 與 ChaCha8 的狀態格式與 math/rand/v2 相容。
+func Test_MarshalRandV2(t *testing.T) {
+	pcg, stdPCG := NewPCG(7, 8), randv2.NewPCG(0, 0)
+	chacha, stdChaCha := NewChaCha8([32]byte{9}), randv2.NewChaCha8([32]byte{})
+	var tests = []struct {
+		name string
+		ours stateSource
+		std  interface {
+			randv2.Source
+			encoding.BinaryMarshaler
+			encoding.BinaryUnmarshaler
+		}
+	}{
+		{"PCG", pcg, stdPCG},
+		{"ChaCha8", chacha, stdChaCha},
+	}
+	for _, test := range tests {
+		for i := 0; i < 300; i++ {
+			test.ours.Uint64()
+		}
+		state, _ := test.ours.MarshalBinary()
+		if err := test.std.UnmarshalBinary(state); err != nil {
+			t.Fatalf("Error math/rand/v2 %s cannot read our state: %v", test.name, err)
+		}
+		if std, _ := test.std.MarshalBinary(); !bytes.Equal(std, state) {
+			t.Errorf("Error %s state %x, math/rand/v2 gives %x.", test.name, state, std)
+		}
+		for n := 0; n < 1000; n++ {
+			if a, b := test.ours.Uint64(), test.std.Uint64(); a != b {
+				t.Fatalf("Error %s call %d after restore = %#x, should be %#x.", test.name, n, a, b)
+			}
+		}
+	}
+}
+
+// Test_UnmarshalErrors 是測試不同型別、長度錯誤或內容不合法的狀態都會回傳錯誤，且不會改變來源。
+func Test_UnmarshalErrors(t *testing.T) {
+	pcgState, _ := NewPCG(1, 2).MarshalBinary()
+	rngState, _ := NewSource(1).(stateSource).MarshalBinary()
+	badTap := append([]byte(nil), rngState...)
+	badTap[4] = 0xFF
+	var tests = []struct {
+		name string
+		dst  encoding.BinaryUnmarshaler
+		data []byte
+	}{
+		{"PCG into SplitMix64", NewSplitMix64(1), pcgState},
+		{"truncated PCG", NewPCG(1, 2), pcgState[:len(pcgState)-1]},
+		{"rngSource tap out of range", NewSource(1).(stateSource), badTap},
+		{"zero Xoshiro256", NewXoshiro256(1), make([]byte, len("xoshiro256:")+32)},
+		{"ChaCha8 used out of range", NewChaCha8([32]byte{}), append([]byte("chacha8:\x00\x00\x00\x00\x00\x00\x01\x00"), make([]byte, 32)...)},
+		{"empty", NewSplitMix64(1), nil},
+		{"Rand with PCG state", New(NewSplitMix64(1)), pcgState},
+		{"Rand of another source", New(NewSplitMix64(1)), append([]byte("rand:\x00\x00\x00\x00\x00\x00\x00\x00\x00"), pcgState...)},
+		{"Rand bad readPos", New(NewPCG(1, 2)), append([]byte("rand:\x08\x00\x00\x00\x00\x00\x00\x00\x00"), pcgState...)},
+	}
+	for _, test := range tests {
+		m := test.dst.(encoding.BinaryMarshaler)
+		before, _ := m.MarshalBinary()
+		if err := test.dst.UnmarshalBinary(test.data); err == nil {
+			t.Errorf("Error %s should return an error.", test.name)
+		}
+		if after, _ := m.MarshalBinary(); !bytes.Equal(before, after) {
+			t.Errorf("Error %s modified the state.", test.name)
+		}
+	}
+
+	// 不能保存狀態的來源會讓 Rand 回傳錯誤。
+	if _, err := New(&lockedSource{src: NewSource(1).(*rngSource)}).MarshalBinary(); err == nil {
+		t.Errorf("Error Rand with a source that cannot be marshaled should return an error.")
+	}
+}
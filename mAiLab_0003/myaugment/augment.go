@@ -0,0 +1,108 @@
+package myaugment
+
+import (
+	"image"
+	"math"
+
+	"github.com/oneleo/LeNetPractice/mAiLab_0002/rand_fromgo"
+)
+
+// region interface
+
+// Transform 會以亂數產生器 rnd 隨機變換一張灰階影像，回傳與輸入同大小的新影像，不會修改輸入。
+// 本套件的 Transform 都不會修改自身，只要每個 goroutine 使用各自的 rnd 即可同時使用。
+type Transform interface {
+	Apply(img *image.Gray, rnd *rand_fromgo.Rand) *image.Gray
+}
+
+// endregion interface
+
+// region struct
+
+// Pipeline 會依序套用多個 Transform，所有變換共用同一個以 rand_fromgo.Source 建立的亂數產生器，
+// 因此相同的 Source 種子與相同的呼叫順序會得到完全相同的擴增結果。
+type Pipeline struct {
+	Transforms []Transform
+	Rand       *rand_fromgo.Rand
+}
+
+// Random 會以機率 P 套用 Transform，否則回傳輸入的副本。
+type Random struct {
+	P float64
+	Transform
+}
+
+// endregion struct
+
+// region function
+
+// NewPipeline 函數會建立以 src 為亂數來源、依序套用 transforms 的擴增流程。
+func NewPipeline(src rand_fromgo.Source, transforms ...Transform) *Pipeline {
+	return &Pipeline{Transforms: transforms, Rand: rand_fromgo.New(src)}
+}
+
+// Default 函數會建立常用於 MNIST 的擴增流程：小幅度的仿射變換、彈性形變，以及機率 0.5 的隨機遮蔽。
+func Default(seed int64) *Pipeline {
+	return NewPipeline(rand_fromgo.NewSource(seed),
+		&Affine{MaxRotate: 15, MinScale: 0.9, MaxScale: 1.1, MaxShear: 10, MaxShift: 2},
+		NewElastic(34, 4),
+		Random{P: 0.5, Transform: NewErasing()},
+	)
+}
+
+// clone 函數會複製一張灰階影像，新影像的原點為 (0, 0)。
+func clone(img *image.Gray) *image.Gray {
+	b := img.Bounds()
+	dst := image.NewGray(image.Rect(0, 0, b.Dx(), b.Dy()))
+	for y := 0; y < b.Dy(); y++ {
+		copy(dst.Pix[y*dst.Stride:(y+1)*dst.Stride], img.Pix[img.PixOffset(b.Min.X, b.Min.Y+y):])
+	}
+	return dst
+}
+
+// bilinear 函數會以雙線性內插取得 img 在 (x, y) 的像素值，x、y 為相對於影像左上角的座標，影像外的像素視為 0。
+func bilinear(img *image.Gray, x, y float64) float64 {
+	b := img.Bounds()
+	x0, y0 := int(math.Floor(x)), int(math.Floor(y))
+	fx, fy := x-float64(x0), y-float64(y0)
+	at := func(i, j int) float64 {
+		if i < 0 || j < 0 || i >= b.Dx() || j >= b.Dy() {
+			return 0
+		}
+		return float64(img.Pix[img.PixOffset(b.Min.X+i, b.Min.Y+j)])
+	}
+	return (1-fy)*((1-fx)*at(x0, y0)+fx*at(x0+1, y0)) + fy*((1-fx)*at(x0, y0+1)+fx*at(x0+1, y0+1))
+}
+
+// toUint8 函數會將像素值四捨五入並限制在 [0, 255]。
+func toUint8(v float64) uint8 {
+	return uint8(math.Max(0, math.Min(255, math.Round(v))))
+}
+
+// endregion function
+
+// region method
+
+// Apply 會依序套用所有變換。
+func (p *Pipeline) Apply(img *image.Gray) *image.Gray {
+	out := clone(img)
+	for _, t := range p.Transforms {
+		out = t.Apply(out, p.Rand)
+	}
+	return out
+}
+
+// Augment 會以值傳遞的方式套用所有變換，與 mymnist 讀出的 []image.Gray 搭配使用。
+func (p *Pipeline) Augment(img image.Gray) image.Gray {
+	return *p.Apply(&img)
+}
+
+// Apply 會以機率 P 套用 Transform。
+func (r Random) Apply(img *image.Gray, rnd *rand_fromgo.Rand) *image.Gray {
+	if rnd.Float64() < r.P {
+		return r.Transform.Apply(img, rnd)
+	}
+	return clone(img)
+}
+
+// endregion method
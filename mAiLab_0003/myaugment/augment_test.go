@@ -0,0 +1,206 @@
+// How to use:
+//
+// 1. Testing
+// (1) > cd "%GOPATH%\src\github.com\oneleo\LeNetPractice\mAiLab_0003\myaugment"
+// or (1) $ cd "$GOPATH/src/github.com/oneleo/LeNetPractice/mAiLab_0003/myaugment"
+// (2) $> go test -v
+
+package myaugment
+
+import (
+	"bytes"
+	"image"
+	"math"
+	"sync"
+	"testing"
+
+	"github.com/oneleo/LeNetPractice/mAiLab_0002/rand_fromgo"
+)
+
+// digit 會回傳一張 28x28、中間有一條直線的測試影像。
+func digit() *image.Gray {
+	img := image.NewGray(image.Rect(0, 0, 28, 28))
+	for y := 6; y < 22; y++ {
+		img.Pix[y*28+13] = 255
+		img.Pix[y*28+14] = 200
+	}
+	return img
+}
+
+// sum 會回傳影像所有像素值的總和。
+func sum(img *image.Gray) (s int) {
+	for _, p := range img.Pix {
+		s += int(p)
+	}
+	return s
+}
+
+// Test_Identity 是測試參數為 0 的仿射變換與強度為 0 的彈性形變不會改變影像，且不會修改輸入。
+func Test_Identity(t *testing.T) {
+	rnd := rand_fromgo.New(rand_fromgo.NewSource(1))
+	src := digit()
+	before := append([]byte(nil), src.Pix...)
+	var tests = []Transform{
+		&Affine{},
+		&Affine{MinScale: 1, MaxScale: 1},
+		NewElastic(0, 4),
+		&Noise{},
+		Random{P: 0, Transform: NewErasing()},
+	}
+	for i, tr := range tests {
+		out := tr.Apply(src, rnd)
+		if !bytes.Equal(out.Pix, src.Pix) {
+			t.Errorf("Error transform %d changed the image.", i)
+		}
+		if out == src {
+			t.Errorf("Error transform %d returned the input instead of a copy.", i)
+		}
+	}
+	if !bytes.Equal(src.Pix, before) {
+		t.Error("Error input image was modified.")
+	}
+}
+
+// Test_Affine 是測試 90 度旋轉會把直線變成橫線，以及平移會移動像素。
+func Test_Affine(t *testing.T) {
+	rnd := rand_fromgo.New(rand_fromgo.NewSource(2))
+	// 旋轉角度是隨機取樣的，因此重複套用直到出現接近 ±90 度的樣本。
+	a := &Affine{MaxRotate: 90.0001, MinScale: 1, MaxScale: 1}
+	for n := 0; n < 200; n++ {
+		out := a.Apply(digit(), rnd)
+		// 接近 ±90 度時，第 13、14 列應該有一條橫線。
+		row := 0
+		for x := 0; x < 28; x++ {
+			row += int(out.Pix[13*28+x]) + int(out.Pix[14*28+x])
+		}
+		if row > 5000 {
+			return
+		}
+	}
+	t.Error("Error no rotation close to 90 degrees produced a horizontal line.")
+}
+
+// Test_AffineShift 是測試純平移時影像總亮度不變（沒有像素被移出影像）。
+func Test_AffineShift(t *testing.T) {
+	rnd := rand_fromgo.New(rand_fromgo.NewSource(3))
+	a := &Affine{MaxShift: 2}
+	src := digit()
+	for n := 0; n < 20; n++ {
+		out := a.Apply(src, rnd)
+		if d := math.Abs(float64(sum(out)-sum(src))) / float64(sum(src)); d > 0.02 {
+			t.Errorf("Error shifted image brightness changed by %.3f.", d)
+		}
+	}
+}
+
+// Test_Reproducible 是測試相同種子的擴增流程會產生完全相同的結果，不同種子則不同。
+func Test_Reproducible(t *testing.T) {
+	run := func(seed int64) (out [][]byte) {
+		p := Default(seed)
+		p.Transforms = append(p.Transforms, &Noise{Sigma: 10, Salt: 0.01})
+		for n := 0; n < 5; n++ {
+			out = append(out, p.Augment(*digit()).Pix)
+		}
+		return out
+	}
+	a, b, c := run(7), run(7), run(8)
+	same := true
+	for n := range a {
+		if !bytes.Equal(a[n], b[n]) {
+			t.Fatalf("Error sample %d differs with the same seed.", n)
+		}
+		same = same && bytes.Equal(a[n], c[n])
+	}
+	if same {
+		t.Error("Error different seeds produced the same samples.")
+	}
+}
+
+// Test_Elastic 是測試彈性形變會改變影像但大致保留筆畫亮度。
+func Test_Elastic(t *testing.T) {
+	rnd := rand_fromgo.New(rand_fromgo.NewSource(4))
+	src := digit()
+	out := NewElastic(34, 4).Apply(src, rnd)
+	if bytes.Equal(out.Pix, src.Pix) {
+		t.Error("Error elastic distortion did not change the image.")
+	}
+	if r := float64(sum(out)) / float64(sum(src)); r < 0.5 || r > 1.5 {
+		t.Errorf("Error elastic distortion changed brightness by a factor of %.2f.", r)
+	}
+	if k := gaussianKernel(4); len(k) != 25 || math.Abs(k[0]-k[24]) > 1e-15 {
+		t.Errorf("Error gaussian kernel has %d taps.", len(k))
+	}
+}
+
+// Test_ElasticShared 是測試修改 Sigma 後使用新的高斯核，以及多個 goroutine 同時使用同一個 Elastic 的結果與依序執行相同。
+func Test_ElasticShared(t *testing.T) {
+	changed := NewElastic(34, 4)
+	// 3.9 與 4 的高斯核長度相同，只比較長度無法發現 Sigma 已經改變。
+	changed.Sigma = 3.9
+	a := changed.Apply(digit(), rand_fromgo.New(rand_fromgo.NewSource(5)))
+	b := NewElastic(34, 3.9).Apply(digit(), rand_fromgo.New(rand_fromgo.NewSource(5)))
+	if !bytes.Equal(a.Pix, b.Pix) {
+		t.Error("Error elastic distortion should use the kernel of the current Sigma.")
+	}
+
+	want := make([][]byte, 8)
+	for n := range want {
+		want[n] = NewElastic(34, 4).Apply(digit(), rand_fromgo.New(rand_fromgo.NewSource(int64(n)))).Pix
+	}
+	e := NewElastic(34, 4)
+	got := make([][]byte, len(want))
+	var wg sync.WaitGroup
+	for n := range got {
+		wg.Add(1)
+		go func(n int) {
+			defer wg.Done()
+			got[n] = e.Apply(digit(), rand_fromgo.New(rand_fromgo.NewSource(int64(n)))).Pix
+		}(n)
+	}
+	wg.Wait()
+	for n := range want {
+		if !bytes.Equal(got[n], want[n]) {
+			t.Fatalf("Error concurrent elastic distortion %d differs from the sequential one.", n)
+		}
+	}
+}
+
+// Test_Erasing 是測試遮蔽的面積落在設定的範圍內。
+func Test_Erasing(t *testing.T) {
+	rnd := rand_fromgo.New(rand_fromgo.NewSource(5))
+	e := NewErasing()
+	e.Value = 128
+	for n := 0; n < 50; n++ {
+		out := e.Apply(image.NewGray(image.Rect(0, 0, 28, 28)), rnd)
+		area := 0
+		for _, p := range out.Pix {
+			if p == 128 {
+				area++
+			}
+		}
+		// 四捨五入與長寬比限制會讓面積稍微偏離，因此放寬上下限。
+		if f := float64(area) / (28 * 28); f < 0.01 || f > 0.3 {
+			t.Errorf("Error erased area fraction %.3f out of range.", f)
+		}
+	}
+}
+
+// Test_Noise 是測試高斯雜訊的平均值與標準差。
+func Test_Noise(t *testing.T) {
+	rnd := rand_fromgo.New(rand_fromgo.NewSource(6))
+	img := image.NewGray(image.Rect(0, 0, 100, 100))
+	for i := range img.Pix {
+		img.Pix[i] = 128
+	}
+	out := (&Noise{Sigma: 10}).Apply(img, rnd)
+	mean, sq := 0.0, 0.0
+	for _, p := range out.Pix {
+		mean += float64(p)
+		sq += float64(p) * float64(p)
+	}
+	mean /= float64(len(out.Pix))
+	std := math.Sqrt(sq/float64(len(out.Pix)) - mean*mean)
+	if math.Abs(mean-128) > 0.5 || math.Abs(std-10) > 0.5 {
+		t.Errorf("Error noise mean %.2f std %.2f, should be about 128 and 10.", mean, std)
+	}
+}
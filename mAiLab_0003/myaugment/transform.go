@@ -0,0 +1,222 @@
+package myaugment
+
+import (
+	"image"
+	"math"
+
+	"github.com/oneleo/LeNetPractice/mAiLab_0002/rand_fromgo"
+)
+
+// region struct
+
+// Affine 是以影像中心為原點的隨機仿射變換，每次套用時各參數在範圍內均勻取樣。
+// 欄位為 0 時不做該項變換；MinScale、MaxScale 皆為 0 時不縮放。
+type Affine struct {
+	// MaxRotate 為最大旋轉角度（度），實際角度介於 [-MaxRotate, MaxRotate)。
+	MaxRotate float64
+	// MinScale、MaxScale 為縮放倍率的範圍。
+	MinScale float64
+	MaxScale float64
+	// MaxShear 為最大水平錯切角度（度）。
+	MaxShear float64
+	// MaxShift 為水平與垂直方向的最大平移像素。
+	MaxShift float64
+}
+
+// Elastic 是 Simard 等人（2003）提出的彈性形變：每一個像素的位移先取 [-1, 1) 的均勻亂數，
+// 再以標準差 Sigma 的高斯核平滑並乘上強度 Alpha。Apply 不會修改 Elastic，可在多個 goroutine 中同時使用。
+type Elastic struct {
+	Alpha float64
+	Sigma float64
+	// kernel 為 NewElastic 以 kernelSigma 預先計算的一維高斯核，Sigma 被修改後 Apply 會改用臨時計算的高斯核。
+	kernel      []float64
+	kernelSigma float64
+}
+
+// Erasing 是隨機遮蔽（Zhong 等人，2017）：以面積比例與長寬比隨機選取一個矩形，並填入 Value；
+// RandomFill 為 true 時改填入均勻亂數像素。長寬比的範圍必須大於 0，建議以 NewErasing 建立後再調整。
+type Erasing struct {
+	MinArea    float64
+	MaxArea    float64
+	MinAspect  float64
+	MaxAspect  float64
+	Value      uint8
+	RandomFill bool
+}
+
+// Noise 會對每一個像素加上標準差為 Sigma（以 0～255 的像素值為單位）的高斯雜訊；
+// Salt 大於 0 時，另有 Salt 的機率將像素直接設為 0 或 255（椒鹽雜訊）。
+type Noise struct {
+	Sigma float64
+	Salt  float64
+}
+
+// endregion struct
+
+// region function
+
+// NewElastic 函數會建立強度為 alpha、平滑程度為 sigma 的彈性形變，論文在 MNIST 上使用 alpha = 34、sigma = 4。
+func NewElastic(alpha, sigma float64) *Elastic {
+	return &Elastic{Alpha: alpha, Sigma: sigma, kernel: gaussianKernel(sigma), kernelSigma: sigma}
+}
+
+// NewErasing 函數會建立遮蔽面積為影像 2%～20%、長寬比 0.3～3.3、填入 0 的隨機遮蔽。
+func NewErasing() *Erasing {
+	return &Erasing{MinArea: 0.02, MaxArea: 0.2, MinAspect: 0.3, MaxAspect: 3.3}
+}
+
+// gaussianKernel 函數會回傳半徑為 ceil(3*sigma)、總和為 1 的一維高斯核。
+func gaussianKernel(sigma float64) (k []float64) {
+	r := int(math.Ceil(3 * sigma))
+	k = make([]float64, 2*r+1)
+	sum := 0.0
+	for i := range k {
+		x := float64(i - r)
+		k[i] = math.Exp(-x * x / (2 * sigma * sigma))
+		sum += k[i]
+	}
+	for i := range k {
+		k[i] /= sum
+	}
+	return k
+}
+
+// blur 函數會以一維核 k 對 w*h 的場 f 分別做水平與垂直卷積（可分離的二維高斯平滑），邊界外視為 0。
+func blur(f []float64, w, h int, k []float64) []float64 {
+	r := len(k) / 2
+	tmp := make([]float64, len(f))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			s := 0.0
+			for i, v := range k {
+				if xx := x + i - r; xx >= 0 && xx < w {
+					s += v * f[y*w+xx]
+				}
+			}
+			tmp[y*w+x] = s
+		}
+	}
+	out := make([]float64, len(f))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			s := 0.0
+			for i, v := range k {
+				if yy := y + i - r; yy >= 0 && yy < h {
+					s += v * tmp[yy*w+x]
+				}
+			}
+			out[y*w+x] = s
+		}
+	}
+	return out
+}
+
+// endregion function
+
+// region method
+
+// Apply 會對 img 做一次隨機仿射變換，以反向映射與雙線性內插取樣，影像外的像素補 0。
+func (a *Affine) Apply(img *image.Gray, rnd *rand_fromgo.Rand) *image.Gray {
+	theta := rnd.Uniform(-a.MaxRotate, a.MaxRotate) * math.Pi / 180
+	scale := 1.0
+	if a.MinScale > 0 || a.MaxScale > 0 {
+		scale = rnd.Uniform(a.MinScale, a.MaxScale)
+	}
+	shear := math.Tan(rnd.Uniform(-a.MaxShear, a.MaxShear) * math.Pi / 180)
+	tx, ty := rnd.Uniform(-a.MaxShift, a.MaxShift), rnd.Uniform(-a.MaxShift, a.MaxShift)
+
+	// 正向變換 M = R(theta) * Sh(shear) * S(scale)，M = [[m00, m01], [m10, m11]]。
+	cos, sin := math.Cos(theta), math.Sin(theta)
+	m00, m01 := scale*cos, scale*(cos*shear-sin)
+	m10, m11 := scale*sin, scale*(sin*shear+cos)
+	det := m00*m11 - m01*m10
+	if det == 0 {
+		return image.NewGray(image.Rect(0, 0, img.Bounds().Dx(), img.Bounds().Dy()))
+	}
+
+	b := img.Bounds()
+	dst := image.NewGray(image.Rect(0, 0, b.Dx(), b.Dy()))
+	cx, cy := float64(b.Dx()-1)/2, float64(b.Dy()-1)/2
+	for y := 0; y < b.Dy(); y++ {
+		for x := 0; x < b.Dx(); x++ {
+			// 以 M 的反矩陣將輸出座標映射回輸入座標。
+			u, v := float64(x)-cx-tx, float64(y)-cy-ty
+			sx := (m11*u - m01*v) / det
+			sy := (-m10*u + m00*v) / det
+			dst.Pix[y*dst.Stride+x] = toUint8(bilinear(img, sx+cx, sy+cy))
+		}
+	}
+	return dst
+}
+
+// Apply 會對 img 做一次隨機彈性形變。
+func (e *Elastic) Apply(img *image.Gray, rnd *rand_fromgo.Rand) *image.Gray {
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+	k := e.kernel
+	if k == nil || e.kernelSigma != e.Sigma {
+		k = gaussianKernel(e.Sigma)
+	}
+	dx, dy := make([]float64, w*h), make([]float64, w*h)
+	for i := range dx {
+		dx[i] = rnd.Uniform(-1, 1)
+		dy[i] = rnd.Uniform(-1, 1)
+	}
+	dx, dy = blur(dx, w, h, k), blur(dy, w, h, k)
+
+	dst := image.NewGray(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			i := y*w + x
+			dst.Pix[y*dst.Stride+x] = toUint8(bilinear(img, float64(x)+e.Alpha*dx[i], float64(y)+e.Alpha*dy[i]))
+		}
+	}
+	return dst
+}
+
+// Apply 會在 img 上隨機遮蔽一個矩形。
+func (e *Erasing) Apply(img *image.Gray, rnd *rand_fromgo.Rand) *image.Gray {
+	dst := clone(img)
+	w, h := dst.Bounds().Dx(), dst.Bounds().Dy()
+	area := rnd.Uniform(e.MinArea, e.MaxArea) * float64(w*h)
+	// 長寬比在對數尺度上均勻取樣，使 r 與 1/r 出現的機會相同。
+	aspect := math.Exp(rnd.Uniform(math.Log(e.MinAspect), math.Log(e.MaxAspect)))
+	ew := int(math.Min(float64(w), math.Round(math.Sqrt(area*aspect))))
+	eh := int(math.Min(float64(h), math.Round(math.Sqrt(area/aspect))))
+	if ew <= 0 || eh <= 0 {
+		return dst
+	}
+	x0 := rnd.Intn(w - ew + 1)
+	y0 := rnd.Intn(h - eh + 1)
+	for y := y0; y < y0+eh; y++ {
+		for x := x0; x < x0+ew; x++ {
+			v := e.Value
+			if e.RandomFill {
+				v = uint8(rnd.Intn(256))
+			}
+			dst.Pix[y*dst.Stride+x] = v
+		}
+	}
+	return dst
+}
+
+// Apply 會對 img 加上高斯雜訊與椒鹽雜訊。
+func (n *Noise) Apply(img *image.Gray, rnd *rand_fromgo.Rand) *image.Gray {
+	dst := clone(img)
+	for i, p := range dst.Pix {
+		v := float64(p)
+		if n.Sigma > 0 {
+			v += rnd.Normal(0, n.Sigma)
+		}
+		if n.Salt > 0 && rnd.Float64() < n.Salt {
+			v = 0
+			if rnd.Float64() < 0.5 {
+				v = 255
+			}
+		}
+		dst.Pix[i] = toUint8(v)
+	}
+	return dst
+}
+
+// endregion method
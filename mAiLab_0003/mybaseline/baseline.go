@@ -0,0 +1,302 @@
+package mybaseline
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"math"
+	"runtime"
+	"sort"
+	"sync"
+
+	"github.com/oneleo/LeNetPractice/mAiLab_0003/mymnist"
+)
+
+// region interface
+
+// Classifier 是可以預測一張影像 label 的分類器，Predict 必須可以在多個 goroutine 中同時呼叫。
+// Size 為分類器接受的影像像素數，像素數不同的影像 Predict 會回傳錯誤。
+type Classifier interface {
+	Size() int
+	Predict(img *image.Gray) (label byte, err error)
+}
+
+// endregion interface
+
+// region type
+
+// Distance 會回傳兩個等長向量之間的距離，越小代表越相似。
+type Distance func(a, b []float64) float64
+
+// endregion type
+
+// region struct
+
+// NearestCentroid 以每一個 label 的平均影像做為該類別的代表，預測時回傳距離最近的平均影像的 label。
+type NearestCentroid struct {
+	// Centroids[l] 為 label l 的平均影像向量，訓練資料中沒有出現的 label 為 nil。
+	Centroids [][]float64
+	Distance  Distance
+	size      int
+}
+
+// KNN 是 k 個最近鄰居分類器，預測時以距離最近的 K 筆訓練資料投票，票數相同時選擇距離總和較小的 label。
+type KNN struct {
+	K        int
+	Distance Distance
+	vectors  [][]float64
+	labels   []byte
+	size     int
+}
+
+// neighbor 是一筆候選的鄰居。
+type neighbor struct {
+	dist  float64
+	label byte
+}
+
+// endregion struct
+
+// region function
+
+// Vector 函數會將影像的像素值由 0～255 縮放至 [0, 1] 並攤平成向量。
+func Vector(img *image.Gray) (v []float64) {
+	b := img.Bounds()
+	v = make([]float64, 0, b.Dx()*b.Dy())
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			v = append(v, float64(img.Pix[img.PixOffset(x, y)])/255)
+		}
+	}
+	return v
+}
+
+// L2 函數會回傳歐氏距離的平方；對最近鄰居的排序而言與歐氏距離相同，但不需要開根號。
+func L2(a, b []float64) (d float64) {
+	for i := range a {
+		diff := a[i] - b[i]
+		d += diff * diff
+	}
+	return d
+}
+
+// L1 函數會回傳曼哈頓距離。
+func L1(a, b []float64) (d float64) {
+	for i := range a {
+		d += math.Abs(a[i] - b[i])
+	}
+	return d
+}
+
+// Cosine 函數會回傳餘弦距離 1 - cos(a, b)；任一向量為 0 向量時回傳 1。
+func Cosine(a, b []float64) float64 {
+	dot, na, nb := 0.0, 0.0, 0.0
+	for i := range a {
+		dot += a[i] * b[i]
+		na += a[i] * a[i]
+		nb += b[i] * b[i]
+	}
+	if na == 0 || nb == 0 {
+		return 1
+	}
+	return 1 - dot/math.Sqrt(na*nb)
+}
+
+// DistanceByName 函數會回傳名稱為 "l2"、"l1" 或 "cosine" 的距離函數。
+func DistanceByName(name string) (d Distance, err error) {
+	switch name {
+	case "l2":
+		return L2, nil
+	case "l1":
+		return L1, nil
+	case "cosine":
+		return Cosine, nil
+	}
+	return nil, fmt.Errorf("Error: unknown distance %q", name)
+}
+
+// NewNearestCentroid 函數會以 train 計算每一個 label 的平均影像。
+func NewNearestCentroid(train *mymnist.Dataset, dist Distance) (c *NearestCentroid, err error) {
+	if train.Len() == 0 {
+		return nil, errors.New("Error: training set is empty")
+	}
+	c = &NearestCentroid{Centroids: make([][]float64, 256), Distance: dist}
+	counts := make([]int, 256)
+	size := -1
+	for i := range train.Labels {
+		v := Vector(&train.Images[i])
+		if size >= 0 && len(v) != size {
+			return nil, errors.New("Error: images in the training set have different sizes")
+		}
+		size = len(v)
+		l := train.Labels[i]
+		if c.Centroids[l] == nil {
+			c.Centroids[l] = make([]float64, size)
+		}
+		for k, p := range v {
+			c.Centroids[l][k] += p
+		}
+		counts[l]++
+	}
+	last := 0
+	for l, n := range counts {
+		if n == 0 {
+			continue
+		}
+		for k := range c.Centroids[l] {
+			c.Centroids[l][k] /= float64(n)
+		}
+		last = l
+	}
+	c.Centroids = c.Centroids[:last+1]
+	c.size = size
+	return c, nil
+}
+
+// NewKNN 函數會建立以 train 為鄰居、投票數為 k 的 k-NN 分類器。
+func NewKNN(train *mymnist.Dataset, k int, dist Distance) (c *KNN, err error) {
+	if k < 1 || k > train.Len() {
+		return nil, fmt.Errorf("Error: k = %d out of range [1, %d]", k, train.Len())
+	}
+	c = &KNN{K: k, Distance: dist, vectors: make([][]float64, train.Len()), labels: train.Labels}
+	for i := range train.Images {
+		c.vectors[i] = Vector(&train.Images[i])
+		if len(c.vectors[i]) != len(c.vectors[0]) {
+			return nil, errors.New("Error: images in the training set have different sizes")
+		}
+	}
+	c.size = len(c.vectors[0])
+	return c, nil
+}
+
+// PredictAll 函數會以 workers 個 goroutine 預測 ds 的每一張影像，workers 小於 1 時使用 runtime.NumCPU()。
+// 開始預測前會先確認每一張影像的像素數都等於 c.Size()，否則回傳錯誤。
+func PredictAll(c Classifier, ds *mymnist.Dataset, workers int) (preds []byte, err error) {
+	for i := range ds.Images {
+		if err = checkSize(&ds.Images[i], c.Size()); err != nil {
+			return nil, fmt.Errorf("Error: image %d: %v", i, err)
+		}
+	}
+	if workers < 1 {
+		workers = runtime.NumCPU()
+	}
+	preds = make([]byte, ds.Len())
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		// 每一個 goroutine 負責索引 w、w+workers、w+2*workers…的影像，彼此寫入不同的位置。
+		go func(w int) {
+			defer wg.Done()
+			for i := w; i < ds.Len(); i += workers {
+				// 大小已經檢查過，Predict 不會回傳錯誤。
+				preds[i], _ = c.Predict(&ds.Images[i])
+			}
+		}(w)
+	}
+	wg.Wait()
+	return preds, nil
+}
+
+// Accuracy 函數會回傳 c 在 ds 上的正確率，以 workers 個 goroutine 平行預測；ds 為空時回傳 NaN。
+func Accuracy(c Classifier, ds *mymnist.Dataset, workers int) (acc float64, err error) {
+	if ds.Len() == 0 {
+		return math.NaN(), nil
+	}
+	preds, err := PredictAll(c, ds, workers)
+	if err != nil {
+		return 0, err
+	}
+	correct := 0
+	for i, p := range preds {
+		if p == ds.Labels[i] {
+			correct++
+		}
+	}
+	return float64(correct) / float64(ds.Len()), nil
+}
+
+// checkSize 函數會確認 img 的像素數為 size。
+func checkSize(img *image.Gray, size int) error {
+	if b := img.Bounds(); b.Dx()*b.Dy() != size {
+		return fmt.Errorf("Error: image is %dx%d, should have %d pixels", b.Dx(), b.Dy(), size)
+	}
+	return nil
+}
+
+// endregion function
+
+// region method
+
+// Size 會回傳訓練影像的像素數。
+func (c *NearestCentroid) Size() int { return c.size }
+
+// Predict 會回傳與 img 距離最近的平均影像的 label，img 的像素數與訓練影像不同時回傳錯誤。
+func (c *NearestCentroid) Predict(img *image.Gray) (label byte, err error) {
+	if err = checkSize(img, c.size); err != nil {
+		return 0, err
+	}
+	v := Vector(img)
+	best := math.Inf(1)
+	for l, centroid := range c.Centroids {
+		if centroid == nil {
+			continue
+		}
+		if d := c.Distance(v, centroid); d < best {
+			best, label = d, byte(l)
+		}
+	}
+	return label, nil
+}
+
+// Size 會回傳訓練影像的像素數。
+func (c *KNN) Size() int { return c.size }
+
+// Predict 會以距離 img 最近的 K 筆訓練資料投票決定 label，img 的像素數與訓練影像不同時回傳錯誤。
+func (c *KNN) Predict(img *image.Gray) (label byte, err error) {
+	if err = checkSize(img, c.size); err != nil {
+		return 0, err
+	}
+	return c.vote(c.neighbors(Vector(img))), nil
+}
+
+// neighbors 會回傳與向量 v 最近的 K 筆訓練資料，依距離由小到大排列。
+func (c *KNN) neighbors(v []float64) (nn []neighbor) {
+	nn = make([]neighbor, 0, c.K+1)
+	for i, t := range c.vectors {
+		d := c.Distance(v, t)
+		if len(nn) == c.K && d >= nn[c.K-1].dist {
+			continue
+		}
+		// 以插入排序維護目前最近的 K 筆，K 很小時比完整排序快得多。
+		n := sort.Search(len(nn), func(j int) bool { return nn[j].dist > d })
+		nn = append(nn, neighbor{})
+		copy(nn[n+1:], nn[n:])
+		nn[n] = neighbor{dist: d, label: c.labels[i]}
+		if len(nn) > c.K {
+			nn = nn[:c.K]
+		}
+	}
+	return nn
+}
+
+// vote 會回傳 nn 中票數最多的 label，票數相同時選擇距離總和較小者。
+func (c *KNN) vote(nn []neighbor) (label byte) {
+	var votes [256]int
+	var dists [256]float64
+	for _, n := range nn {
+		votes[n.label]++
+		dists[n.label] += n.dist
+	}
+	best := -1
+	for l := range votes {
+		if votes[l] == 0 {
+			continue
+		}
+		if best < 0 || votes[l] > votes[best] || (votes[l] == votes[best] && dists[l] < dists[best]) {
+			best = l
+		}
+	}
+	return byte(best)
+}
+
+// endregion method
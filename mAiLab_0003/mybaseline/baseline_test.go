@@ -0,0 +1,161 @@
+// How to use:
+//
+// 1. Testing
+// (1) > cd "%GOPATH%\src\github.com\oneleo\LeNetPractice\mAiLab_0003\mybaseline"
+// or (1) $ cd "$GOPATH/src/github.com/oneleo/LeNetPractice/mAiLab_0003/mybaseline"
+// (2) $> go test -v
+
+package mybaseline
+
+import (
+	"image"
+	"math"
+	"testing"
+
+	"github.com/oneleo/LeNetPractice/mAiLab_0002/rand_fromgo"
+	"github.com/oneleo/LeNetPractice/mAiLab_0003/mymnist"
+)
+
+// patterns 會產生 n 張 8x8 的帶雜訊影像：label 0 左半部亮、label 1 右半部亮、label 2 上半部亮。
+func patterns(n int, seed int64) *mymnist.Dataset {
+	rnd := rand_fromgo.New(rand_fromgo.NewSource(seed))
+	ds := &mymnist.Dataset{}
+	for i := 0; i < n; i++ {
+		l := byte(i % 3)
+		img := image.NewGray(image.Rect(0, 0, 8, 8))
+		for y := 0; y < 8; y++ {
+			for x := 0; x < 8; x++ {
+				on := (l == 0 && x < 4) || (l == 1 && x >= 4) || (l == 2 && y < 4)
+				v := 60 * rnd.Float64()
+				if on {
+					v += 180
+				}
+				img.Pix[y*8+x] = uint8(v)
+			}
+		}
+		ds.Images = append(ds.Images, *img)
+		ds.Labels = append(ds.Labels, l)
+	}
+	return ds
+}
+
+// Test_Distance 是測試三種距離函數。
+func Test_Distance(t *testing.T) {
+	a, b := []float64{1, 0, 2}, []float64{0, 0, 4}
+	var tests = []struct {
+		name string
+		want float64
+	}{
+		{"l2", 5},
+		{"l1", 3},
+		{"cosine", 1 - 8/math.Sqrt(5*16)},
+	}
+	for _, test := range tests {
+		d, err := DistanceByName(test.name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := d(a, b); math.Abs(got-test.want) > 1e-12 {
+			t.Errorf("Error %s distance = %g, should be %g.", test.name, got, test.want)
+		}
+	}
+	if Cosine(a, []float64{0, 0, 0}) != 1 {
+		t.Error("Error cosine distance to the zero vector should be 1.")
+	}
+	if _, err := DistanceByName("l3"); err == nil {
+		t.Error("Error unknown distance should return an error.")
+	}
+}
+
+// Test_Baselines 是測試最近平均影像與 k-NN 在可分離的資料上能正確分類，且平行預測的結果與單一 goroutine 相同。
+func Test_Baselines(t *testing.T) {
+	train, test := patterns(60, 1), patterns(30, 2)
+	nc, err := NewNearestCentroid(train, L2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(nc.Centroids) != 3 || math.Abs(nc.Centroids[0][0]-(180+30)/255.0) > 0.1 {
+		t.Errorf("Error centroids %d, first pixel of class 0 is %g.", len(nc.Centroids), nc.Centroids[0][0])
+	}
+	if acc, err := Accuracy(nc, test, 4); err != nil || acc != 1 {
+		t.Errorf("Error nearest centroid accuracy %g (%v), should be 1.", acc, err)
+	}
+	for _, dist := range []Distance{L2, L1, Cosine} {
+		knn, err := NewKNN(train, 5, dist)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if acc, err := Accuracy(knn, test, 0); err != nil || acc != 1 {
+			t.Errorf("Error k-NN accuracy %g (%v), should be 1.", acc, err)
+		}
+		one, err := PredictAll(knn, test, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		many, err := PredictAll(knn, test, 7)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i := range one {
+			if one[i] != many[i] {
+				t.Fatalf("Error prediction %d differs between 1 and 7 goroutines.", i)
+			}
+		}
+	}
+	if _, err = NewKNN(train, 0, L2); err == nil {
+		t.Error("Error k = 0 should return an error.")
+	}
+}
+
+// Test_Size 是測試影像大小與訓練影像不同時，Predict、PredictAll 與 Accuracy 回傳錯誤而不是 panic。
+func Test_Size(t *testing.T) {
+	train := patterns(30, 3)
+	nc, err := NewNearestCentroid(train, L2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	knn, err := NewKNN(train, 3, Cosine)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 第二張影像比訓練影像大，第三張比訓練影像小。
+	test := patterns(3, 4)
+	test.Images[1] = *image.NewGray(image.Rect(0, 0, 9, 8))
+	test.Images[2] = *image.NewGray(image.Rect(0, 0, 4, 4))
+	for _, c := range []Classifier{nc, knn} {
+		if c.Size() != 64 {
+			t.Errorf("Error size = %d, should be 64.", c.Size())
+		}
+		if _, err := c.Predict(&test.Images[0]); err != nil {
+			t.Error(err)
+		}
+		for i := 1; i < 3; i++ {
+			if _, err := c.Predict(&test.Images[i]); err == nil {
+				t.Errorf("Error image %d with a different size should return an error.", i)
+			}
+		}
+		if _, err := PredictAll(c, test, 2); err == nil {
+			t.Error("Error PredictAll with a different image size should return an error.")
+		}
+		if _, err := Accuracy(c, test, 2); err == nil {
+			t.Error("Error Accuracy with a different image size should return an error.")
+		}
+	}
+	train.Images[5] = *image.NewGray(image.Rect(0, 0, 4, 4))
+	if _, err := NewKNN(train, 3, L2); err == nil {
+		t.Error("Error training images with different sizes should return an error.")
+	}
+}
+
+// Test_Vote 是測試票數相同時選擇距離總和較小的 label。
+func Test_Vote(t *testing.T) {
+	knn := &KNN{K: 4}
+	nn := []neighbor{{0.1, 3}, {0.2, 5}, {0.3, 5}, {0.4, 3}}
+	if l := knn.vote(nn); l != 3 {
+		t.Errorf("Error vote = %d, should be 3.", l)
+	}
+	nn = append(nn[:3:3], neighbor{0.5, 5})
+	if l := knn.vote(nn); l != 5 {
+		t.Errorf("Error vote = %d, should be 5.", l)
+	}
+}
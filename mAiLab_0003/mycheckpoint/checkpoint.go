@@ -0,0 +1,443 @@
+package mycheckpoint
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+
+	"github.com/oneleo/LeNetPractice/mAiLab_0002/rand_fromgo"
+	"github.com/oneleo/LeNetPractice/mAiLab_0003/mynetwork"
+	"github.com/oneleo/LeNetPractice/mAiLab_0003/myoptimizer"
+	"github.com/oneleo/LeNetPractice/mAiLab_0003/mytensor"
+)
+
+// 檢查點檔案格式（所有整數皆為 Big-Endian，與 MNIST 檔案相同）：
+// [type]            [description]
+// 4 bytes           magic "LNCK"
+// uint32            format version（目前為 2，也可以讀入版本 1）
+// uint32 + bytes    模型架構：mynetwork.Config 的 JSON
+// uint32            下一個要訓練的 epoch
+// uint32            參數個數 N
+// N 組 tensor       每組為 name、shape 與 float64 元素（見 writer.tensor）
+// uint32 + bytes    最佳化器型別名稱，空字串表示沒有保存最佳化器
+// float64           學習率
+// uint32            最佳化器已更新次數
+// N 組              每組為 uint32 狀態個數 S，以及 S 組 tensor
+// uint32 + bytes    亂數產生器狀態（encoding.BinaryMarshaler 的輸出），長度 0 表示沒有保存
+// 以下五項從版本 2 開始才有：
+// uint32 + bytes    學習率排程型別名稱，空字串表示沒有保存排程狀態
+// uint32 + bytes    學習率排程狀態（encoding.BinaryMarshaler 的輸出）
+// float64           提早停止目前最低的損失
+// uint32            提早停止連續沒有改善的 epoch 數
+// uint32 + M 組     最低損失時的參數個數 M（0 表示沒有，否則為 N）與 M 組 tensor
+// uint32            以上所有 bytes 的 CRC-32（IEEE）
+
+// region variable
+
+// Magic 為檢查點檔案開頭的識別字串。
+var Magic = [4]byte{'L', 'N', 'C', 'K'}
+
+// endregion variable
+
+// region const
+
+// Version 為目前寫入的檔案格式版本。
+const Version = 2
+
+// endregion const
+
+// region struct
+
+// Checkpoint 是從檔案讀入的檢查點內容。
+type Checkpoint struct {
+	Config mynetwork.Config
+	Epoch  int
+	// Names 與 Values 為依模型參數順序保存的參數名稱與值。
+	Names  []string
+	Values []*mytensor.Tensor
+
+	// Optimizer 為保存時最佳化器的型別名稱（如 "*myoptimizer.Adam"），空字串表示沒有保存。
+	Optimizer string
+	LR        float64
+	Step      int
+	Slots     [][]*mytensor.Tensor
+
+	// RNG 為亂數產生器的狀態，長度 0 表示沒有保存。
+	RNG []byte
+
+	// Schedule 為學習率排程的型別名稱，ScheduleState 為其狀態，空字串表示沒有保存。
+	Schedule      string
+	ScheduleState []byte
+	// Best、Bad 與 BestValues 為提早停止的狀態（見 mytrainer.Trainer），BestValues 為 nil 表示還沒有最低損失。
+	Best       float64
+	Bad        int
+	BestValues []*mytensor.Tensor
+}
+
+// writer 會將資料以 Big-Endian 寫入記憶體緩衝，寫入 bytes.Buffer 不會發生錯誤。
+type writer struct {
+	buf bytes.Buffer
+}
+
+// reader 會從 bytes.Reader 讀取資料，並記錄第一個發生的錯誤。
+type reader struct {
+	r   *bytes.Reader
+	err error
+}
+
+// endregion struct
+
+// region function
+
+// Save 函數會將模型架構與參數、最佳化器狀態、epoch 以及亂數產生器狀態寫入 dstFile。
+// opt 或 rng 為 nil 時不保存該部份；寫入時先寫到同目錄的暫存檔再更名，
+// 因此寫到一半中斷也不會破壞原本的檢查點。
+func Save(dstFile string, model *mynetwork.Model, opt myoptimizer.Optimizer, epoch int, rng encoding.BinaryMarshaler) (err error) {
+	return save(dstFile, model, opt, rng, &Checkpoint{Epoch: epoch})
+}
+
+// save 函數會寫入 model、opt、rng 的狀態，以及 ck 中 epoch、學習率排程與提早停止的狀態。
+func save(dstFile string, model *mynetwork.Model, opt myoptimizer.Optimizer, rng encoding.BinaryMarshaler, ck *Checkpoint) (err error) {
+	cfg, err := json.Marshal(model.Config)
+	if err != nil {
+		return err
+	}
+	params := model.Params()
+	w := &writer{}
+	w.buf.Write(Magic[:])
+	w.u32(Version)
+	w.bytes(cfg)
+	w.u32(uint32(ck.Epoch))
+	w.u32(uint32(len(params)))
+	for _, p := range params {
+		w.tensor(p.Name, p.Value)
+	}
+
+	if opt == nil {
+		w.bytes(nil)
+		w.f64(0)
+		w.u32(0)
+	} else {
+		st, ok := opt.(myoptimizer.Stateful)
+		if !ok {
+			return fmt.Errorf("Error: optimizer %T cannot export its state", opt)
+		}
+		step, slots := st.State(params)
+		w.bytes([]byte(fmt.Sprintf("%T", opt)))
+		w.f64(opt.LearningRate())
+		w.u32(uint32(step))
+		for n, p := range params {
+			w.u32(uint32(len(slots[n])))
+			for _, s := range slots[n] {
+				w.tensor(p.Name, s)
+			}
+		}
+	}
+
+	var state []byte
+	if rng != nil {
+		if state, err = rng.MarshalBinary(); err != nil {
+			return err
+		}
+	}
+	w.bytes(state)
+
+	w.bytes([]byte(ck.Schedule))
+	w.bytes(ck.ScheduleState)
+	w.f64(ck.Best)
+	w.u32(uint32(ck.Bad))
+	if ck.BestValues != nil && len(ck.BestValues) != len(params) {
+		return fmt.Errorf("Error: %d best parameters, model has %d", len(ck.BestValues), len(params))
+	}
+	w.u32(uint32(len(ck.BestValues)))
+	for n, t := range ck.BestValues {
+		w.tensor(params[n].Name, t)
+	}
+	w.u32(crc32.ChecksumIEEE(w.buf.Bytes()))
+	return writeAtomic(dstFile, w.buf.Bytes())
+}
+
+// writeAtomic 函數會先將 data 寫入同目錄的暫存檔並同步至磁碟，再更名為 dstFile。
+func writeAtomic(dstFile string, data []byte) (err error) {
+	// 根據作業系統調整路徑的正反鈄線，以及將目錄路徑轉換成絕對路徑。
+	dstFile, err = filepath.Abs(dstFile)
+	if err != nil {
+		fmt.Println("Error while finding absolute path", dstFile, "-", err)
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(dstFile), "."+filepath.Base(dstFile)+"-*")
+	if err != nil {
+		fmt.Println("Error while creating temporary file for", dstFile, "-", err)
+		return err
+	}
+	// 發生錯誤時刪除暫存檔；成功更名後 Remove 會因找不到檔案而無作用。
+	defer os.Remove(tmp.Name())
+
+	if _, err = tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err = tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+	// os.CreateTemp 建立的檔案權限為 0600，改成與其他輸出檔相同的 0644。
+	if err = os.Chmod(tmp.Name(), 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), dstFile)
+}
+
+// Load 函數會讀入 src 檢查點，並確認識別字串、版本與 CRC。
+func Load(src string) (ck *Checkpoint, err error) {
+	// 根據作業系統調整路徑的正反鈄線，以及將目錄路徑轉換成絕對路徑。
+	src, err = filepath.Abs(src)
+	if err != nil {
+		fmt.Println("Error while finding absolute path", src, "-", err)
+		return nil, err
+	}
+	data, err := os.ReadFile(src)
+	if err != nil {
+		return nil, err
+	}
+	return Decode(data)
+}
+
+// Decode 函數會解析 Save 寫入的檢查點內容。
+func Decode(data []byte) (ck *Checkpoint, err error) {
+	if len(data) < len(Magic)+8 || !bytes.Equal(data[:len(Magic)], Magic[:]) {
+		return nil, errors.New("Error: not a checkpoint file")
+	}
+	body := data[:len(data)-4]
+	if crc32.ChecksumIEEE(body) != binary.BigEndian.Uint32(data[len(data)-4:]) {
+		return nil, errors.New("Error: checkpoint checksum mismatch")
+	}
+	r := &reader{r: bytes.NewReader(body[len(Magic):])}
+	version := r.u32()
+	if r.err == nil && (version < 1 || version > Version) {
+		return nil, fmt.Errorf("Error: unsupported checkpoint version %d", version)
+	}
+	ck = &Checkpoint{}
+	if cfg := r.bytes(); r.err == nil {
+		if err = json.Unmarshal(cfg, &ck.Config); err != nil {
+			return nil, err
+		}
+	}
+	ck.Epoch = int(r.u32())
+	n := int(r.u32())
+	for i := 0; i < n && r.err == nil; i++ {
+		name, t := r.tensor()
+		ck.Names = append(ck.Names, name)
+		ck.Values = append(ck.Values, t)
+	}
+	ck.Optimizer = string(r.bytes())
+	ck.LR = r.f64()
+	ck.Step = int(r.u32())
+	if ck.Optimizer != "" {
+		ck.Slots = make([][]*mytensor.Tensor, n)
+		for i := 0; i < n && r.err == nil; i++ {
+			slots := int(r.u32())
+			for s := 0; s < slots && r.err == nil; s++ {
+				_, t := r.tensor()
+				ck.Slots[i] = append(ck.Slots[i], t)
+			}
+		}
+	}
+	ck.RNG = r.bytes()
+	if version >= 2 {
+		ck.Schedule = string(r.bytes())
+		ck.ScheduleState = r.bytes()
+		ck.Best = r.f64()
+		ck.Bad = int(r.u32())
+		m := int(r.u32())
+		if r.err == nil && m != 0 && m != n {
+			return nil, fmt.Errorf("Error: corrupted checkpoint: %d best parameters, should be 0 or %d", m, n)
+		}
+		for i := 0; i < m && r.err == nil; i++ {
+			_, t := r.tensor()
+			ck.BestValues = append(ck.BestValues, t)
+		}
+	}
+	if r.err != nil {
+		return nil, fmt.Errorf("Error: corrupted checkpoint: %v", r.err)
+	}
+	if r.r.Len() != 0 {
+		return nil, errors.New("Error: corrupted checkpoint: trailing data")
+	}
+	return ck, nil
+}
+
+// endregion function
+
+// region method
+
+// NewModel 會依檢查點保存的架構建立模型，並還原所有參數值。
+func (ck *Checkpoint) NewModel() (model *mynetwork.Model, err error) {
+	// 初始權重會被檢查點的參數覆寫，所以種子不影響結果。
+	model, err = mynetwork.NewLeNet5(ck.Config, rand_fromgo.New(rand_fromgo.NewSource(1)))
+	if err != nil {
+		return nil, err
+	}
+	if err = ck.Restore(model, nil, nil); err != nil {
+		return nil, err
+	}
+	return model, nil
+}
+
+// Restore 會將檢查點的參數值寫回 model，並在 opt、rng 不為 nil 時還原最佳化器與亂數產生器的狀態。
+// 參數名稱、個數與形狀必須與 model 完全相同，最佳化器的型別與狀態也必須相符；
+// 所有內容都檢查通過、且 rng 還原成功後才會修改 model 與 opt，否則不會修改任何狀態並回傳錯誤。
+func (ck *Checkpoint) Restore(model *mynetwork.Model, opt myoptimizer.Optimizer, rng encoding.BinaryUnmarshaler) (err error) {
+	params := model.Params()
+	if len(params) != len(ck.Values) {
+		return fmt.Errorf("Error: checkpoint has %d parameters, model has %d", len(ck.Values), len(params))
+	}
+	for i, p := range params {
+		if p.Name != ck.Names[i] || !p.Value.SameShape(ck.Values[i]) {
+			return fmt.Errorf("Error: checkpoint parameter %s %v does not match model parameter %s %v",
+				ck.Names[i], ck.Values[i].Shape, p.Name, p.Value.Shape)
+		}
+	}
+	var st myoptimizer.Stateful
+	if opt != nil {
+		if name := fmt.Sprintf("%T", opt); name != ck.Optimizer {
+			return fmt.Errorf("Error: checkpoint optimizer is %q, got %s", ck.Optimizer, name)
+		}
+		var ok bool
+		if st, ok = opt.(myoptimizer.Stateful); !ok {
+			return fmt.Errorf("Error: optimizer %T cannot restore its state", opt)
+		}
+		if err = st.CheckState(params, ck.Step, ck.Slots); err != nil {
+			return err
+		}
+	}
+	// 亂數產生器的狀態無法事先檢查，因此在其他內容都檢查通過後、修改 model 與 opt 之前還原。
+	if rng != nil && len(ck.RNG) > 0 {
+		if err = rng.UnmarshalBinary(ck.RNG); err != nil {
+			return err
+		}
+	}
+	if st != nil {
+		if err = st.SetState(params, ck.Step, ck.Slots); err != nil {
+			return err
+		}
+		opt.SetLearningRate(ck.LR)
+	}
+	for i, p := range params {
+		copy(p.Value.Data, ck.Values[i].Data)
+	}
+	return nil
+}
+
+// u32 會寫入一個 uint32。
+func (w *writer) u32(v uint32) {
+	binary.Write(&w.buf, binary.BigEndian, v)
+}
+
+// f64 會寫入一個 float64。
+func (w *writer) f64(v float64) {
+	binary.Write(&w.buf, binary.BigEndian, math.Float64bits(v))
+}
+
+// bytes 會寫入 uint32 長度與 b。
+func (w *writer) bytes(b []byte) {
+	w.u32(uint32(len(b)))
+	w.buf.Write(b)
+}
+
+// tensor 會寫入名稱、維度數、每一維的大小以及所有元素。
+func (w *writer) tensor(name string, t *mytensor.Tensor) {
+	w.bytes([]byte(name))
+	w.u32(uint32(len(t.Shape)))
+	for _, s := range t.Shape {
+		w.u32(uint32(s))
+	}
+	for _, v := range t.Data {
+		w.f64(v)
+	}
+}
+
+// u32 會讀取一個 uint32。
+func (r *reader) u32() (v uint32) {
+	if r.err == nil {
+		r.err = binary.Read(r.r, binary.BigEndian, &v)
+	}
+	return v
+}
+
+// f64 會讀取一個 float64。
+func (r *reader) f64() float64 {
+	var v uint64
+	if r.err == nil {
+		r.err = binary.Read(r.r, binary.BigEndian, &v)
+	}
+	return math.Float64frombits(v)
+}
+
+// bytes 會讀取 uint32 長度與對應的 bytes，長度超過剩餘資料時記錄錯誤。
+func (r *reader) bytes() (b []byte) {
+	n := r.u32()
+	if r.err != nil {
+		return nil
+	}
+	if int64(n) > int64(r.r.Len()) {
+		r.err = io.ErrUnexpectedEOF
+		return nil
+	}
+	b = make([]byte, n)
+	_, r.err = io.ReadFull(r.r, b)
+	return b
+}
+
+// tensor 會讀取 writer.tensor 寫入的名稱與 Tensor。
+func (r *reader) tensor() (name string, t *mytensor.Tensor) {
+	name = string(r.bytes())
+	dims := int(r.u32())
+	if r.err != nil {
+		return name, nil
+	}
+	if dims > r.r.Len()/4 {
+		r.err = io.ErrUnexpectedEOF
+		return name, nil
+	}
+	shape := make([]int, dims)
+	size := 1
+	for d := range shape {
+		shape[d] = int(r.u32())
+		if r.err != nil {
+			return name, nil
+		}
+		if shape[d] <= 0 {
+			r.err = fmt.Errorf("invalid tensor dimension %d", shape[d])
+			return name, nil
+		}
+		// 每乘一維就與剩餘資料比較，避免惡意的大小溢位後通過檢查。
+		if size > r.r.Len()/8/shape[d] {
+			r.err = io.ErrUnexpectedEOF
+			return name, nil
+		}
+		size *= shape[d]
+	}
+	if size > r.r.Len()/8 {
+		r.err = io.ErrUnexpectedEOF
+		return name, nil
+	}
+	t = mytensor.New(shape...)
+	for i := range t.Data {
+		t.Data[i] = r.f64()
+	}
+	return name, t
+}
+
+// endregion method
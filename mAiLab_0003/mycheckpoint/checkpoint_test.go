@@ -0,0 +1,364 @@
+// How to use:
+//
+// 1. Testing
+// (1) > cd "%GOPATH%\src\github.com\oneleo\LeNetPractice\mAiLab_0003\mycheckpoint"
+// or (1) $ cd "$GOPATH/src/github.com/oneleo/LeNetPractice/mAiLab_0003/mycheckpoint"
+// (2) $> go test -v
+
+package mycheckpoint
+
+import (
+	"hash/crc32"
+	"image"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/oneleo/LeNetPractice/mAiLab_0002/rand_fromgo"
+	"github.com/oneleo/LeNetPractice/mAiLab_0003/mymnist"
+	"github.com/oneleo/LeNetPractice/mAiLab_0003/mynetwork"
+	"github.com/oneleo/LeNetPractice/mAiLab_0003/myoptimizer"
+	"github.com/oneleo/LeNetPractice/mAiLab_0003/mytensor"
+	"github.com/oneleo/LeNetPractice/mAiLab_0003/mytrainer"
+)
+
+// trainedModel 會建立一個模型，並以 Adam 做一次更新，讓最佳化器有狀態可以保存。
+func trainedModel(cfg mynetwork.Config, seed int64) (*mynetwork.Model, *myoptimizer.Adam) {
+	m, _ := mynetwork.NewLeNet5(cfg, rand_fromgo.New(rand_fromgo.NewSource(seed)))
+	opt := myoptimizer.NewAdam(0.01)
+	out, _ := m.Forward(mytensor.New(1, mynetwork.InputRows, mynetwork.InputCols))
+	_, grad, _ := mynetwork.NewCrossEntropy().Loss(out, 3)
+	m.Backward(grad)
+	opt.Step(m.Params())
+	return m, opt
+}
+
+// Test_SaveLoad 是測試模型參數、架構與最佳化器狀態寫入後可以完整讀回。
+func Test_SaveLoad(t *testing.T) {
+	dst := filepath.Join(t.TempDir(), "lenet.ckpt")
+	m, opt := trainedModel(mynetwork.ClassicConfig(), 1)
+	if err := Save(dst, m, opt, 7, nil); err != nil {
+		t.Fatal(err)
+	}
+	// 原子寫入後目錄內只會有檢查點本身，不會留下暫存檔。
+	if files, _ := os.ReadDir(filepath.Dir(dst)); len(files) != 1 {
+		t.Errorf("Error directory has %d files, should be 1.", len(files))
+	}
+	// 檢查點的權限應與其他輸出檔相同，其他使用者也可以讀取。
+	if info, err := os.Stat(dst); err != nil || info.Mode().Perm()&0044 != 0044 {
+		t.Errorf("Error checkpoint mode %v, should be readable by everyone.", info.Mode())
+	}
+	ck, err := Load(dst)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ck.Epoch != 7 || ck.Config != mynetwork.ClassicConfig() || ck.Optimizer != "*myoptimizer.Adam" {
+		t.Errorf("Error checkpoint header %+v.", ck)
+	}
+
+	restored, err := ck.NewModel()
+	if err != nil {
+		t.Fatal(err)
+	}
+	in := mytensor.New(1, mynetwork.InputRows, mynetwork.InputCols)
+	in.Fill(0.5)
+	want, _ := m.Forward(in)
+	got, _ := restored.Forward(in)
+	for i := range want.Data {
+		if want.Data[i] != got.Data[i] {
+			t.Fatalf("Error restored output %v, should be %v.", got.Data, want.Data)
+		}
+	}
+
+	opt2 := myoptimizer.NewAdam(0.5)
+	if err = ck.Restore(restored, opt2, nil); err != nil {
+		t.Fatal(err)
+	}
+	step, slots := opt.State(m.Params())
+	step2, slots2 := opt2.State(restored.Params())
+	if step != step2 || opt2.LearningRate() != 0.01 {
+		t.Errorf("Error optimizer step %d lr %g, should be %d and 0.01.", step2, opt2.LearningRate(), step)
+	}
+	for n := range slots {
+		for s := range slots[n] {
+			for i, v := range slots[n][s].Data {
+				if slots2[n][s].Data[i] != v {
+					t.Fatalf("Error optimizer state %d/%d differs.", n, s)
+				}
+			}
+		}
+	}
+}
+
+// Test_Validate 是測試架構不符、最佳化器不符以及損毀的檔案都會回傳錯誤。
+func Test_Validate(t *testing.T) {
+	dst := filepath.Join(t.TempDir(), "lenet.ckpt")
+	m, opt := trainedModel(mynetwork.ClassicConfig(), 1)
+	if err := Save(dst, m, opt, 1, nil); err != nil {
+		t.Fatal(err)
+	}
+	ck, _ := Load(dst)
+
+	modern, _ := mynetwork.NewLeNet5(mynetwork.ModernConfig(), rand_fromgo.New(rand_fromgo.NewSource(1)))
+	before := modern.Params()[0].Value.Clone()
+	if err := ck.Restore(modern, nil, nil); err == nil {
+		t.Errorf("Error restoring into a different architecture should return an error.")
+	}
+	if modern.Params()[0].Value.Data[0] != before.Data[0] {
+		t.Errorf("Error failed restore should not modify the model.")
+	}
+	if err := ck.Restore(m, myoptimizer.NewSGD(0.1), nil); err == nil {
+		t.Errorf("Error restoring into a different optimizer should return an error.")
+	}
+
+	data, _ := os.ReadFile(dst)
+	data[len(data)/2] ^= 0xFF
+	if _, err := Decode(data); err == nil {
+		t.Errorf("Error corrupted checkpoint should return an error.")
+	}
+	if _, err := Decode(data[:10]); err == nil {
+		t.Errorf("Error truncated checkpoint should return an error.")
+	}
+
+	// 亂數產生器的狀態無效時，不應修改模型與最佳化器。
+	ck.RNG = []byte("bad state")
+	opt2 := myoptimizer.NewAdam(0.5)
+	before = m.Params()[0].Value.Clone()
+	m.Params()[0].Value.Data[0]++
+	if err := ck.Restore(m, opt2, rand_fromgo.New(rand_fromgo.NewSource(1))); err == nil {
+		t.Errorf("Error restoring an invalid RNG state should return an error.")
+	}
+	if step, _ := opt2.State(m.Params()); step != 0 || opt2.LearningRate() != 0.5 || m.Params()[0].Value.Data[0] == before.Data[0] {
+		t.Errorf("Error failed restore should not modify the optimizer or the model.")
+	}
+}
+
+// Test_DecodeShape 是測試 CRC 正確但維度為 0 或乘積溢位的檔案會回傳錯誤，而不是在配置記憶體時 panic。
+func Test_DecodeShape(t *testing.T) {
+	var tests = []struct {
+		name  string
+		shape []uint32
+	}{
+		{"zero", []uint32{6, 0, 5}},
+		{"overflow", []uint32{1 << 31, 1 << 31, 1 << 31}},
+		{"negative", []uint32{1 << 31, 1 << 31, 2}},
+		{"too large", []uint32{1000, 1000}},
+	}
+	for _, test := range tests {
+		w := &writer{}
+		w.buf.Write(Magic[:])
+		w.u32(Version)
+		w.bytes([]byte("{}"))
+		w.u32(0)
+		w.u32(1)
+		w.bytes([]byte("c1.weight"))
+		w.u32(uint32(len(test.shape)))
+		for _, d := range test.shape {
+			w.u32(d)
+		}
+		w.f64(1)
+		w.bytes(nil)
+		w.f64(0)
+		w.u32(0)
+		w.bytes(nil)
+		w.u32(crc32.ChecksumIEEE(w.buf.Bytes()))
+		if _, err := Decode(w.buf.Bytes()); err == nil {
+			t.Errorf("Error %s shape %v should return an error.", test.name, test.shape)
+		}
+	}
+}
+
+// newTrainer 會建立一個以 Adam 訓練古典 LeNet-5 的 Trainer，每次建立的初始參數都相同。
+func newTrainer(epochs int) *mytrainer.Trainer {
+	m, _ := mynetwork.NewLeNet5(mynetwork.ClassicConfig(), rand_fromgo.New(rand_fromgo.NewSource(1)))
+	return mytrainer.New(m, mynetwork.NewCrossEntropy(), myoptimizer.NewAdam(0.005),
+		mytrainer.Config{Epochs: epochs, BatchSize: 4, Seed: 5})
+}
+
+// Test_ResumeTrainer 是測試中斷後從檢查點繼續訓練，結果與未中斷的訓練逐位元相同（包含打亂資料的順序）。
+func Test_ResumeTrainer(t *testing.T) {
+	// 每張影像只在不同位置有一個亮點，打亂的順序不同就會得到不同的參數。
+	ds := &mymnist.Dataset{}
+	for i := 0; i < 10; i++ {
+		img := image.NewGray(image.Rect(0, 0, 28, 28))
+		img.Pix[i*57] = 255
+		ds.Images = append(ds.Images, *img)
+		ds.Labels = append(ds.Labels, byte(i%3))
+	}
+
+	full := newTrainer(3)
+	if _, err := full.Fit(ds, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	dst := filepath.Join(t.TempDir(), "lenet.ckpt")
+	first := newTrainer(1)
+	if _, err := first.Fit(ds, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := SaveTrainer(dst, first); err != nil {
+		t.Fatal(err)
+	}
+	resumed := newTrainer(3)
+	if err := ResumeTrainer(dst, resumed); err != nil {
+		t.Fatal(err)
+	}
+	if resumed.Epoch != 1 || resumed.Rand == nil {
+		t.Fatalf("Error resumed trainer epoch %d, rand %v.", resumed.Epoch, resumed.Rand)
+	}
+	if _, err := resumed.Fit(ds, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	want, got := full.Model.Params(), resumed.Model.Params()
+	for n := range want {
+		for i, v := range want[n].Value.Data {
+			if got[n].Value.Data[i] != v {
+				t.Fatalf("Error resumed parameter %s[%d] = %g, should be %g.", got[n].Name, i, got[n].Value.Data[i], v)
+			}
+		}
+	}
+	if a, b := full.Rand.Int63(), resumed.Rand.Int63(); a != b {
+		t.Errorf("Error resumed random stream %d, should be %d.", b, a)
+	}
+}
+
+// Test_ResumeEarlyStopping 是測試提早停止與 ReduceOnPlateau 的狀態也會保存：從驗證損失沒有改善的 epoch 之後繼續訓練，
+// 停止的 epoch、學習率與還原的最佳參數都與未中斷的訓練相同。
+func Test_ResumeEarlyStopping(t *testing.T) {
+	ds := &mymnist.Dataset{}
+	for i := 0; i < 10; i++ {
+		img := image.NewGray(image.Rect(0, 0, 28, 28))
+		img.Pix[i*57] = 255
+		ds.Images = append(ds.Images, *img)
+		ds.Labels = append(ds.Labels, byte(i%3))
+	}
+	newEarly := func(epochs int) *mytrainer.Trainer {
+		tr := newTrainer(epochs)
+		tr.Config.Patience = 2
+		tr.Config.Schedule = myoptimizer.NewReduceOnPlateau(0.02, 0.5, 1)
+		return tr
+	}
+
+	full := newEarly(10)
+	if _, err := full.Fit(ds, ds); err != nil {
+		t.Fatal(err)
+	}
+	// 此設定下第 1 個 epoch 的驗證損失最低，第 2 個 epoch 沒有改善並降低學習率，於第 6 個 epoch 後提早停止。
+	if len(full.History) != 6 {
+		t.Fatalf("Error full run trained %d epochs, should stop after 6.", len(full.History))
+	}
+
+	dst := filepath.Join(t.TempDir(), "lenet.ckpt")
+	first := newEarly(3)
+	if _, err := first.Fit(ds, ds); err != nil {
+		t.Fatal(err)
+	}
+	if first.Bad != 1 {
+		t.Fatalf("Error checkpoint taken with bad = %d, should be 1.", first.Bad)
+	}
+	if err := SaveTrainer(dst, first); err != nil {
+		t.Fatal(err)
+	}
+	resumed := newEarly(10)
+	if err := ResumeTrainer(dst, resumed); err != nil {
+		t.Fatal(err)
+	}
+	if resumed.Best != first.Best || resumed.Bad != 1 || len(resumed.BestParams) != len(first.BestParams) {
+		t.Fatalf("Error resumed best %g bad %d, should be %g and 1.", resumed.Best, resumed.Bad, first.Best)
+	}
+	if _, err := resumed.Fit(ds, ds); err != nil {
+		t.Fatal(err)
+	}
+
+	history := append(first.History, resumed.History...)
+	if len(history) != len(full.History) || resumed.Epoch != full.Epoch {
+		t.Fatalf("Error resumed run trained %d epochs, should be %d.", len(history), len(full.History))
+	}
+	for i, want := range full.History {
+		if history[i] != want {
+			t.Errorf("Error epoch %d: %+v, should be %+v.", i, history[i], want)
+		}
+	}
+	want, got := full.Model.Params(), resumed.Model.Params()
+	for n := range want {
+		for i, v := range want[n].Value.Data {
+			if got[n].Value.Data[i] != v {
+				t.Fatalf("Error resumed parameter %s[%d] = %g, should be %g.", got[n].Name, i, got[n].Value.Data[i], v)
+			}
+		}
+	}
+
+	// 排程型別不同時回傳錯誤，且不修改 Trainer。
+	other := newTrainer(10)
+	before := other.Model.Params()[0].Value.Clone()
+	other.Config.Schedule = &myoptimizer.Constant{LR: 0.02}
+	if err := ResumeTrainer(dst, other); err == nil {
+		t.Error("Error resuming with a different schedule should return an error.")
+	}
+	if other.Epoch != 0 || other.BestParams != nil || other.Rand != nil || other.Model.Params()[0].Value.Data[0] != before.Data[0] {
+		t.Error("Error failed resume modified the trainer.")
+	}
+	// 排程相符但最佳化器不同時，已還原的排程狀態會復原。
+	other.Config.Schedule = myoptimizer.NewReduceOnPlateau(0.02, 0.5, 1)
+	other.Optimizer = myoptimizer.NewSGD(0.02)
+	if err := ResumeTrainer(dst, other); err == nil {
+		t.Error("Error resuming with a different optimizer should return an error.")
+	}
+	if lr := other.Config.Schedule.Rate(0); lr != 0.02 {
+		t.Errorf("Error failed resume changed the schedule rate to %g.", lr)
+	}
+}
+
+// Test_DecodeVersion 是測試版本 1 的檢查點（沒有排程與提早停止的狀態）仍可讀入，以及最佳參數個數不符時回傳錯誤。
+func Test_DecodeVersion(t *testing.T) {
+	// header 會寫入一個只有 1 個參數、沒有最佳化器與亂數產生器狀態的檢查點開頭。
+	header := func(version uint32) *writer {
+		w := &writer{}
+		w.buf.Write(Magic[:])
+		w.u32(version)
+		w.bytes([]byte("{}"))
+		w.u32(3)
+		w.u32(1)
+		w.tensor("c1.weight", mytensor.New(2))
+		w.bytes(nil)
+		w.f64(0)
+		w.u32(0)
+		w.bytes(nil)
+		return w
+	}
+	w := header(1)
+	w.u32(crc32.ChecksumIEEE(w.buf.Bytes()))
+	ck, err := Decode(w.buf.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ck.Epoch != 3 || ck.Schedule != "" || ck.Bad != 0 || ck.BestValues != nil {
+		t.Errorf("Error version 1 checkpoint %+v.", ck)
+	}
+
+	var tests = []struct {
+		name    string
+		version uint32
+		best    int
+	}{
+		{"best count", 2, 2},
+		{"future version", Version + 1, 0},
+		{"version 0", 0, 0},
+	}
+	for _, test := range tests {
+		w = header(test.version)
+		w.bytes(nil)
+		w.bytes(nil)
+		w.f64(1)
+		w.u32(1)
+		w.u32(uint32(test.best))
+		for i := 0; i < test.best; i++ {
+			w.tensor("c1.weight", mytensor.New(2))
+		}
+		w.u32(crc32.ChecksumIEEE(w.buf.Bytes()))
+		if _, err = Decode(w.buf.Bytes()); err == nil {
+			t.Errorf("Error %s should return an error.", test.name)
+		}
+	}
+}
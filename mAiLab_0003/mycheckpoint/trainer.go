@@ -0,0 +1,99 @@
+package mycheckpoint
+
+import (
+	"encoding"
+	"fmt"
+
+	"github.com/oneleo/LeNetPractice/mAiLab_0002/rand_fromgo"
+	"github.com/oneleo/LeNetPractice/mAiLab_0003/mytrainer"
+)
+
+// region interface
+
+// binaryState 是可以編碼與還原狀態的學習率排程，例如 *myoptimizer.ReduceOnPlateau。
+type binaryState interface {
+	encoding.BinaryMarshaler
+	encoding.BinaryUnmarshaler
+}
+
+// endregion interface
+
+// region function
+
+// SaveTrainer 函數會保存 Trainer 目前的模型、最佳化器、下一個 epoch、提早停止的狀態，
+// 以及打亂資料用的亂數產生器狀態（t.Rand 為 nil 時不保存）；
+// 學習率排程可以編碼狀態時（如 *myoptimizer.ReduceOnPlateau）也會一併保存。
+func SaveTrainer(dstFile string, t *mytrainer.Trainer) (err error) {
+	var rng encoding.BinaryMarshaler
+	if t.Rand != nil {
+		rng = t.Rand
+	}
+	ck := &Checkpoint{Epoch: t.Epoch, Best: t.Best, Bad: t.Bad, BestValues: t.BestParams}
+	if sched, ok := t.Config.Schedule.(binaryState); ok {
+		if ck.ScheduleState, err = sched.MarshalBinary(); err != nil {
+			return err
+		}
+		ck.Schedule = fmt.Sprintf("%T", sched)
+	}
+	return save(dstFile, t.Model, t.Optimizer, rng, ck)
+}
+
+// ResumeTrainer 函數會以 src 檢查點還原 t 的模型參數、最佳化器狀態、epoch、提早停止與學習率排程的狀態，
+// 讓 t.Fit 從中斷處繼續訓練。
+// 若檢查點保存了亂數產生器狀態，t.Rand 會還原成該狀態，打亂資料的順序也會與未中斷的訓練完全相同；
+// t.Rand 為 nil 時會先以 t.Config.Seed 建立。
+// 檢查點與 t 不相符時回傳錯誤，且不會修改 t 的任何狀態。
+func ResumeTrainer(src string, t *mytrainer.Trainer) (err error) {
+	ck, err := Load(src)
+	if err != nil {
+		return err
+	}
+	params := t.Model.Params()
+	if ck.BestValues != nil {
+		if len(ck.BestValues) != len(params) {
+			return fmt.Errorf("Error: checkpoint has %d best parameters, model has %d", len(ck.BestValues), len(params))
+		}
+		for i, p := range params {
+			if !p.Value.SameShape(ck.BestValues[i]) {
+				return fmt.Errorf("Error: checkpoint best parameter %v does not match model parameter %s %v",
+					ck.BestValues[i].Shape, p.Name, p.Value.Shape)
+			}
+		}
+	}
+	var sched binaryState
+	var old []byte
+	if ck.Schedule != "" {
+		var ok bool
+		if sched, ok = t.Config.Schedule.(binaryState); !ok || fmt.Sprintf("%T", sched) != ck.Schedule {
+			return fmt.Errorf("Error: checkpoint schedule is %q, got %T", ck.Schedule, t.Config.Schedule)
+		}
+		// 先保存排程原本的狀態，之後還原模型失敗時用來復原排程。
+		if old, err = sched.MarshalBinary(); err != nil {
+			return err
+		}
+		if err = sched.UnmarshalBinary(ck.ScheduleState); err != nil {
+			return err
+		}
+	}
+
+	var rng encoding.BinaryUnmarshaler
+	rnd := t.Rand
+	if len(ck.RNG) > 0 {
+		if rnd == nil {
+			rnd = rand_fromgo.New(rand_fromgo.NewSource(t.Config.Seed))
+		}
+		rng = rnd
+	}
+	if err = ck.Restore(t.Model, t.Optimizer, rng); err != nil {
+		if sched != nil {
+			sched.UnmarshalBinary(old)
+		}
+		return err
+	}
+	t.Rand = rnd
+	t.Epoch = ck.Epoch
+	t.Best, t.Bad, t.BestParams = ck.Best, ck.Bad, ck.BestValues
+	return nil
+}
+
+// endregion function
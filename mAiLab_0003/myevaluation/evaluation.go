@@ -0,0 +1,290 @@
+package myevaluation
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"image"
+	"io"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/oneleo/LeNetPractice/mAiLab_0003/mygzip"
+	"github.com/oneleo/LeNetPractice/mAiLab_0003/mymnist"
+	"github.com/oneleo/LeNetPractice/mAiLab_0003/mynetwork"
+	"github.com/oneleo/LeNetPractice/mAiLab_0003/mytensor"
+	"github.com/oneleo/LeNetPractice/mAiLab_0003/mytrainer"
+)
+
+// region struct
+
+// Report 是模型在一個資料集上的評估結果。
+type Report struct {
+	Classes int `json:"classes"`
+	// K 為 top-k 正確率的 k。
+	K     int `json:"k"`
+	Total int `json:"total"`
+	// Confusion[t][p] 為正確類別 t 被預測成 p 的筆數。
+	Confusion [][]int `json:"confusion"`
+	// TopKCorrect 為正確類別出現在分數前 K 名的筆數。
+	TopKCorrect int `json:"top_k_correct"`
+	// Predictions[n] 為第 n 次呼叫 Add 的預測類別；Evaluate 依資料順序加入，因此即為第 n 筆資料的預測。
+	Predictions []int `json:"predictions"`
+	// Misclassified 為預測錯誤的資料，依呼叫 Add 的順序排列。
+	Misclassified []Misclassification `json:"misclassified"`
+}
+
+// Misclassification 是一筆預測錯誤的資料：Add 傳入的資料索引、正確類別與預測類別。
+type Misclassification struct {
+	Index     int `json:"index"`
+	Label     int `json:"label"`
+	Predicted int `json:"predicted"`
+}
+
+// ClassStats 是單一類別的 precision、recall 與 F1。
+type ClassStats struct {
+	Class     int     `json:"class"`
+	Support   int     `json:"support"`
+	Precision float64 `json:"precision"`
+	Recall    float64 `json:"recall"`
+	F1        float64 `json:"f1"`
+}
+
+// endregion struct
+
+// region function
+
+// NewReport 函數會建立一個有 classes 個類別、計算 top-k 正確率的空白報告，k 會被限制在 [1, classes]。
+func NewReport(classes, k int) *Report {
+	if k < 1 {
+		k = 1
+	}
+	if k > classes {
+		k = classes
+	}
+	r := &Report{Classes: classes, K: k, Confusion: make([][]int, classes)}
+	for c := range r.Confusion {
+		r.Confusion[c] = make([]int, classes)
+	}
+	return r
+}
+
+// Evaluate 函數會以 model 預測 ds 的每一張影像並產生報告；input 為 nil 時使用 mytrainer.ImageToInput。
+func Evaluate(model *mynetwork.Model, ds *mymnist.Dataset, input func(image.Gray) (*mytensor.Tensor, error), k int) (r *Report, err error) {
+	if input == nil {
+		input = mytrainer.ImageToInput
+	}
+	r = NewReport(mynetwork.Classes, k)
+	for i := range ds.Labels {
+		in, err := input(ds.Images[i])
+		if err != nil {
+			return nil, err
+		}
+		out, err := model.Forward(in)
+		if err != nil {
+			return nil, fmt.Errorf("sample %d: %v", i, err)
+		}
+		scores := out.Data
+		// RBF 輸出為距離，越小越好，取負號後與一般輸出一樣「越大越好」。
+		if model.Config.Output == mynetwork.OutputRBF {
+			scores = make([]float64, out.Len())
+			for c, v := range out.Data {
+				scores[c] = -v
+			}
+		}
+		if err = r.Add(i, ds.Labels[i], scores); err != nil {
+			return nil, err
+		}
+	}
+	return r, nil
+}
+
+// ranking 函數會回傳依分數由大到小排序的類別。
+func ranking(scores []float64) (order []int) {
+	order = make([]int, len(scores))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool { return scores[order[a]] > scores[order[b]] })
+	return order
+}
+
+// ratio 函數會回傳 a/b，b 為 0 時回傳 0。
+func ratio(a, b int) float64 {
+	if b == 0 {
+		return 0
+	}
+	return float64(a) / float64(b)
+}
+
+// endregion function
+
+// region method
+
+// Add 會將第 index 筆資料（正確類別為 label、各類別分數為 scores，越大越好）加入報告。
+func (r *Report) Add(index int, label byte, scores []float64) (err error) {
+	if len(scores) != r.Classes {
+		return fmt.Errorf("Error: got %d scores, should be %d", len(scores), r.Classes)
+	}
+	if int(label) >= r.Classes {
+		return fmt.Errorf("Error: label %d out of range [0, %d)", label, r.Classes)
+	}
+	order := ranking(scores)
+	pred := order[0]
+	r.Total++
+	r.Confusion[label][pred]++
+	r.Predictions = append(r.Predictions, pred)
+	for _, c := range order[:r.K] {
+		if c == int(label) {
+			r.TopKCorrect++
+			break
+		}
+	}
+	if pred != int(label) {
+		r.Misclassified = append(r.Misclassified, Misclassification{Index: index, Label: int(label), Predicted: pred})
+	}
+	return nil
+}
+
+// Accuracy 會回傳 top-1 正確率。
+func (r *Report) Accuracy() float64 {
+	correct := 0
+	for c := range r.Confusion {
+		correct += r.Confusion[c][c]
+	}
+	return ratio(correct, r.Total)
+}
+
+// TopKAccuracy 會回傳 top-K 正確率。
+func (r *Report) TopKAccuracy() float64 { return ratio(r.TopKCorrect, r.Total) }
+
+// Stats 會回傳每一個類別的 precision、recall 與 F1；沒有預測或沒有樣本的類別其值為 0。
+func (r *Report) Stats() (stats []ClassStats) {
+	stats = make([]ClassStats, r.Classes)
+	for c := range stats {
+		predicted := 0
+		for t := range r.Confusion {
+			predicted += r.Confusion[t][c]
+		}
+		s := ClassStats{Class: c}
+		for _, n := range r.Confusion[c] {
+			s.Support += n
+		}
+		s.Precision = ratio(r.Confusion[c][c], predicted)
+		s.Recall = ratio(r.Confusion[c][c], s.Support)
+		if s.Precision+s.Recall > 0 {
+			s.F1 = 2 * s.Precision * s.Recall / (s.Precision + s.Recall)
+		}
+		stats[c] = s
+	}
+	return stats
+}
+
+// WriteText 會將正確率、混淆矩陣與每個類別的統計以文字表格寫入 w。
+// 表格先在記憶體中組好再一次寫入，寫入失敗時回傳該錯誤。
+func (r *Report) WriteText(w io.Writer) (err error) {
+	var b strings.Builder
+	fmt.Fprintf(&b, "samples: %d\n", r.Total)
+	fmt.Fprintf(&b, "top-1 accuracy: %.4f\n", r.Accuracy())
+	fmt.Fprintf(&b, "top-%d accuracy: %.4f\n", r.K, r.TopKAccuracy())
+	fmt.Fprintf(&b, "misclassified: %d\n\n", len(r.Misclassified))
+
+	b.WriteString("confusion (row: true, column: predicted)\n     ")
+	for c := 0; c < r.Classes; c++ {
+		fmt.Fprintf(&b, "%6d", c)
+	}
+	b.WriteString("\n")
+	for t, row := range r.Confusion {
+		fmt.Fprintf(&b, "%5d", t)
+		for _, n := range row {
+			fmt.Fprintf(&b, "%6d", n)
+		}
+		b.WriteString("\n")
+	}
+
+	b.WriteString("\nclass  support  precision  recall      f1\n")
+	for _, s := range r.Stats() {
+		fmt.Fprintf(&b, "%5d  %7d  %9.4f  %6.4f  %6.4f\n", s.Class, s.Support, s.Precision, s.Recall, s.F1)
+	}
+	_, err = io.WriteString(w, b.String())
+	return err
+}
+
+// WriteCSV 會將每個類別的統計寫成 CSV，最後一列為整體的 top-1 與 top-K 正確率。
+func (r *Report) WriteCSV(w io.Writer) (err error) {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"class", "support", "precision", "recall", "f1"})
+	for _, s := range r.Stats() {
+		cw.Write([]string{
+			strconv.Itoa(s.Class),
+			strconv.Itoa(s.Support),
+			strconv.FormatFloat(s.Precision, 'f', 6, 64),
+			strconv.FormatFloat(s.Recall, 'f', 6, 64),
+			strconv.FormatFloat(s.F1, 'f', 6, 64),
+		})
+	}
+	cw.Write([]string{"accuracy", strconv.Itoa(r.Total), strconv.FormatFloat(r.Accuracy(), 'f', 6, 64), "", ""})
+	cw.Write([]string{"top_" + strconv.Itoa(r.K), strconv.Itoa(r.Total), strconv.FormatFloat(r.TopKAccuracy(), 'f', 6, 64), "", ""})
+	cw.Flush()
+	return cw.Error()
+}
+
+// WriteConfusionCSV 會將混淆矩陣寫成 CSV，第一列與第一欄為類別編號。
+func (r *Report) WriteConfusionCSV(w io.Writer) (err error) {
+	cw := csv.NewWriter(w)
+	header := []string{"true\\predicted"}
+	for c := 0; c < r.Classes; c++ {
+		header = append(header, strconv.Itoa(c))
+	}
+	cw.Write(header)
+	for t, row := range r.Confusion {
+		record := []string{strconv.Itoa(t)}
+		for _, n := range row {
+			record = append(record, strconv.Itoa(n))
+		}
+		cw.Write(record)
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// WriteJSON 會將完整報告與每個類別的統計寫成 JSON。
+func (r *Report) WriteJSON(w io.Writer) (err error) {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(struct {
+		*Report
+		Accuracy     float64      `json:"accuracy"`
+		TopKAccuracy float64      `json:"top_k_accuracy"`
+		PerClass     []ClassStats `json:"per_class"`
+	}{r, r.Accuracy(), r.TopKAccuracy(), r.Stats()})
+}
+
+// WriteMisclassifiedBMP 會將 ds 中最多 limit 張預測錯誤的影像以 mymnist.WriteBMP 存到 dstDir，
+// 檔名為「索引_true正確類別_pred預測類別.bmp」；limit <= 0 表示全部輸出。
+// 影像以 Add 傳入的資料索引從 ds 取出，索引超出範圍或正確類別與 ds 不符時回傳錯誤。
+func (r *Report) WriteMisclassifiedBMP(dstDir string, ds *mymnist.Dataset, limit int) (err error) {
+	for _, m := range r.Misclassified {
+		if m.Index < 0 || m.Index >= ds.Len() || int(ds.Labels[m.Index]) != m.Label {
+			return fmt.Errorf("Error: misclassified sample %d does not match the dataset", m.Index)
+		}
+	}
+	// 若欲放置影像的目錄不存在則建立之。
+	if err = mygzip.CreateFolder(dstDir); err != nil {
+		return err
+	}
+	for n, m := range r.Misclassified {
+		if limit > 0 && n >= limit {
+			break
+		}
+		name := fmt.Sprintf("%05d_true%d_pred%d.bmp", m.Index, m.Label, m.Predicted)
+		if err = mymnist.WriteBMP(filepath.Join(dstDir, name), &ds.Images[m.Index]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// endregion method
@@ -0,0 +1,155 @@
+// How to use:
+//
+// 1. Testing
+// (1) > cd "%GOPATH%\src\github.com\oneleo\LeNetPractice\mAiLab_0003\myevaluation"
+// or (1) $ cd "$GOPATH/src/github.com/oneleo/LeNetPractice/mAiLab_0003/myevaluation"
+// (2) $> go test -v
+
+package myevaluation
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"image"
+	"math"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/oneleo/LeNetPractice/mAiLab_0003/mymnist"
+)
+
+// sampleReport 會建立一個 3 類別的報告：
+// 類別 0 兩筆皆正確；類別 1 一筆正確、一筆誤判為 2（第二名為 1）；類別 2 一筆誤判為 0（第二名為 1，第三名才是 2）。
+func sampleReport(t *testing.T) *Report {
+	r := NewReport(3, 2)
+	var tests = []struct {
+		label  byte
+		scores []float64
+	}{
+		{0, []float64{0.9, 0.05, 0.05}},
+		{0, []float64{0.6, 0.3, 0.1}},
+		{1, []float64{0.1, 0.8, 0.1}},
+		{1, []float64{0.1, 0.3, 0.6}},
+		{2, []float64{0.5, 0.3, 0.2}},
+	}
+	for i, test := range tests {
+		if err := r.Add(i, test.label, test.scores); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return r
+}
+
+// Test_Report 是測試正確率、top-k 正確率、混淆矩陣與每類別統計。
+func Test_Report(t *testing.T) {
+	r := sampleReport(t)
+	if r.Accuracy() != 0.6 || r.TopKAccuracy() != 0.8 {
+		t.Errorf("Error accuracy %g top-2 %g, should be 0.6 and 0.8.", r.Accuracy(), r.TopKAccuracy())
+	}
+	if r.Confusion[1][2] != 1 || r.Confusion[2][0] != 1 || r.Confusion[0][0] != 2 {
+		t.Errorf("Error confusion %v.", r.Confusion)
+	}
+	if want := []Misclassification{{3, 1, 2}, {4, 2, 0}}; len(r.Misclassified) != 2 || r.Misclassified[0] != want[0] || r.Misclassified[1] != want[1] {
+		t.Errorf("Error misclassified %v, should be %v.", r.Misclassified, want)
+	}
+	s := r.Stats()
+	// 類別 0：預測 3 次、正確 2 次，樣本 2 筆。
+	if math.Abs(s[0].Precision-2.0/3) > 1e-12 || s[0].Recall != 1 || math.Abs(s[0].F1-0.8) > 1e-12 {
+		t.Errorf("Error class 0 stats %+v.", s[0])
+	}
+	// 類別 2：沒有任何正確預測。
+	if s[2].Precision != 0 || s[2].Recall != 0 || s[2].F1 != 0 || s[2].Support != 1 {
+		t.Errorf("Error class 2 stats %+v.", s[2])
+	}
+}
+
+// Test_Export 是測試文字、CSV 與 JSON 輸出，以及誤判影像的 BMP 輸出。
+func Test_Export(t *testing.T) {
+	r := sampleReport(t)
+	var txt, csv, conf, js bytes.Buffer
+	if err := r.WriteText(&txt); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(txt.String(), "top-1 accuracy: 0.6000") {
+		t.Errorf("Error text report:\n%s", txt.String())
+	}
+	if err := r.WriteCSV(&csv); err != nil {
+		t.Fatal(err)
+	}
+	if lines := strings.Split(strings.TrimSpace(csv.String()), "\n"); len(lines) != 1+3+2 || lines[1] != "0,2,0.666667,1.000000,0.800000" {
+		t.Errorf("Error CSV report:\n%s", csv.String())
+	}
+	if err := r.WriteConfusionCSV(&conf); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(conf.String(), "\n1,0,1,1\n") {
+		t.Errorf("Error confusion CSV:\n%s", conf.String())
+	}
+	if err := r.WriteJSON(&js); err != nil {
+		t.Fatal(err)
+	}
+	var decoded struct {
+		Accuracy      float64             `json:"accuracy"`
+		Misclassified []Misclassification `json:"misclassified"`
+		PerClass      []ClassStats        `json:"per_class"`
+	}
+	if err := json.Unmarshal(js.Bytes(), &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if decoded.Accuracy != 0.6 || len(decoded.Misclassified) != 2 || decoded.Misclassified[1].Index != 4 || len(decoded.PerClass) != 3 {
+		t.Errorf("Error JSON report %s", js.String())
+	}
+
+	ds := &mymnist.Dataset{Labels: []byte{0, 0, 1, 1, 2}}
+	for range ds.Labels {
+		ds.Images = append(ds.Images, *image.NewGray(image.Rect(0, 0, 28, 28)))
+	}
+	dir := t.TempDir()
+	if err := r.WriteMisclassifiedBMP(dir, ds, 0); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(dir + "/00003_true1_pred2.bmp"); err != nil {
+		t.Errorf("Error misclassified image was not written: %v", err)
+	}
+
+	// 索引不必依序加入：以資料集中第 1 筆（正確類別 0）建立只有一筆的報告，檔名與影像都應對應第 1 筆。
+	single := NewReport(3, 1)
+	if err := single.Add(1, 0, []float64{0.1, 0.2, 0.7}); err != nil {
+		t.Fatal(err)
+	}
+	dir = t.TempDir()
+	if err := single.WriteMisclassifiedBMP(dir, ds, 0); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(dir + "/00001_true0_pred2.bmp"); err != nil {
+		t.Errorf("Error misclassified image for index 1 was not written: %v", err)
+	}
+	// 索引超出資料集或正確類別不符時回傳錯誤。
+	if err := single.WriteMisclassifiedBMP(t.TempDir(), &mymnist.Dataset{}, 0); err == nil {
+		t.Errorf("Error index outside the dataset should return an error.")
+	}
+	ds.Labels[1] = 2
+	if err := single.WriteMisclassifiedBMP(t.TempDir(), ds, 0); err == nil {
+		t.Errorf("Error label mismatch should return an error.")
+	}
+}
+
+// failWriter 在寫入 n 次後每次都回傳錯誤。
+type failWriter struct{ n int }
+
+func (w *failWriter) Write(p []byte) (int, error) {
+	if w.n <= 0 {
+		return 0, errors.New("write failed")
+	}
+	w.n--
+	return len(p), nil
+}
+
+// Test_WriteTextError 是測試寫入失敗時 WriteText 會回傳錯誤。
+func Test_WriteTextError(t *testing.T) {
+	if err := sampleReport(t).WriteText(&failWriter{}); err == nil {
+		t.Errorf("Error WriteText should return the write error.")
+	}
+}
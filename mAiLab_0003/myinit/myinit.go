@@ -0,0 +1,236 @@
+package myinit
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/oneleo/LeNetPractice/mAiLab_0002/rand_fromgo"
+	"github.com/oneleo/LeNetPractice/mAiLab_0003/mytensor"
+)
+
+// ByName 可用的初始化方式名稱。
+const (
+	NameUniformFanIn  = "uniform_fan_in"
+	NameXavierUniform = "xavier_uniform"
+	NameXavierNormal  = "xavier_normal"
+	NameHeNormal      = "he_normal"
+	NameOrthogonal    = "orthogonal"
+	NameZeros         = "zeros"
+)
+
+// region interface
+
+// Initializer 會以 rnd 產生的亂數填滿 t。fanIn 為每個輸出單元連接的輸入數，fanOut 為每個輸入單元連接的輸出數
+// （一般可以 Fans 由 t 的形狀計算）。相同狀態的 rnd 必須得到相同的結果。
+type Initializer interface {
+	Init(t *mytensor.Tensor, fanIn, fanOut int, rnd *rand_fromgo.Rand) error
+}
+
+// endregion interface
+
+// region struct
+
+// UniformFanIn 以 [-Scale/fanIn, Scale/fanIn) 的均勻分佈初始化，Scale 為 0 時使用 LeNet 論文的 2.4。
+type UniformFanIn struct {
+	Scale float64
+}
+
+// XavierUniform 是 Glorot 與 Bengio 的均勻分佈初始化：[-a, a)，a = Gain·√(6/(fanIn+fanOut))。Gain 為 0 時視為 1。
+type XavierUniform struct {
+	Gain float64
+}
+
+// XavierNormal 是 Glorot 與 Bengio 的常態分佈初始化：標準差 Gain·√(2/(fanIn+fanOut))。Gain 為 0 時視為 1。
+type XavierNormal struct {
+	Gain float64
+}
+
+// HeNormal 是 He 等人針對 ReLU 的常態分佈初始化：標準差 Gain·√(2/fanIn)。Gain 為 0 時視為 1。
+type HeNormal struct {
+	Gain float64
+}
+
+// Orthogonal 是 Saxe 等人的正交初始化：將 t 視為 Shape[0] 列的矩陣，列數不大於行數時各列互相正交且長度為 Gain，
+// 否則各行互相正交且長度為 Gain。Gain 為 0 時視為 1；fanIn、fanOut 不會使用。
+type Orthogonal struct {
+	Gain float64
+}
+
+// Constant 將所有元素設為 Value，常用於偏差值。
+type Constant struct {
+	Value float64
+}
+
+// endregion struct
+
+// region function
+
+// ByName 函數會回傳名稱為 name 的初始化方式（見 Name 開頭的常數），所有參數皆為預設值。
+func ByName(name string) (init Initializer, err error) {
+	switch name {
+	case NameUniformFanIn:
+		return UniformFanIn{}, nil
+	case NameXavierUniform:
+		return XavierUniform{}, nil
+	case NameXavierNormal:
+		return XavierNormal{}, nil
+	case NameHeNormal:
+		return HeNormal{}, nil
+	case NameOrthogonal:
+		return Orthogonal{}, nil
+	case NameZeros:
+		return Constant{}, nil
+	}
+	return nil, fmt.Errorf("Error: unknown initializer %q", name)
+}
+
+// Fans 函數會由權重的形狀計算 fanIn 與 fanOut：
+// 全連接層 [Out, In] 為 (In, Out)；卷積層 [OutC, InC, K, K] 為 (InC·K·K, OutC·K·K)；一維（例如偏差值）為 (n, n)。
+func Fans(shape []int) (fanIn, fanOut int) {
+	switch len(shape) {
+	case 0:
+		return 1, 1
+	case 1:
+		return shape[0], shape[0]
+	}
+	receptive := 1
+	for _, n := range shape[2:] {
+		receptive *= n
+	}
+	return shape[1] * receptive, shape[0] * receptive
+}
+
+// Fill 函數會以 src 建立亂數產生器，並依 t 的形狀計算 fanIn、fanOut 後以 init 初始化 t。
+// 相同種子的 src 會得到相同的權重。
+func Fill(init Initializer, t *mytensor.Tensor, src rand_fromgo.Source) (err error) {
+	fanIn, fanOut := Fans(t.Shape)
+	return init.Init(t, fanIn, fanOut, rand_fromgo.New(src))
+}
+
+// gain 函數會回傳 g，g 為 0 時回傳 1。
+func gain(g float64) float64 {
+	if g == 0 {
+		return 1
+	}
+	return g
+}
+
+// checkFans 函數會確認 fan 皆為正數。
+func checkFans(fans ...int) (err error) {
+	for _, f := range fans {
+		if f <= 0 {
+			return fmt.Errorf("Error: fan-in and fan-out must be positive, got %v", fans)
+		}
+	}
+	return nil
+}
+
+// uniform 函數會以 [-limit, limit) 的均勻分佈亂數填滿 t。
+func uniform(t *mytensor.Tensor, limit float64, rnd *rand_fromgo.Rand) {
+	for i := range t.Data {
+		t.Data[i] = (2*rnd.Float64() - 1) * limit
+	}
+}
+
+// normal 函數會以平均值 0、標準差 std 的常態分佈亂數填滿 t。
+func normal(t *mytensor.Tensor, std float64, rnd *rand_fromgo.Rand) {
+	for i := range t.Data {
+		t.Data[i] = rnd.Normal(0, std)
+	}
+}
+
+// endregion function
+
+// region method
+
+// Init 會以 [-Scale/fanIn, Scale/fanIn) 的均勻分佈亂數填滿 t。
+func (u UniformFanIn) Init(t *mytensor.Tensor, fanIn, fanOut int, rnd *rand_fromgo.Rand) (err error) {
+	if err = checkFans(fanIn); err != nil {
+		return err
+	}
+	scale := u.Scale
+	if scale == 0 {
+		scale = 2.4
+	}
+	uniform(t, scale/float64(fanIn), rnd)
+	return nil
+}
+
+// Init 會以 Xavier 均勻分佈亂數填滿 t。
+func (x XavierUniform) Init(t *mytensor.Tensor, fanIn, fanOut int, rnd *rand_fromgo.Rand) (err error) {
+	if err = checkFans(fanIn, fanOut); err != nil {
+		return err
+	}
+	uniform(t, gain(x.Gain)*math.Sqrt(6/float64(fanIn+fanOut)), rnd)
+	return nil
+}
+
+// Init 會以 Xavier 常態分佈亂數填滿 t。
+func (x XavierNormal) Init(t *mytensor.Tensor, fanIn, fanOut int, rnd *rand_fromgo.Rand) (err error) {
+	if err = checkFans(fanIn, fanOut); err != nil {
+		return err
+	}
+	normal(t, gain(x.Gain)*math.Sqrt(2/float64(fanIn+fanOut)), rnd)
+	return nil
+}
+
+// Init 會以 He 常態分佈亂數填滿 t。
+func (h HeNormal) Init(t *mytensor.Tensor, fanIn, fanOut int, rnd *rand_fromgo.Rand) (err error) {
+	if err = checkFans(fanIn); err != nil {
+		return err
+	}
+	normal(t, gain(h.Gain)*math.Sqrt(2/float64(fanIn)), rnd)
+	return nil
+}
+
+// Init 會先以標準常態亂數填滿 t，再以修正的 Gram-Schmidt 法將較短的一邊正交化並縮放成長度 Gain。
+func (o Orthogonal) Init(t *mytensor.Tensor, fanIn, fanOut int, rnd *rand_fromgo.Rand) (err error) {
+	if len(t.Shape) == 0 || t.Len() == 0 {
+		return fmt.Errorf("Error: cannot orthogonalize a tensor of shape %v", t.Shape)
+	}
+	rows := t.Shape[0]
+	cols := t.Len() / rows
+	normal(t, 1, rnd)
+	// 要正交化的 n 個向量：列數不大於行數時為各列，否則為各行；第 v 個向量的第 k 個元素位於 Data[v*step+k*stride]。
+	n, length, step, stride := rows, cols, cols, 1
+	if rows > cols {
+		n, length, step, stride = cols, rows, 1, cols
+	}
+	at := func(v, k int) *float64 { return &t.Data[v*step+k*stride] }
+	for v := 0; v < n; v++ {
+		for u := 0; u < v; u++ {
+			dot := 0.0
+			for k := 0; k < length; k++ {
+				dot += *at(v, k) * *at(u, k)
+			}
+			for k := 0; k < length; k++ {
+				*at(v, k) -= dot * *at(u, k)
+			}
+		}
+		norm := 0.0
+		for k := 0; k < length; k++ {
+			norm += *at(v, k) * *at(v, k)
+		}
+		norm = math.Sqrt(norm)
+		if norm == 0 {
+			return fmt.Errorf("Error: orthogonalization of shape %v failed", t.Shape)
+		}
+		for k := 0; k < length; k++ {
+			*at(v, k) /= norm
+		}
+	}
+	if g := gain(o.Gain); g != 1 {
+		for i := range t.Data {
+			t.Data[i] *= g
+		}
+	}
+	return nil
+}
+
+// Init 會將 t 的所有元素設為 Value。
+func (c Constant) Init(t *mytensor.Tensor, fanIn, fanOut int, rnd *rand_fromgo.Rand) (err error) {
+	t.Fill(c.Value)
+	return nil
+}
+
+// endregion method
@@ -0,0 +1,174 @@
+// How to use:
+//
+// 1. Testing
+// (1) > cd "%GOPATH%\src\github.com\oneleo\LeNetPractice\mAiLab_0003\myinit"
+// or (1) $ cd "$GOPATH/src/github.com/oneleo/LeNetPractice/mAiLab_0003/myinit"
+// (2) $> go test -v
+
+package myinit
+
+import (
+	"math"
+	"testing"
+
+	"github.com/oneleo/LeNetPractice/mAiLab_0002/rand_fromgo"
+	"github.com/oneleo/LeNetPractice/mAiLab_0003/mytensor"
+)
+
+// meanStd 函數會回傳 t 所有元素的平均值與標準差，以及絕對值的最大值。
+func meanStd(t *mytensor.Tensor) (mean, std, absMax float64) {
+	for _, v := range t.Data {
+		mean += v
+		absMax = math.Max(absMax, math.Abs(v))
+	}
+	mean /= float64(t.Len())
+	for _, v := range t.Data {
+		std += (v - mean) * (v - mean)
+	}
+	return mean, math.Sqrt(std / float64(t.Len())), absMax
+}
+
+// Test_Fans 是測試全連接層、卷積層與偏差值形狀的 fanIn、fanOut。
+func Test_Fans(t *testing.T) {
+	var tests = []struct {
+		shape  []int
+		fanIn  int
+		fanOut int
+	}{
+		{[]int{84, 120}, 120, 84},
+		{[]int{16, 6, 5, 5}, 150, 400},
+		{[]int{10}, 10, 10},
+		{nil, 1, 1},
+	}
+	for _, test := range tests {
+		if in, out := Fans(test.shape); in != test.fanIn || out != test.fanOut {
+			t.Errorf("Error Fans(%v) = (%d, %d), should be (%d, %d).", test.shape, in, out, test.fanIn, test.fanOut)
+		}
+	}
+}
+
+// Test_Distributions 是測試各初始化方式的平均值、標準差與範圍符合理論值。
+func Test_Distributions(t *testing.T) {
+	// 形狀 [200, 100, 3, 3]：fanIn = 900，fanOut = 1800。
+	shape := []int{200, 100, 3, 3}
+	uniformStd := func(limit float64) float64 { return limit / math.Sqrt(3) }
+	var tests = []struct {
+		name  string
+		init  Initializer
+		std   float64
+		limit float64 // 大於 0 時所有元素的絕對值必須小於等於 limit。
+	}{
+		{"UniformFanIn", UniformFanIn{}, uniformStd(2.4 / 900), 2.4 / 900},
+		{"UniformFanIn scale", UniformFanIn{Scale: 1}, uniformStd(1.0 / 900), 1.0 / 900},
+		{"XavierUniform", XavierUniform{}, uniformStd(math.Sqrt(6.0 / 2700)), math.Sqrt(6.0 / 2700)},
+		{"XavierUniform gain", XavierUniform{Gain: 2}, uniformStd(2 * math.Sqrt(6.0/2700)), 2 * math.Sqrt(6.0/2700)},
+		{"XavierNormal", XavierNormal{}, math.Sqrt(2.0 / 2700), 0},
+		{"HeNormal", HeNormal{}, math.Sqrt(2.0 / 900), 0},
+	}
+	for _, test := range tests {
+		w := mytensor.New(shape...)
+		if err := Fill(test.init, w, rand_fromgo.NewSource(1)); err != nil {
+			t.Fatal(err)
+		}
+		mean, std, absMax := meanStd(w)
+		// 18 萬個樣本，平均值的標準誤約為 std/424，標準差的相對誤差約 0.2%。
+		if math.Abs(mean) > 5*test.std/424 || math.Abs(std/test.std-1) > 0.02 {
+			t.Errorf("Error %s: mean %g, std %g, should be 0 and %g.", test.name, mean, std, test.std)
+		}
+		if test.limit > 0 && absMax > test.limit {
+			t.Errorf("Error %s: |w| up to %g, should be at most %g.", test.name, absMax, test.limit)
+		}
+	}
+}
+
+// Test_Orthogonal 是測試正交初始化的列（或行）互相正交且長度為 Gain。
+func Test_Orthogonal(t *testing.T) {
+	var tests = []struct {
+		shape []int
+		gain  float64
+	}{
+		{[]int{8, 20}, 0},
+		{[]int{20, 8}, 0},
+		{[]int{6, 2, 3, 3}, 1.5},
+		{[]int{10, 10}, 2},
+	}
+	for _, test := range tests {
+		w := mytensor.New(test.shape...)
+		if err := Fill(Orthogonal{Gain: test.gain}, w, rand_fromgo.NewSource(2)); err != nil {
+			t.Fatal(err)
+		}
+		g := test.gain
+		if g == 0 {
+			g = 1
+		}
+		rows := test.shape[0]
+		cols := w.Len() / rows
+		// 計算較短一邊的 Gram 矩陣：列數不大於行數時為 W·Wᵀ，否則為 Wᵀ·W，應等於 Gain² 乘上單位矩陣。
+		n, length := rows, cols
+		at := func(v, k int) float64 { return w.Data[v*cols+k] }
+		if rows > cols {
+			n, length = cols, rows
+			at = func(v, k int) float64 { return w.Data[k*cols+v] }
+		}
+		for a := 0; a < n; a++ {
+			for b := 0; b < n; b++ {
+				dot := 0.0
+				for k := 0; k < length; k++ {
+					dot += at(a, k) * at(b, k)
+				}
+				want := 0.0
+				if a == b {
+					want = g * g
+				}
+				if math.Abs(dot-want) > 1e-9 {
+					t.Fatalf("Error shape %v: Gram[%d][%d] = %g, should be %g.", test.shape, a, b, dot, want)
+				}
+			}
+		}
+	}
+}
+
+// Test_Reproducible 是測試相同種子的 Source 得到相同的權重，不同種子得到不同的權重。
+func Test_Reproducible(t *testing.T) {
+	for _, name := range []string{NameUniformFanIn, NameXavierUniform, NameXavierNormal, NameHeNormal, NameOrthogonal} {
+		init, err := ByName(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		a, b, c := mytensor.New(6, 5), mytensor.New(6, 5), mytensor.New(6, 5)
+		Fill(init, a, rand_fromgo.NewSource(3))
+		Fill(init, b, rand_fromgo.NewSource(3))
+		Fill(init, c, rand_fromgo.NewPCG(3, 4))
+		for i := range a.Data {
+			if a.Data[i] != b.Data[i] {
+				t.Fatalf("Error %s differs with the same seed.", name)
+			}
+		}
+		if a.Data[0] == c.Data[0] {
+			t.Errorf("Error %s gives the same weights for different sources.", name)
+		}
+	}
+}
+
+// Test_ConstantAndErrors 是測試常數初始化，以及不合法的名稱與 fan 會回傳錯誤。
+func Test_ConstantAndErrors(t *testing.T) {
+	w := mytensor.New(3, 4)
+	zeros, _ := ByName(NameZeros)
+	w.Fill(1)
+	if err := Fill(zeros, w, rand_fromgo.NewSource(1)); err != nil || w.Data[5] != 0 {
+		t.Errorf("Error zeros initializer: %v, %v.", w.Data, err)
+	}
+	if (Constant{Value: 0.1}).Init(w, 4, 3, nil); w.Data[11] != 0.1 {
+		t.Errorf("Error constant initializer: %v.", w.Data)
+	}
+	if _, err := ByName("glorot"); err == nil {
+		t.Errorf("Error unknown initializer name should return an error.")
+	}
+	rnd := rand_fromgo.New(rand_fromgo.NewSource(1))
+	if err := (XavierUniform{}).Init(w, 4, 0, rnd); err == nil {
+		t.Errorf("Error zero fan-out should return an error.")
+	}
+	if err := (HeNormal{}).Init(w, 0, 3, rnd); err == nil {
+		t.Errorf("Error zero fan-in should return an error.")
+	}
+}
@@ -0,0 +1,280 @@
+package myloader
+
+import (
+	"errors"
+	"image"
+	"io"
+	"math"
+	"sync"
+
+	"github.com/oneleo/LeNetPractice/mAiLab_0002/rand_fromgo"
+	"github.com/oneleo/LeNetPractice/mAiLab_0003/myaugment"
+	"github.com/oneleo/LeNetPractice/mAiLab_0003/mymnist"
+	"github.com/oneleo/LeNetPractice/mAiLab_0003/mytensor"
+)
+
+// region struct
+
+// Config 是 Loader 的設定。
+type Config struct {
+	// BatchSize 為每個 mini-batch 的樣本數。
+	BatchSize int
+	// Shuffle 為 true 時每個 epoch 以 Seed 與 epoch 編號決定的順序打亂資料，同一個 epoch 的順序永遠相同。
+	Shuffle bool
+	Seed    int64
+	// DropLast 為 true 時捨棄最後一個不足 BatchSize 的 mini-batch。
+	DropLast bool
+	// Rows、Cols 大於影像大小時，影像會以 mymnist.ImgAddZero 置中補零，LeNet-5 使用 32x32；為 0 時維持原大小。
+	Rows int
+	Cols int
+	// Std 為 0 時像素值由 0～255 縮放至 [0, 1]；否則再以 (v-Mean)/Std 標準化（Mean、Std 以 [0, 1] 的尺度計算，見 MeanStd）。
+	Mean float64
+	Std  float64
+	// Augment 不為空時，每張影像在轉成 Tensor 前依序套用這些變換。每個 mini-batch 使用各自不重疊的亂數子數列
+	// （見 rand_fromgo.Splitter），由 Seed 與 epoch 決定，因此結果與 Workers 的數目無關。
+	Augment []myaugment.Transform
+	// Workers 為同時準備 mini-batch 的 goroutine 數，小於 1 時視為 1。
+	Workers int
+	// Prefetch 為最多預先準備好的 mini-batch 數，小於 1 時視為 Workers。
+	Prefetch int
+}
+
+// Batch 是一個 mini-batch。
+type Batch struct {
+	// Index 為此 mini-batch 在 epoch 中的編號。
+	Index int
+	// Images 的形狀為 [N, 1, Rows, Cols]，第 i 筆樣本可以 Images.Slice(i) 取得。
+	Images *mytensor.Tensor
+	Labels []byte
+	// Indices 為每一筆樣本在資料集中的索引。
+	Indices []int
+}
+
+// Loader 會將 mymnist.Dataset 切成 mini-batch。
+type Loader struct {
+	Dataset *mymnist.Dataset
+	Config  Config
+	rows    int
+	cols    int
+}
+
+// Iterator 會依序回傳一個 epoch 的 mini-batch，並在背景以 goroutine 預先準備後續的 mini-batch。
+// 使用完畢（包含提早結束）後必須呼叫 Close。
+type Iterator struct {
+	pending chan chan result
+	done    chan struct{}
+	once    sync.Once
+	wg      sync.WaitGroup
+}
+
+// job 是一個要準備的 mini-batch。
+type job struct {
+	batch Batch
+	rnd   *rand_fromgo.Rand
+	out   chan result
+}
+
+// result 是準備好的 mini-batch 或錯誤。
+type result struct {
+	batch *Batch
+	err   error
+}
+
+// endregion struct
+
+// region function
+
+// New 函數會建立一個 Loader，資料集不能為空，且所有影像必須與第一張影像同大小。
+func New(ds *mymnist.Dataset, cfg Config) (l *Loader, err error) {
+	if cfg.BatchSize <= 0 {
+		return nil, errors.New("Error: batch size must be positive")
+	}
+	if ds.Len() == 0 || len(ds.Images) != ds.Len() {
+		return nil, errors.New("Error: dataset is empty or has mismatched images and labels")
+	}
+	if cfg.Workers < 1 {
+		cfg.Workers = 1
+	}
+	if cfg.Prefetch < 1 {
+		cfg.Prefetch = cfg.Workers
+	}
+	l = &Loader{Dataset: ds, Config: cfg}
+	b := ds.Images[0].Bounds()
+	l.rows, l.cols = b.Dy(), b.Dx()
+	if cfg.Rows > 0 || cfg.Cols > 0 {
+		if cfg.Rows < l.rows || cfg.Cols < l.cols {
+			return nil, errors.New("Error: Rows and Cols must not be smaller than the images")
+		}
+		l.rows, l.cols = cfg.Rows, cfg.Cols
+	}
+	return l, nil
+}
+
+// MeanStd 函數會回傳 ds 所有像素值縮放至 [0, 1] 後的平均值與標準差，供 Config.Mean、Config.Std 使用。
+func MeanStd(ds *mymnist.Dataset) (mean, std float64) {
+	n, sum, sq := 0, 0.0, 0.0
+	for i := range ds.Images {
+		for _, p := range ds.Images[i].Pix {
+			v := float64(p) / 255
+			sum += v
+			sq += v * v
+			n++
+		}
+	}
+	if n == 0 {
+		return 0, 0
+	}
+	mean = sum / float64(n)
+	return mean, math.Sqrt(math.Max(0, sq/float64(n)-mean*mean))
+}
+
+// epochSeed 函數會以 seed 與 epoch 混合出該 epoch 的種子（SplitMix64 的混合函數），相鄰 epoch 的種子互不相關。
+func epochSeed(seed int64, epoch int) int64 {
+	z := uint64(seed) + uint64(epoch+1)*0x9e3779b97f4a7c15
+	z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+	z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+	return int64(z ^ (z >> 31))
+}
+
+// endregion function
+
+// region method
+
+// Len 會回傳每個 epoch 的 mini-batch 數。
+func (l *Loader) Len() int {
+	n, size := l.Dataset.Len(), l.Config.BatchSize
+	if l.Config.DropLast {
+		return n / size
+	}
+	return (n + size - 1) / size
+}
+
+// Order 會回傳第 epoch 個 epoch 讀取資料的順序。
+func (l *Loader) Order(epoch int) (idx []int) {
+	idx = make([]int, l.Dataset.Len())
+	for i := range idx {
+		idx[i] = i
+	}
+	if l.Config.Shuffle {
+		rnd := rand_fromgo.New(rand_fromgo.NewSource(epochSeed(l.Config.Seed, epoch)))
+		rnd.Shuffle(len(idx), func(i, j int) { idx[i], idx[j] = idx[j], idx[i] })
+	}
+	return idx
+}
+
+// Epoch 會開始讀取第 epoch 個 epoch，回傳的 Iterator 會以 Config.Workers 個 goroutine 在背景準備 mini-batch。
+func (l *Loader) Epoch(epoch int) *Iterator {
+	it := &Iterator{
+		pending: make(chan chan result, l.Config.Prefetch),
+		done:    make(chan struct{}),
+	}
+	jobs := make(chan job)
+	for w := 0; w < l.Config.Workers; w++ {
+		it.wg.Add(1)
+		go func() {
+			defer it.wg.Done()
+			for j := range jobs {
+				b, err := l.build(j.batch, j.rnd)
+				j.out <- result{b, err}
+			}
+		}()
+	}
+
+	idx := l.Order(epoch)
+	// 每個 mini-batch 的擴增子數列依序由該 epoch 的 Splitter 分出，與 goroutine 的執行順序無關。
+	streams := rand_fromgo.NewSplitter(epochSeed(l.Config.Seed^0x5bd1e995, epoch))
+	it.wg.Add(1)
+	go func() {
+		defer it.wg.Done()
+		defer close(jobs)
+		defer close(it.pending)
+		size := l.Config.BatchSize
+		for b := 0; b < l.Len(); b++ {
+			end := (b + 1) * size
+			if end > len(idx) {
+				end = len(idx)
+			}
+			j := job{batch: Batch{Index: b, Indices: idx[b*size : end]}, out: make(chan result, 1)}
+			if len(l.Config.Augment) > 0 {
+				j.rnd = streams.Split()
+			}
+			// 先放入 pending 保留順序，pending 滿了代表預先準備的 mini-batch 已達 Prefetch 個。
+			select {
+			case it.pending <- j.out:
+			case <-it.done:
+				return
+			}
+			select {
+			case jobs <- j:
+			case <-it.done:
+				return
+			}
+		}
+	}()
+	return it
+}
+
+// build 會讀取 b.Indices 的影像與 label，以 rnd 套用擴增並正規化後組成 mini-batch。
+func (l *Loader) build(b Batch, rnd *rand_fromgo.Rand) (batch *Batch, err error) {
+	n, size := len(b.Indices), l.rows*l.cols
+	b.Images = mytensor.New(n, 1, l.rows, l.cols)
+	b.Labels = make([]byte, n)
+	for k, i := range b.Indices {
+		img := &l.Dataset.Images[i]
+		for _, t := range l.Config.Augment {
+			img = t.Apply(img, rnd)
+		}
+		if img.Bounds().Dy() != l.rows || img.Bounds().Dx() != l.cols {
+			if img, err = mymnist.ImgAddZero(*img, l.rows, l.cols); err != nil {
+				return nil, err
+			}
+		}
+		dst := b.Images.Data[k*size : (k+1)*size]
+		if err = l.normalize(img, dst); err != nil {
+			return nil, err
+		}
+		b.Labels[k] = l.Dataset.Labels[i]
+	}
+	return &b, nil
+}
+
+// normalize 會將 img 的像素值正規化後寫入 dst。
+func (l *Loader) normalize(img *image.Gray, dst []float64) (err error) {
+	bounds := img.Bounds()
+	if bounds.Dx()*bounds.Dy() != len(dst) {
+		return errors.New("Error: image size does not match the batch tensor")
+	}
+	for y := 0; y < bounds.Dy(); y++ {
+		for x := 0; x < bounds.Dx(); x++ {
+			v := float64(img.Pix[img.PixOffset(bounds.Min.X+x, bounds.Min.Y+y)]) / 255
+			if l.Config.Std > 0 {
+				v = (v - l.Config.Mean) / l.Config.Std
+			}
+			dst[y*bounds.Dx()+x] = v
+		}
+	}
+	return nil
+}
+
+// Next 會回傳下一個 mini-batch；epoch 結束時回傳 io.EOF。
+func (it *Iterator) Next() (b *Batch, err error) {
+	out, ok := <-it.pending
+	if !ok {
+		return nil, io.EOF
+	}
+	r := <-out
+	return r.batch, r.err
+}
+
+// Close 會停止背景的 goroutine 並等待它們結束，可以重複呼叫，但不能與 Next 同時呼叫。
+func (it *Iterator) Close() {
+	it.once.Do(func() {
+		close(it.done)
+		// 取出尚未讀取的 mini-batch，讓生產者不會卡在 pending 上。
+		for range it.pending {
+		}
+		it.wg.Wait()
+	})
+}
+
+// endregion method
@@ -0,0 +1,162 @@
+// How to use:
+//
+// 1. Testing
+// (1) > cd "%GOPATH%\src\github.com\oneleo\LeNetPractice\mAiLab_0003\myloader"
+// or (1) $ cd "$GOPATH/src/github.com/oneleo/LeNetPractice/mAiLab_0003/myloader"
+// (2) $> go test -v
+
+package myloader
+
+import (
+	"image"
+	"io"
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/oneleo/LeNetPractice/mAiLab_0003/myaugment"
+	"github.com/oneleo/LeNetPractice/mAiLab_0003/mymnist"
+)
+
+// testDataset 會回傳 n 張 28x28 的影像，第 i 張的像素值皆為 i，label 為 i % 10。
+func testDataset(n int) *mymnist.Dataset {
+	ds := &mymnist.Dataset{}
+	for i := 0; i < n; i++ {
+		img := image.NewGray(image.Rect(0, 0, 28, 28))
+		for k := range img.Pix {
+			img.Pix[k] = uint8(i)
+		}
+		ds.Images = append(ds.Images, *img)
+		ds.Labels = append(ds.Labels, byte(i%10))
+	}
+	return ds
+}
+
+// collect 會讀取一個 epoch 所有的 mini-batch。
+func collect(t *testing.T, l *Loader, epoch int) (batches []*Batch) {
+	it := l.Epoch(epoch)
+	defer it.Close()
+	for {
+		b, err := it.Next()
+		if err == io.EOF {
+			return batches
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		batches = append(batches, b)
+	}
+}
+
+// Test_Batches 是測試 mini-batch 的個數、大小、順序、正規化與 DropLast。
+func Test_Batches(t *testing.T) {
+	var tests = []struct {
+		dropLast bool
+		batches  int
+		last     int
+	}{
+		{false, 4, 1},
+		{true, 3, 3},
+	}
+	for _, test := range tests {
+		l, err := New(testDataset(10), Config{BatchSize: 3, DropLast: test.dropLast, Rows: 32, Cols: 32, Workers: 3})
+		if err != nil {
+			t.Fatal(err)
+		}
+		batches := collect(t, l, 0)
+		if len(batches) != test.batches || l.Len() != test.batches {
+			t.Fatalf("Error got %d batches, should be %d.", len(batches), test.batches)
+		}
+		last := batches[len(batches)-1]
+		if !reflect.DeepEqual(last.Images.Shape, []int{test.last, 1, 32, 32}) || len(last.Labels) != test.last {
+			t.Errorf("Error last batch shape %v.", last.Images.Shape)
+		}
+		for n, b := range batches {
+			if b.Index != n || b.Indices[0] != 3*n {
+				t.Errorf("Error batch %d has index %d and starts at %d.", n, b.Index, b.Indices[0])
+			}
+			// 第 i 張影像補零後，中間的像素為 i/255，角落為 0。
+			s, _ := b.Images.Slice(1 % len(b.Labels))
+			i := b.Indices[1%len(b.Labels)]
+			if s.At(0, 16, 16) != float64(i)/255 || s.At(0, 0, 0) != 0 || b.Labels[1%len(b.Labels)] != byte(i%10) {
+				t.Errorf("Error sample %d has value %g.", i, s.At(0, 16, 16))
+			}
+		}
+	}
+}
+
+// Test_Shuffle 是測試同一個 epoch 的順序固定、不同 epoch 的順序不同，且每個 epoch 都恰好讀取每筆資料一次。
+func Test_Shuffle(t *testing.T) {
+	l, _ := New(testDataset(50), Config{BatchSize: 8, Shuffle: true, Seed: 3, Workers: 4, Prefetch: 2})
+	order := func(epoch int) (idx []int) {
+		for _, b := range collect(t, l, epoch) {
+			idx = append(idx, b.Indices...)
+		}
+		return idx
+	}
+	a, b, c := order(1), order(1), order(2)
+	if !reflect.DeepEqual(a, b) || !reflect.DeepEqual(a, l.Order(1)) {
+		t.Error("Error the same epoch gave different orders.")
+	}
+	if reflect.DeepEqual(a, c) {
+		t.Error("Error different epochs gave the same order.")
+	}
+	seen := make([]bool, 50)
+	for _, i := range c {
+		seen[i] = true
+	}
+	for i, ok := range seen {
+		if !ok {
+			t.Errorf("Error sample %d was not loaded.", i)
+		}
+	}
+}
+
+// Test_Augment 是測試擴增的結果與 Workers 數目無關。
+func Test_Augment(t *testing.T) {
+	run := func(workers int) (data []float64) {
+		l, _ := New(testDataset(20), Config{BatchSize: 4, Shuffle: true, Seed: 5, Workers: workers,
+			Augment: []myaugment.Transform{&myaugment.Noise{Sigma: 20}}})
+		for _, b := range collect(t, l, 0) {
+			data = append(data, b.Images.Data...)
+		}
+		return data
+	}
+	if !reflect.DeepEqual(run(1), run(4)) {
+		t.Error("Error augmentation depends on the number of workers.")
+	}
+}
+
+// Test_MeanStd 是測試標準化後的資料平均值為 0、標準差為 1。
+func Test_MeanStd(t *testing.T) {
+	ds := testDataset(10)
+	mean, std := MeanStd(ds)
+	l, _ := New(ds, Config{BatchSize: 10, Mean: mean, Std: std})
+	b := collect(t, l, 0)[0]
+	m, sq := 0.0, 0.0
+	for _, v := range b.Images.Data {
+		m += v
+		sq += v * v
+	}
+	m /= float64(b.Images.Len())
+	if s := math.Sqrt(sq/float64(b.Images.Len()) - m*m); math.Abs(m) > 1e-9 || math.Abs(s-1) > 1e-9 {
+		t.Errorf("Error normalized mean %g std %g.", m, s)
+	}
+}
+
+// Test_Close 是測試提早結束時 Close 會停止背景的 goroutine，且之後 Next 回傳 io.EOF。
+func Test_Close(t *testing.T) {
+	l, _ := New(testDataset(100), Config{BatchSize: 1, Workers: 2, Prefetch: 4})
+	it := l.Epoch(0)
+	if _, err := it.Next(); err != nil {
+		t.Fatal(err)
+	}
+	it.Close()
+	it.Close()
+	if _, err := it.Next(); err != io.EOF {
+		t.Errorf("Error Next after Close returned %v, should be io.EOF.", err)
+	}
+	if _, err := New(testDataset(1), Config{BatchSize: 1, Rows: 20, Cols: 20}); err == nil {
+		t.Error("Error Rows smaller than the images should return an error.")
+	}
+}
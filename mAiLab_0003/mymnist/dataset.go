@@ -0,0 +1,47 @@
+package mymnist
+
+import (
+	"errors"
+	"image"
+)
+
+// Dataset 是成對的 MNIST 影像與 label，Images[i] 的正確答案為 Labels[i]。
+type Dataset struct {
+	Images []image.Gray
+	Labels []byte
+}
+
+// ReadMnistDataset 函數會讀入 *images.idx?-ubyte 與 *labels.idx?-ubyte 兩個檔案，
+// 並確認影像與 label 的個數相同。
+func ReadMnistDataset(imgSrc, lblSrc string) (ds *Dataset, err error) {
+	imgs, err := ReadMnistImages(imgSrc)
+	if err != nil {
+		return nil, err
+	}
+	lbls, err := ReadMnistLabels(lblSrc)
+	if err != nil {
+		return nil, err
+	}
+	return NewDataset(imgs, lbls)
+}
+
+// NewDataset 函數會以 imgs 與 lbls 建立 Dataset，兩者個數不同時回傳錯誤。
+func NewDataset(imgs []image.Gray, lbls []byte) (ds *Dataset, err error) {
+	if len(imgs) != len(lbls) {
+		return nil, errors.New("Error: number of images and labels are not same")
+	}
+	return &Dataset{Images: imgs, Labels: lbls}, nil
+}
+
+// Len 會回傳資料筆數。
+func (ds *Dataset) Len() int { return len(ds.Labels) }
+
+// Subset 會回傳只包含 idx 所指資料的新 Dataset，影像像素與原 Dataset 共用。
+func (ds *Dataset) Subset(idx []int) *Dataset {
+	sub := &Dataset{Images: make([]image.Gray, len(idx)), Labels: make([]byte, len(idx))}
+	for n, i := range idx {
+		sub.Images[n] = ds.Images[i]
+		sub.Labels[n] = ds.Labels[i]
+	}
+	return sub
+}
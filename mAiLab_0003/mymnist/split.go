@@ -0,0 +1,149 @@
+package mymnist
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/oneleo/LeNetPractice/mAiLab_0002/rand_fromgo"
+)
+
+// region struct
+
+// Split 是一組訓練與驗證資料的索引，可以寫入檔案以便重現同樣的實驗。
+type Split struct {
+	// Seed 為產生此切分所使用的種子，Fold 為 k-fold 中的第幾折（非 k-fold 時為 0）。
+	Seed  int64 `json:"seed"`
+	Fold  int   `json:"fold"`
+	Train []int `json:"train"`
+	Val   []int `json:"val"`
+}
+
+// endregion struct
+
+// region function
+
+// byLabel 函數會將 lbls 的索引依 label 分組，並以 rnd 打亂每一組的順序；分組依 label 由小到大排列。
+func byLabel(lbls []byte, rnd *rand_fromgo.Rand) (groups [][]int) {
+	var idx [256][]int
+	for i, l := range lbls {
+		idx[l] = append(idx[l], i)
+	}
+	for _, g := range idx {
+		if len(g) == 0 {
+			continue
+		}
+		rnd.Shuffle(len(g), func(i, j int) { g[i], g[j] = g[j], g[i] })
+		groups = append(groups, g)
+	}
+	return groups
+}
+
+// StratifiedSplit 函數會將 lbls 的索引切成訓練與驗證兩組，每一個 label 各取約 valFrac 的比例做為驗證資料，
+// 使兩組的 label 分佈與原資料相同。相同的 seed 會得到相同的切分，回傳的索引由小到大排列。
+func StratifiedSplit(lbls []byte, valFrac float64, seed int64) (s *Split, err error) {
+	if valFrac < 0 || valFrac >= 1 {
+		return nil, fmt.Errorf("Error: validation fraction %g out of range [0, 1)", valFrac)
+	}
+	s = &Split{Seed: seed}
+	for _, g := range byLabel(lbls, rand_fromgo.New(rand_fromgo.NewSource(seed))) {
+		n := int(math.Round(valFrac * float64(len(g))))
+		s.Val = append(s.Val, g[:n]...)
+		s.Train = append(s.Train, g[n:]...)
+	}
+	sort.Ints(s.Train)
+	sort.Ints(s.Val)
+	return s, nil
+}
+
+// KFold 函數會將 lbls 的索引分成 k 折，回傳 k 組切分，第 f 組以第 f 折為驗證資料、其餘為訓練資料。
+// stratified 為 true 時每一個 label 會平均分配到各折，否則先整體打亂再依序切成 k 份。
+func KFold(lbls []byte, k int, seed int64, stratified bool) (splits []Split, err error) {
+	if k < 2 || k > len(lbls) {
+		return nil, fmt.Errorf("Error: k = %d out of range [2, %d]", k, len(lbls))
+	}
+	rnd := rand_fromgo.New(rand_fromgo.NewSource(seed))
+	fold := make([]int, len(lbls))
+	if stratified {
+		// 將每一個 label 打亂後的索引輪流發給各折，並延續上一個 label 的位置，讓各折大小相差不超過 1。
+		next := 0
+		for _, g := range byLabel(lbls, rnd) {
+			for _, i := range g {
+				fold[i] = next % k
+				next++
+			}
+		}
+	} else {
+		for n, i := range rnd.Perm(len(lbls)) {
+			fold[i] = n * k / len(lbls)
+		}
+	}
+	splits = make([]Split, k)
+	for f := range splits {
+		splits[f] = Split{Seed: seed, Fold: f}
+	}
+	for i, f := range fold {
+		for g := range splits {
+			if g == f {
+				splits[g].Val = append(splits[g].Val, i)
+			} else {
+				splits[g].Train = append(splits[g].Train, i)
+			}
+		}
+	}
+	return splits, nil
+}
+
+// WriteSplits 函數會將 splits 以 JSON 格式寫入 dstFile。
+func WriteSplits(dstFile string, splits []Split) (err error) {
+	// 根據作業系統調整路徑的正反鈄線，以及將目錄路徑轉換成絕對路徑。
+	dstFile, err = filepath.Abs(dstFile)
+	if err != nil {
+		fmt.Println("Error while finding absolute path", dstFile, "-", err)
+		return err
+	}
+	data, err := json.MarshalIndent(splits, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err = os.WriteFile(dstFile, append(data, '\n'), 0644); err != nil {
+		fmt.Println("Error while writing", dstFile, "-", err)
+		return err
+	}
+	return nil
+}
+
+// ReadSplits 函數會讀入 WriteSplits 寫入的檔案。
+func ReadSplits(srcFile string) (splits []Split, err error) {
+	data, err := os.ReadFile(srcFile)
+	if err != nil {
+		fmt.Println("Error while opening", srcFile, "-", err)
+		return nil, err
+	}
+	if err = json.Unmarshal(data, &splits); err != nil {
+		return nil, err
+	}
+	return splits, nil
+}
+
+// endregion function
+
+// region method
+
+// Apply 會以 s 的索引從 ds 取出訓練與驗證資料，索引超出範圍時回傳錯誤。
+func (s *Split) Apply(ds *Dataset) (train, val *Dataset, err error) {
+	for _, idx := range [][]int{s.Train, s.Val} {
+		for _, i := range idx {
+			if i < 0 || i >= ds.Len() {
+				return nil, nil, errors.New("Error: split index out of range of the dataset")
+			}
+		}
+	}
+	return ds.Subset(s.Train), ds.Subset(s.Val), nil
+}
+
+// endregion method
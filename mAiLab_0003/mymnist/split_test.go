@@ -0,0 +1,121 @@
+// How to use:
+//
+// 1. Testing
+// (1) > cd "%GOPATH%\src\github.com\oneleo\LeNetPractice\mAiLab_0003\mymnist"
+// or (1) $ cd "$GOPATH/src/github.com/oneleo/LeNetPractice/mAiLab_0003/mymnist"
+// (2) $> go test -v
+
+package mymnist
+
+import (
+	"image"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// testLabels 會回傳 label 0 有 100 筆、label 1 有 50 筆、label 2 有 10 筆的測試資料。
+func testLabels() (lbls []byte) {
+	for l, n := range []int{100, 50, 10} {
+		for i := 0; i < n; i++ {
+			lbls = append(lbls, byte(l))
+		}
+	}
+	return lbls
+}
+
+// count 會回傳 idx 中每一個 label 的個數。
+func count(lbls []byte, idx []int) (c [3]int) {
+	for _, i := range idx {
+		c[lbls[i]]++
+	}
+	return c
+}
+
+// Test_StratifiedSplit 是測試每一個 label 的驗證比例、兩組不重疊，以及相同種子得到相同切分。
+func Test_StratifiedSplit(t *testing.T) {
+	lbls := testLabels()
+	s, err := StratifiedSplit(lbls, 0.2, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c := count(lbls, s.Val); c != [3]int{20, 10, 2} {
+		t.Errorf("Error validation label counts %v, should be [20 10 2].", c)
+	}
+	seen := make([]bool, len(lbls))
+	for _, i := range append(append([]int(nil), s.Train...), s.Val...) {
+		if seen[i] {
+			t.Fatalf("Error index %d appears twice.", i)
+		}
+		seen[i] = true
+	}
+	if len(s.Train)+len(s.Val) != len(lbls) {
+		t.Errorf("Error split covers %d of %d samples.", len(s.Train)+len(s.Val), len(lbls))
+	}
+	again, _ := StratifiedSplit(lbls, 0.2, 1)
+	other, _ := StratifiedSplit(lbls, 0.2, 2)
+	if !reflect.DeepEqual(s, again) || reflect.DeepEqual(s.Val, other.Val) {
+		t.Error("Error split is not determined by the seed.")
+	}
+	if _, err = StratifiedSplit(lbls, 1, 1); err == nil {
+		t.Error("Error validation fraction 1 should return an error.")
+	}
+}
+
+// Test_KFold 是測試每一筆資料恰好在一折中做為驗證資料，且分層時每一折的 label 分佈相近。
+func Test_KFold(t *testing.T) {
+	lbls := testLabels()
+	for _, stratified := range []bool{true, false} {
+		splits, err := KFold(lbls, 5, 3, stratified)
+		if err != nil {
+			t.Fatal(err)
+		}
+		times := make([]int, len(lbls))
+		for f, s := range splits {
+			if s.Fold != f || len(s.Val) != 32 || len(s.Train) != 128 {
+				t.Errorf("Error fold %d has %d train and %d validation samples.", f, len(s.Train), len(s.Val))
+			}
+			for _, i := range s.Val {
+				times[i]++
+			}
+			if c := count(lbls, s.Val); stratified && c != [3]int{20, 10, 2} {
+				t.Errorf("Error stratified fold %d has label counts %v.", f, c)
+			}
+		}
+		for i, n := range times {
+			if n != 1 {
+				t.Fatalf("Error sample %d is validated %d times.", i, n)
+			}
+		}
+	}
+	if _, err := KFold(lbls, 1, 0, true); err == nil {
+		t.Error("Error k = 1 should return an error.")
+	}
+}
+
+// Test_Splits 是測試切分寫入檔案後可以讀回，並以 Apply 取出資料。
+func Test_Splits(t *testing.T) {
+	lbls := testLabels()
+	splits, _ := KFold(lbls, 4, 5, true)
+	dst := filepath.Join(t.TempDir(), "folds.json")
+	if err := WriteSplits(dst, splits); err != nil {
+		t.Fatal(err)
+	}
+	got, err := ReadSplits(dst)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, splits) {
+		t.Error("Error splits read back differ from the written ones.")
+	}
+
+	ds := &Dataset{Images: make([]image.Gray, len(lbls)), Labels: lbls}
+	train, val, err := got[0].Apply(ds)
+	if err != nil || train.Len() != 120 || val.Len() != 40 {
+		t.Errorf("Error Apply returned %d train and %d validation samples, %v.", train.Len(), val.Len(), err)
+	}
+	bad := Split{Train: []int{len(lbls)}}
+	if _, _, err = bad.Apply(ds); err == nil {
+		t.Error("Error out-of-range index should return an error.")
+	}
+}
@@ -0,0 +1,318 @@
+package mynetwork
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/oneleo/LeNetPractice/mAiLab_0003/mytensor"
+)
+
+// LeCun 在 LeNet 論文中使用的縮放 tanh：f(x) = A*tanh(S*x)，A = 1.7159，S = 2/3，
+// 使得 f(1) = 1、f(-1) = -1。
+const (
+	LeCunA = 1.7159
+	LeCunS = 2.0 / 3.0
+)
+
+// region struct
+
+// Activation 是逐元素套用激活函數的層，輸出與輸入形狀相同。
+type Activation struct {
+	Name string
+
+	// f 為激活函數；df 為其導數，以輸入 x 與輸出 y 表示，方便重用 Forward 的結果。
+	f  func(x float64) float64
+	df func(x, y float64) float64
+
+	in  *mytensor.Tensor
+	out *mytensor.Tensor
+}
+
+// Softmax 是將輸入轉換成機率分佈的層，輸入與輸出形狀皆為 [N]。
+type Softmax struct {
+	out *mytensor.Tensor
+}
+
+// LogSoftmax 是輸出 log(softmax(x)) 的層，比先算 Softmax 再取 log 更穩定。
+type LogSoftmax struct {
+	out *mytensor.Tensor
+}
+
+// endregion struct
+
+// region function
+
+// NewSigmoid 函數會建立 f(x) = 1/(1+e^-x) 的激活層。
+func NewSigmoid() *Activation {
+	return &Activation{
+		Name: "sigmoid",
+		f:    Sigmoid,
+		df:   func(x, y float64) float64 { return y * (1 - y) },
+	}
+}
+
+// NewTanh 函數會建立 f(x) = tanh(x) 的激活層。
+func NewTanh() *Activation {
+	return &Activation{
+		Name: "tanh",
+		f:    math.Tanh,
+		df:   func(x, y float64) float64 { return 1 - y*y },
+	}
+}
+
+// NewLeCunTanh 函數會建立 LeNet 論文中 f(x) = 1.7159*tanh(2x/3) 的激活層。
+func NewLeCunTanh() *Activation {
+	return &Activation{
+		Name: "lecun_tanh",
+		f:    func(x float64) float64 { return LeCunA * math.Tanh(LeCunS*x) },
+		// f'(x) = A*S*(1-tanh²(Sx)) = S/A*(A²-y²)。
+		df: func(x, y float64) float64 { return LeCunS / LeCunA * (LeCunA*LeCunA - y*y) },
+	}
+}
+
+// NewReLU 函數會建立 f(x) = max(0, x) 的激活層。
+func NewReLU() *Activation {
+	return NewLeakyReLU(0)
+}
+
+// NewLeakyReLU 函數會建立 x < 0 時 f(x) = alpha*x 的激活層。
+func NewLeakyReLU(alpha float64) *Activation {
+	name := "relu"
+	if alpha != 0 {
+		name = fmt.Sprintf("leaky_relu(%g)", alpha)
+	}
+	return &Activation{
+		Name: name,
+		f: func(x float64) float64 {
+			if x < 0 {
+				return alpha * x
+			}
+			return x
+		},
+		df: func(x, y float64) float64 {
+			if x < 0 {
+				return alpha
+			}
+			return 1
+		},
+	}
+}
+
+// NewSoftmax 函數會建立一個 Softmax 層。
+func NewSoftmax() *Softmax { return &Softmax{} }
+
+// NewLogSoftmax 函數會建立一個 LogSoftmax 層。
+func NewLogSoftmax() *LogSoftmax { return &LogSoftmax{} }
+
+// Sigmoid 函數會回傳 1/(1+e^-x)，並避免 x 為極大負數時 e^-x 溢位。
+func Sigmoid(x float64) float64 {
+	if x >= 0 {
+		return 1 / (1 + math.Exp(-x))
+	}
+	e := math.Exp(x)
+	return e / (1 + e)
+}
+
+// SoftmaxOf 函數會回傳 x 的 softmax 機率分佈。
+// 計算前會先減去最大值，使 e^x 不會溢位。
+func SoftmaxOf(x []float64) (p []float64) {
+	max := maxOf(x)
+	p = make([]float64, len(x))
+	sum := 0.0
+	for i, v := range x {
+		p[i] = math.Exp(v - max)
+		sum += p[i]
+	}
+	for i := range p {
+		p[i] /= sum
+	}
+	return p
+}
+
+// LogSoftmaxOf 函數會回傳 x 的 log-softmax，即 x - logsumexp(x)。
+func LogSoftmaxOf(x []float64) (lp []float64) {
+	lse := LogSumExp(x)
+	lp = make([]float64, len(x))
+	for i, v := range x {
+		lp[i] = v - lse
+	}
+	return lp
+}
+
+// LogSumExp 函數會以 max + log(Σ e^(x-max)) 穩定地計算 log(Σ e^x)。
+func LogSumExp(x []float64) float64 {
+	max := maxOf(x)
+	if math.IsInf(max, 0) {
+		return max
+	}
+	sum := 0.0
+	for _, v := range x {
+		sum += math.Exp(v - max)
+	}
+	return max + math.Log(sum)
+}
+
+// maxOf 函數會回傳 x 的最大值，x 為空時回傳負無限大。
+func maxOf(x []float64) float64 {
+	max := math.Inf(-1)
+	for _, v := range x {
+		if v > max {
+			max = v
+		}
+	}
+	return max
+}
+
+// endregion function
+
+// region method
+
+// Forward 會將激活函數逐元素套用在 in 上。
+func (l *Activation) Forward(in *mytensor.Tensor) (out *mytensor.Tensor, err error) {
+	l.in = in
+	l.out = mytensor.New(in.Shape...)
+	for i, x := range in.Data {
+		l.out.Data[i] = l.f(x)
+	}
+	return l.out, nil
+}
+
+// Backward 會回傳 gradOut 逐元素乘上激活函數的導數。
+func (l *Activation) Backward(gradOut *mytensor.Tensor) (gradIn *mytensor.Tensor, err error) {
+	if l.in == nil {
+		return nil, fmt.Errorf("Error: %s Backward called before Forward", l.Name)
+	}
+	if err = checkShape(l.Name, gradOut, l.out); err != nil {
+		return nil, err
+	}
+	gradIn = mytensor.New(gradOut.Shape...)
+	for i, g := range gradOut.Data {
+		gradIn.Data[i] = g * l.df(l.in.Data[i], l.out.Data[i])
+	}
+	return gradIn, nil
+}
+
+// Backward2 會回傳 hessOut 逐元素乘上激活函數導數的平方 f'(x)²，忽略激活函數二次導數的項。
+func (l *Activation) Backward2(hessOut *mytensor.Tensor) (hessIn *mytensor.Tensor, err error) {
+	if l.in == nil {
+		return nil, fmt.Errorf("Error: %s Backward2 called before Forward", l.Name)
+	}
+	if err = checkShape(l.Name, hessOut, l.out); err != nil {
+		return nil, err
+	}
+	hessIn = mytensor.New(hessOut.Shape...)
+	for i, h := range hessOut.Data {
+		d := l.df(l.in.Data[i], l.out.Data[i])
+		hessIn.Data[i] = h * d * d
+	}
+	return hessIn, nil
+}
+
+// Params 會回傳 nil，因為激活層沒有可訓練參數。
+func (l *Activation) Params() []*Param { return nil }
+
+// Forward 會回傳 in 的 softmax 機率分佈。
+func (l *Softmax) Forward(in *mytensor.Tensor) (out *mytensor.Tensor, err error) {
+	if err = checkDims("Softmax", in, 1); err != nil {
+		return nil, err
+	}
+	l.out, _ = mytensor.FromSlice(SoftmaxOf(in.Data), in.Len())
+	return l.out, nil
+}
+
+// Backward 會以 softmax 的 Jacobian 計算輸入梯度：gradIn_i = y_i * (g_i - Σ g_j*y_j)。
+func (l *Softmax) Backward(gradOut *mytensor.Tensor) (gradIn *mytensor.Tensor, err error) {
+	if l.out == nil {
+		return nil, fmt.Errorf("Error: Softmax Backward called before Forward")
+	}
+	if err = checkShape("Softmax", gradOut, l.out); err != nil {
+		return nil, err
+	}
+	dot := 0.0
+	for i, g := range gradOut.Data {
+		dot += g * l.out.Data[i]
+	}
+	gradIn = mytensor.New(gradOut.Shape...)
+	for i, g := range gradOut.Data {
+		gradIn.Data[i] = l.out.Data[i] * (g - dot)
+	}
+	return gradIn, nil
+}
+
+// Backward2 會以 Jacobian 元素的平方 J_ji² = y_j²(δ_ij - y_i)² 傳回二次導數，
+// 整理後為 hessIn_i = y_i² * (h_i*(1-2y_i) + Σ_j y_j²*h_j)。
+func (l *Softmax) Backward2(hessOut *mytensor.Tensor) (hessIn *mytensor.Tensor, err error) {
+	if l.out == nil {
+		return nil, fmt.Errorf("Error: Softmax Backward2 called before Forward")
+	}
+	if err = checkShape("Softmax", hessOut, l.out); err != nil {
+		return nil, err
+	}
+	sum := 0.0
+	for j, h := range hessOut.Data {
+		sum += l.out.Data[j] * l.out.Data[j] * h
+	}
+	hessIn = mytensor.New(hessOut.Shape...)
+	for i, h := range hessOut.Data {
+		y := l.out.Data[i]
+		hessIn.Data[i] = y * y * (h*(1-2*y) + sum)
+	}
+	return hessIn, nil
+}
+
+// Params 會回傳 nil，因為 Softmax 層沒有可訓練參數。
+func (l *Softmax) Params() []*Param { return nil }
+
+// Forward 會回傳 in 的 log-softmax。
+func (l *LogSoftmax) Forward(in *mytensor.Tensor) (out *mytensor.Tensor, err error) {
+	if err = checkDims("LogSoftmax", in, 1); err != nil {
+		return nil, err
+	}
+	l.out, _ = mytensor.FromSlice(LogSoftmaxOf(in.Data), in.Len())
+	return l.out, nil
+}
+
+// Backward 會計算輸入梯度：gradIn_i = g_i - softmax_i * Σ g_j。
+func (l *LogSoftmax) Backward(gradOut *mytensor.Tensor) (gradIn *mytensor.Tensor, err error) {
+	if l.out == nil {
+		return nil, fmt.Errorf("Error: LogSoftmax Backward called before Forward")
+	}
+	if err = checkShape("LogSoftmax", gradOut, l.out); err != nil {
+		return nil, err
+	}
+	sum := 0.0
+	for _, g := range gradOut.Data {
+		sum += g
+	}
+	gradIn = mytensor.New(gradOut.Shape...)
+	for i, g := range gradOut.Data {
+		gradIn.Data[i] = g - math.Exp(l.out.Data[i])*sum
+	}
+	return gradIn, nil
+}
+
+// Backward2 會以 Jacobian 元素的平方 J_ji² = (δ_ij - p_i)² 傳回二次導數，p 為 softmax 機率，
+// 整理後為 hessIn_i = h_i*(1-2p_i) + p_i² * Σ_j h_j。
+func (l *LogSoftmax) Backward2(hessOut *mytensor.Tensor) (hessIn *mytensor.Tensor, err error) {
+	if l.out == nil {
+		return nil, fmt.Errorf("Error: LogSoftmax Backward2 called before Forward")
+	}
+	if err = checkShape("LogSoftmax", hessOut, l.out); err != nil {
+		return nil, err
+	}
+	sum := 0.0
+	for _, h := range hessOut.Data {
+		sum += h
+	}
+	hessIn = mytensor.New(hessOut.Shape...)
+	for i, h := range hessOut.Data {
+		p := math.Exp(l.out.Data[i])
+		hessIn.Data[i] = h*(1-2*p) + p*p*sum
+	}
+	return hessIn, nil
+}
+
+// Params 會回傳 nil，因為 LogSoftmax 層沒有可訓練參數。
+func (l *LogSoftmax) Params() []*Param { return nil }
+
+// endregion method
@@ -0,0 +1,73 @@
+package mynetwork
+
+import (
+	"math"
+	"testing"
+
+	"github.com/oneleo/LeNetPractice/mAiLab_0002/rand_fromgo"
+	"github.com/oneleo/LeNetPractice/mAiLab_0003/mytensor"
+)
+
+// randomInput 會回傳一個形狀為 shape、元素介於 [-2, 2) 的測試輸入。
+func randomInput(seed int64, shape ...int) *mytensor.Tensor {
+	rnd := rand_fromgo.New(rand_fromgo.NewSource(seed))
+	in := mytensor.New(shape...)
+	for i := range in.Data {
+		in.Data[i] = 4*rnd.Float64() - 2
+	}
+	return in
+}
+
+// Test_ActivationGrad 是以數值梯度檢查各激活層的 Backward。
+func Test_ActivationGrad(t *testing.T) {
+	var tests = []Layer{
+		NewSigmoid(),
+		NewTanh(),
+		NewLeCunTanh(),
+		NewReLU(),
+		NewLeakyReLU(0.01),
+		NewSoftmax(),
+		NewLogSoftmax(),
+	}
+	for _, l := range tests {
+		checkLayerGrad(t, l, randomInput(1, 10), 1e-6)
+	}
+}
+
+// Test_DenseGrad 是以數值梯度檢查全連接層對輸入、權重與偏差值的梯度。
+func Test_DenseGrad(t *testing.T) {
+	l, err := NewDense(12, 5, rand_fromgo.New(rand_fromgo.NewSource(3)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkLayerGrad(t, l, randomInput(2, 3, 2, 2), 1e-6)
+	// 大小不是正數時回傳錯誤，而不是留下全為 0 的權重。
+	for _, size := range [][2]int{{0, 5}, {12, 0}, {-1, 5}} {
+		if _, err = NewDense(size[0], size[1], rand_fromgo.New(rand_fromgo.NewSource(3))); err == nil {
+			t.Errorf("Error dense layer %dx%d should return an error.", size[0], size[1])
+		}
+	}
+}
+
+// Test_LeCunTanh 是測試縮放 tanh 滿足 f(±1) = ±1。
+func Test_LeCunTanh(t *testing.T) {
+	l := NewLeCunTanh()
+	in, _ := mytensor.FromSlice([]float64{1, -1}, 2)
+	out, _ := l.Forward(in)
+	if math.Abs(out.Data[0]-1) > 1e-4 || math.Abs(out.Data[1]+1) > 1e-4 {
+		t.Errorf("Error f(1) = %g, f(-1) = %g, should be about 1 and -1.", out.Data[0], out.Data[1])
+	}
+}
+
+// Test_SoftmaxStable 是測試極大的輸入不會讓 softmax 與 log-softmax 溢位。
+func Test_SoftmaxStable(t *testing.T) {
+	x := []float64{1000, 1000, -1000}
+	p := SoftmaxOf(x)
+	lp := LogSoftmaxOf(x)
+	if math.Abs(p[0]-0.5) > 1e-12 || p[2] != 0 {
+		t.Errorf("Error softmax = %v.", p)
+	}
+	if math.Abs(lp[0]+math.Ln2) > 1e-12 || math.IsInf(lp[2], 0) || math.IsNaN(lp[2]) {
+		t.Errorf("Error log-softmax = %v.", lp)
+	}
+}
@@ -0,0 +1,259 @@
+package mynetwork
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/oneleo/LeNetPractice/mAiLab_0002/rand_fromgo"
+	"github.com/oneleo/LeNetPractice/mAiLab_0003/myinit"
+	"github.com/oneleo/LeNetPractice/mAiLab_0003/mytensor"
+)
+
+// region variable
+
+// C3Table 是 LeNet-5 論文 Table I 中 C3 層的部份連接表，
+// C3Table[o][i] 為 true 表示 C3 的第 o 張特徵圖有連接到 S2 的第 i 張特徵圖。
+// 前 6 張各連接 3 張相鄰的圖，接著 6 張連接 4 張相鄰的圖，再 3 張連接 4 張不相鄰的圖，最後 1 張連接全部。
+var C3Table = [][]bool{
+	{true, true, true, false, false, false},
+	{false, true, true, true, false, false},
+	{false, false, true, true, true, false},
+	{false, false, false, true, true, true},
+	{true, false, false, false, true, true},
+	{true, true, false, false, false, true},
+	{true, true, true, true, false, false},
+	{false, true, true, true, true, false},
+	{false, false, true, true, true, true},
+	{true, false, false, true, true, true},
+	{true, true, false, false, true, true},
+	{true, true, true, false, false, true},
+	{true, true, false, true, true, false},
+	{false, true, true, false, true, true},
+	{true, false, true, true, false, true},
+	{true, true, true, true, true, true},
+}
+
+// endregion variable
+
+// region struct
+
+// Conv2D 是步長為 1、不補零的二維卷積層，輸入為 [InC, H, W]，輸出為 [OutC, H-K+1, W-K+1]。
+// Table 不為 nil 時只有 Table[o][i] 為 true 的輸入通道會連接到輸出通道 o，
+// 未連接的權重固定為 0 且不會累加梯度。
+type Conv2D struct {
+	InC    int
+	OutC   int
+	K      int
+	Table  [][]bool
+	Weight *Param // 形狀為 [OutC, InC, K, K]。
+	Bias   *Param // 形狀為 [OutC]。
+
+	in  *mytensor.Tensor
+	out *mytensor.Tensor
+}
+
+// endregion struct
+
+// region function
+
+// NewConv2D 函數會建立一個 inC 個輸入通道、outC 個輸出通道、k x k 卷積核的卷積層。
+// table 為 nil 時為全連接；權重以 [-2.4/F, 2.4/F) 的均勻分佈亂數初始化，F 為每個輸出單元實際連接的輸入數。
+// 通道數或 k 不是正數時回傳錯誤。
+func NewConv2D(inC, outC, k int, table [][]bool, rnd *rand_fromgo.Rand) (l *Conv2D, err error) {
+	if inC <= 0 || outC <= 0 || k <= 0 {
+		return nil, fmt.Errorf("Error: convolution with %d input channels, %d output channels and kernel %d must be positive", inC, outC, k)
+	}
+	if table != nil {
+		if len(table) != outC {
+			return nil, fmt.Errorf("Error: connection table has %d rows, should be %d", len(table), outC)
+		}
+		for _, row := range table {
+			if len(row) != inC {
+				return nil, fmt.Errorf("Error: connection table row has %d columns, should be %d", len(row), inC)
+			}
+		}
+	}
+	l = &Conv2D{
+		InC: inC, OutC: outC, K: k, Table: table,
+		Weight: NewParam("weight", outC, inC, k, k),
+		Bias:   NewParam("bias", outC),
+	}
+	kk := k * k
+	for o := 0; o < outC; o++ {
+		fanIn := 0
+		for i := 0; i < inC; i++ {
+			if l.connected(o, i) {
+				fanIn += kk
+			}
+		}
+		for i := 0; i < inC; i++ {
+			if l.connected(o, i) {
+				w, _ := mytensor.FromSlice(l.Weight.Value.Data[(o*inC+i)*kk:(o*inC+i+1)*kk], kk)
+				if err = (myinit.UniformFanIn{}).Init(w, fanIn, outC*kk, rnd); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+	return l, nil
+}
+
+// endregion function
+
+// region method
+
+// connected 會回傳輸出通道 o 是否連接到輸入通道 i。
+func (l *Conv2D) connected(o, i int) bool {
+	return l.Table == nil || l.Table[o][i]
+}
+
+// Forward 會回傳 in 與每一個卷積核做相關運算（correlation）後加上偏差值的結果。
+func (l *Conv2D) Forward(in *mytensor.Tensor) (out *mytensor.Tensor, err error) {
+	if err = checkDims("Conv2D", in, 3); err != nil {
+		return nil, err
+	}
+	if in.Shape[0] != l.InC {
+		return nil, fmt.Errorf("Error: Conv2D expects %d input channels, got %d", l.InC, in.Shape[0])
+	}
+	h, w := in.Shape[1], in.Shape[2]
+	if h < l.K || w < l.K {
+		return nil, errors.New("Error: Conv2D kernel is larger than input")
+	}
+	oh, ow := h-l.K+1, w-l.K+1
+	l.in = in
+	l.out = mytensor.New(l.OutC, oh, ow)
+	wt := l.Weight.Value.Data
+	for o := 0; o < l.OutC; o++ {
+		dst := l.out.Data[o*oh*ow : (o+1)*oh*ow]
+		for j := range dst {
+			dst[j] = l.Bias.Value.Data[o]
+		}
+		for i := 0; i < l.InC; i++ {
+			if !l.connected(o, i) {
+				continue
+			}
+			src := in.Data[i*h*w : (i+1)*h*w]
+			kernel := wt[(o*l.InC+i)*l.K*l.K:]
+			for y := 0; y < oh; y++ {
+				for x := 0; x < ow; x++ {
+					sum := 0.0
+					for m := 0; m < l.K; m++ {
+						row := src[(y+m)*w+x:]
+						for n := 0; n < l.K; n++ {
+							sum += kernel[m*l.K+n] * row[n]
+						}
+					}
+					dst[y*ow+x] += sum
+				}
+			}
+		}
+	}
+	return l.out, nil
+}
+
+// Backward 會回傳輸入梯度，並將有連接的權重與偏差值梯度累加至 Grad。
+func (l *Conv2D) Backward(gradOut *mytensor.Tensor) (gradIn *mytensor.Tensor, err error) {
+	if l.in == nil {
+		return nil, errors.New("Error: Conv2D Backward called before Forward")
+	}
+	if err = checkShape("Conv2D", gradOut, l.out); err != nil {
+		return nil, err
+	}
+	h, w := l.in.Shape[1], l.in.Shape[2]
+	oh, ow := l.out.Shape[1], l.out.Shape[2]
+	gradIn = mytensor.New(l.in.Shape...)
+	wt, gw := l.Weight.Value.Data, l.Weight.Grad.Data
+	for o := 0; o < l.OutC; o++ {
+		g := gradOut.Data[o*oh*ow : (o+1)*oh*ow]
+		for _, v := range g {
+			l.Bias.Grad.Data[o] += v
+		}
+		for i := 0; i < l.InC; i++ {
+			if !l.connected(o, i) {
+				continue
+			}
+			src := l.in.Data[i*h*w : (i+1)*h*w]
+			dst := gradIn.Data[i*h*w : (i+1)*h*w]
+			base := (o*l.InC + i) * l.K * l.K
+			for y := 0; y < oh; y++ {
+				for x := 0; x < ow; x++ {
+					v := g[y*ow+x]
+					if v == 0 {
+						continue
+					}
+					for m := 0; m < l.K; m++ {
+						for n := 0; n < l.K; n++ {
+							k := (y+m)*w + x + n
+							gw[base+m*l.K+n] += v * src[k]
+							dst[k] += v * wt[base+m*l.K+n]
+						}
+					}
+				}
+			}
+		}
+	}
+	return gradIn, nil
+}
+
+// Backward2 會回傳輸入的二次導數，並將有連接的權重與偏差值的二次導數累加至 Hess。
+// 與 Backward 相同，只是以 h * x² 取代 g * x、以 h * w² 取代 g * w；共用的權重會加總所有連接的二次導數。
+func (l *Conv2D) Backward2(hessOut *mytensor.Tensor) (hessIn *mytensor.Tensor, err error) {
+	if l.in == nil {
+		return nil, errors.New("Error: Conv2D Backward2 called before Forward")
+	}
+	if err = checkShape("Conv2D", hessOut, l.out); err != nil {
+		return nil, err
+	}
+	h, w := l.in.Shape[1], l.in.Shape[2]
+	oh, ow := l.out.Shape[1], l.out.Shape[2]
+	hessIn = mytensor.New(l.in.Shape...)
+	wt, hw := l.Weight.Value.Data, l.Weight.Hess.Data
+	for o := 0; o < l.OutC; o++ {
+		hs := hessOut.Data[o*oh*ow : (o+1)*oh*ow]
+		for _, v := range hs {
+			l.Bias.Hess.Data[o] += v
+		}
+		for i := 0; i < l.InC; i++ {
+			if !l.connected(o, i) {
+				continue
+			}
+			src := l.in.Data[i*h*w : (i+1)*h*w]
+			dst := hessIn.Data[i*h*w : (i+1)*h*w]
+			base := (o*l.InC + i) * l.K * l.K
+			for y := 0; y < oh; y++ {
+				for x := 0; x < ow; x++ {
+					v := hs[y*ow+x]
+					if v == 0 {
+						continue
+					}
+					for m := 0; m < l.K; m++ {
+						for n := 0; n < l.K; n++ {
+							k := (y+m)*w + x + n
+							wk := wt[base+m*l.K+n]
+							hw[base+m*l.K+n] += v * src[k] * src[k]
+							dst[k] += v * wk * wk
+						}
+					}
+				}
+			}
+		}
+	}
+	return hessIn, nil
+}
+
+// Params 會回傳卷積層的權重與偏差值。
+func (l *Conv2D) Params() []*Param { return []*Param{l.Weight, l.Bias} }
+
+// Connections 會回傳實際有連接的權重數（不含偏差值），C3 使用 C3Table 時為 60*25 = 1500。
+func (l *Conv2D) Connections() (n int) {
+	for o := 0; o < l.OutC; o++ {
+		for i := 0; i < l.InC; i++ {
+			if l.connected(o, i) {
+				n += l.K * l.K
+			}
+		}
+	}
+	return n
+}
+
+// endregion method
@@ -0,0 +1,108 @@
+package mynetwork
+
+import (
+	"fmt"
+
+	"github.com/oneleo/LeNetPractice/mAiLab_0002/rand_fromgo"
+	"github.com/oneleo/LeNetPractice/mAiLab_0003/myinit"
+	"github.com/oneleo/LeNetPractice/mAiLab_0003/mytensor"
+)
+
+// region struct
+
+// Dense 是全連接層：out = Weight * in + Bias。
+// 輸入可以是任意形狀，只要元素總數等於 In 即可（例如 C5 輸出的 [120, 1, 1]），輸出形狀為 [Out]。
+type Dense struct {
+	In     int
+	Out    int
+	Weight *Param // 形狀為 [Out, In]。
+	Bias   *Param // 形狀為 [Out]。
+
+	in *mytensor.Tensor
+}
+
+// endregion struct
+
+// region function
+
+// NewDense 函數會建立一個 in 個輸入、out 個輸出的全連接層。
+// 權重以 rnd 產生 [-2.4/in, 2.4/in) 的均勻分佈亂數（LeNet 論文的初始化方式），偏差值為 0。
+// in 或 out 不是正數時回傳錯誤。
+func NewDense(in, out int, rnd *rand_fromgo.Rand) (l *Dense, err error) {
+	if in <= 0 || out <= 0 {
+		return nil, fmt.Errorf("Error: dense layer size %dx%d must be positive", in, out)
+	}
+	l = &Dense{In: in, Out: out, Weight: NewParam("weight", out, in), Bias: NewParam("bias", out)}
+	if err = (myinit.UniformFanIn{}).Init(l.Weight.Value, in, out, rnd); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// endregion function
+
+// region method
+
+// Forward 會回傳 Weight * in + Bias。
+func (l *Dense) Forward(in *mytensor.Tensor) (out *mytensor.Tensor, err error) {
+	if in.Len() != l.In {
+		return nil, fmt.Errorf("Error: Dense expects %d inputs, got shape %v", l.In, in.Shape)
+	}
+	l.in = in
+	out = mytensor.New(l.Out)
+	w := l.Weight.Value.Data
+	for o := 0; o < l.Out; o++ {
+		sum := l.Bias.Value.Data[o]
+		row := w[o*l.In : (o+1)*l.In]
+		for i, x := range in.Data {
+			sum += row[i] * x
+		}
+		out.Data[o] = sum
+	}
+	return out, nil
+}
+
+// Backward 會回傳與輸入同形狀的梯度，並將權重與偏差值的梯度累加至 Grad。
+func (l *Dense) Backward(gradOut *mytensor.Tensor) (gradIn *mytensor.Tensor, err error) {
+	if l.in == nil {
+		return nil, fmt.Errorf("Error: Dense Backward called before Forward")
+	}
+	if gradOut.Len() != l.Out {
+		return nil, fmt.Errorf("Error: Dense expects %d output gradients, got shape %v", l.Out, gradOut.Shape)
+	}
+	gradIn = mytensor.New(l.in.Shape...)
+	w, gw := l.Weight.Value.Data, l.Weight.Grad.Data
+	for o, g := range gradOut.Data {
+		l.Bias.Grad.Data[o] += g
+		for i, x := range l.in.Data {
+			gw[o*l.In+i] += g * x
+			gradIn.Data[i] += g * w[o*l.In+i]
+		}
+	}
+	return gradIn, nil
+}
+
+// Backward2 會回傳輸入的二次導數 Σ_o w_oi² * h_o，並將權重的 h_o * x_i² 與偏差值的 h_o 累加至 Hess。
+func (l *Dense) Backward2(hessOut *mytensor.Tensor) (hessIn *mytensor.Tensor, err error) {
+	if l.in == nil {
+		return nil, fmt.Errorf("Error: Dense Backward2 called before Forward")
+	}
+	if hessOut.Len() != l.Out {
+		return nil, fmt.Errorf("Error: Dense expects %d output second derivatives, got shape %v", l.Out, hessOut.Shape)
+	}
+	hessIn = mytensor.New(l.in.Shape...)
+	w, hw := l.Weight.Value.Data, l.Weight.Hess.Data
+	for o, h := range hessOut.Data {
+		l.Bias.Hess.Data[o] += h
+		for i, x := range l.in.Data {
+			hw[o*l.In+i] += h * x * x
+			hessIn.Data[i] += h * w[o*l.In+i] * w[o*l.In+i]
+		}
+	}
+	return hessIn, nil
+}
+
+// Params 會回傳全連接層的權重與偏差值。
+func (l *Dense) Params() []*Param { return []*Param{l.Weight, l.Bias} }
+
+// endregion method
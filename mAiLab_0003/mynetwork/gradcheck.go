@@ -0,0 +1,298 @@
+package mynetwork
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/oneleo/LeNetPractice/mAiLab_0002/rand_fromgo"
+	"github.com/oneleo/LeNetPractice/mAiLab_0003/mytensor"
+)
+
+// region struct
+
+// GradCheckOptions 是數值梯度檢查的設定，欄位為 0 時使用 DefaultGradCheckOptions 的值。
+type GradCheckOptions struct {
+	// Step 為中央差分 (f(x+h)-f(x-h))/2h 的 h。
+	Step float64
+	// Tolerance 為可接受的最大相對誤差 |a-n|/max(|a|+|n|, Floor)。
+	Tolerance float64
+	// Floor 為相對誤差分母的下限，避免解析與數值梯度都接近 0 時誤判。
+	Floor float64
+	// MaxChecks 大於 0 時，每一個 Tensor 只隨機檢查 MaxChecks 個元素，用於較大的層。
+	MaxChecks int
+	// Seed 為產生輸出權重與抽樣元素所使用 rand_fromgo 亂數產生器的種子。
+	Seed int64
+}
+
+// GradMismatch 是一個解析梯度與數值梯度不符的元素。
+type GradMismatch struct {
+	// Name 為 "input" 或參數名稱。
+	Name     string
+	Index    int
+	Analytic float64
+	Numeric  float64
+	RelErr   float64
+}
+
+// GradCheckResult 是數值梯度檢查的結果。
+type GradCheckResult struct {
+	// Checked 為檢查過的元素個數。
+	Checked int
+	// MaxRelErr 為所有檢查過元素中最大的相對誤差。
+	MaxRelErr  float64
+	Mismatches []GradMismatch
+}
+
+// endregion struct
+
+// region function
+
+// DefaultGradCheckOptions 函數會回傳 h = 1e-5、容許相對誤差 1e-6 的預設設定。
+func DefaultGradCheckOptions() GradCheckOptions {
+	return GradCheckOptions{Step: 1e-5, Tolerance: 1e-6, Floor: 1e-8, Seed: 7}
+}
+
+// withDefaults 會將 opt 中為 0 的欄位換成預設值。
+func (opt GradCheckOptions) withDefaults() GradCheckOptions {
+	def := DefaultGradCheckOptions()
+	if opt.Step <= 0 {
+		opt.Step = def.Step
+	}
+	if opt.Tolerance <= 0 {
+		opt.Tolerance = def.Tolerance
+	}
+	if opt.Floor <= 0 {
+		opt.Floor = def.Floor
+	}
+	if opt.Seed == 0 {
+		opt.Seed = def.Seed
+	}
+	return opt
+}
+
+// GradCheck 函數會以中央差分計算純量損失 Σ w_i*out_i（w 為隨機權重）對 in 與 l 每一個參數的數值梯度，
+// 並與 l.Backward 算出的解析梯度比較。in 與參數值在檢查後會還原，但參數的 Grad 會被覆寫成解析梯度。
+// 任何層（包含 Model）都可以用此函數檢查反向傳播是否正確。
+func GradCheck(l Layer, in *mytensor.Tensor, opt GradCheckOptions) (res GradCheckResult, err error) {
+	opt = opt.withDefaults()
+	rnd := rand_fromgo.New(rand_fromgo.NewSource(opt.Seed))
+	out, err := l.Forward(in)
+	if err != nil {
+		return res, err
+	}
+	// 以隨機權重 w 組合輸出，讓每一個輸出元素都影響損失。
+	w := mytensor.New(out.Shape...)
+	for i := range w.Data {
+		w.Data[i] = 2*rnd.Float64() - 1
+	}
+	loss := func() (sum float64, err error) {
+		o, err := l.Forward(in)
+		if err != nil {
+			return 0, err
+		}
+		for i, v := range o.Data {
+			sum += w.Data[i] * v
+		}
+		return sum, nil
+	}
+
+	ZeroGrads(l.Params())
+	if _, err = l.Forward(in); err != nil {
+		return res, err
+	}
+	gradIn, err := l.Backward(w)
+	if err != nil {
+		return res, err
+	}
+	if err = res.compare(opt, rnd, "input", in.Data, gradIn.Data, loss); err != nil {
+		return res, err
+	}
+	for _, p := range l.Params() {
+		if err = res.compare(opt, rnd, p.Name, p.Value.Data, p.Grad.Data, loss); err != nil {
+			return res, err
+		}
+	}
+	return res, nil
+}
+
+// GradCheckLoss 函數會以中央差分檢查損失函數 loss 在 out、label 上回傳的梯度，out 在檢查後會還原。
+func GradCheckLoss(loss Loss, out *mytensor.Tensor, label byte, opt GradCheckOptions) (res GradCheckResult, err error) {
+	opt = opt.withDefaults()
+	rnd := rand_fromgo.New(rand_fromgo.NewSource(opt.Seed))
+	_, grad, err := loss.Loss(out, label)
+	if err != nil {
+		return res, err
+	}
+	f := func() (l float64, err error) {
+		l, _, err = loss.Loss(out, label)
+		return l, err
+	}
+	err = res.compare(opt, rnd, "output", out.Data, grad.Data, f)
+	return res, err
+}
+
+// HessCheck 函數會以中央差分求出 l 的 Jacobian J，計算對角 Gauss-Newton 二次導數 Σ_i J_ik² * h_i
+// （h 為隨機的非負值，代表損失對輸出的二次導數），並與 l.Backward2 對 in 與每一個參數算出的結果比較。
+// 對單一層而言這正是 Backward2 應有的值；in 與參數值在檢查後會還原，但參數的 Hess 會被覆寫成解析結果。
+func HessCheck(l Layer2, in *mytensor.Tensor, opt GradCheckOptions) (res GradCheckResult, err error) {
+	opt = opt.withDefaults()
+	rnd := rand_fromgo.New(rand_fromgo.NewSource(opt.Seed))
+	out, err := l.Forward(in)
+	if err != nil {
+		return res, err
+	}
+	h := mytensor.New(out.Shape...)
+	for i := range h.Data {
+		h.Data[i] = rnd.Float64()
+	}
+	// gaussNewton 會擾動 x[i]，以中央差分求出 Jacobian 的一行後回傳 Σ_k J_ki² * h_k。
+	gaussNewton := func(x []float64) func(i int) (float64, error) {
+		return func(i int) (gn float64, err error) {
+			old := x[i]
+			x[i] = old + opt.Step
+			op, err := l.Forward(in)
+			if err != nil {
+				x[i] = old
+				return 0, err
+			}
+			op = op.Clone()
+			x[i] = old - opt.Step
+			om, err := l.Forward(in)
+			x[i] = old
+			if err != nil {
+				return 0, err
+			}
+			for k, hk := range h.Data {
+				j := (op.Data[k] - om.Data[k]) / (2 * opt.Step)
+				gn += j * j * hk
+			}
+			return gn, nil
+		}
+	}
+
+	ZeroHess(l.Params())
+	if _, err = l.Forward(in); err != nil {
+		return res, err
+	}
+	hessIn, err := l.Backward2(h)
+	if err != nil {
+		return res, err
+	}
+	if err = res.compareWith(opt, rnd, "input", in.Data, hessIn.Data, gaussNewton(in.Data)); err != nil {
+		return res, err
+	}
+	for _, p := range l.Params() {
+		if err = res.compareWith(opt, rnd, p.Name, p.Value.Data, p.Hess.Data, gaussNewton(p.Value.Data)); err != nil {
+			return res, err
+		}
+	}
+	return res, nil
+}
+
+// HessCheckLoss 函數會以梯度的中央差分 ∂grad_i/∂out_i 檢查損失函數 loss 在 out、label 上回傳的二次導數，
+// out 在檢查後會還原；梯度本身應先以 GradCheckLoss 檢查。
+func HessCheckLoss(loss Loss2, out *mytensor.Tensor, label byte, opt GradCheckOptions) (res GradCheckResult, err error) {
+	opt = opt.withDefaults()
+	rnd := rand_fromgo.New(rand_fromgo.NewSource(opt.Seed))
+	hess, err := loss.Hess(out, label)
+	if err != nil {
+		return res, err
+	}
+	err = res.compareWith(opt, rnd, "output", out.Data, hess.Data, func(i int) (float64, error) {
+		old := out.Data[i]
+		out.Data[i] = old + opt.Step
+		_, gp, err := loss.Loss(out, label)
+		if err != nil {
+			out.Data[i] = old
+			return 0, err
+		}
+		out.Data[i] = old - opt.Step
+		_, gm, err := loss.Loss(out, label)
+		out.Data[i] = old
+		if err != nil {
+			return 0, err
+		}
+		return (gp.Data[i] - gm.Data[i]) / (2 * opt.Step), nil
+	})
+	return res, err
+}
+
+// sampleIndices 函數會回傳要檢查的元素索引：n <= max 或 max <= 0 時為全部，否則隨機抽出 max 個不重複的索引。
+func sampleIndices(rnd *rand_fromgo.Rand, n, max int) (idx []int) {
+	if max <= 0 || n <= max {
+		idx = make([]int, n)
+		for i := range idx {
+			idx[i] = i
+		}
+		return idx
+	}
+	return rnd.Perm(n)[:max]
+}
+
+// endregion function
+
+// region method
+
+// compare 會逐一擾動 x 的元素，以 f 的中央差分與 analytic 比較，並記錄結果。
+func (r *GradCheckResult) compare(opt GradCheckOptions, rnd *rand_fromgo.Rand, name string, x, analytic []float64, f func() (float64, error)) (err error) {
+	return r.compareWith(opt, rnd, name, x, analytic, func(i int) (float64, error) {
+		old := x[i]
+		x[i] = old + opt.Step
+		fp, err := f()
+		if err != nil {
+			x[i] = old
+			return 0, err
+		}
+		x[i] = old - opt.Step
+		fm, err := f()
+		x[i] = old
+		if err != nil {
+			return 0, err
+		}
+		return (fp - fm) / (2 * opt.Step), nil
+	})
+}
+
+// compareWith 會對抽樣的每一個元素 i 以 numeric(i) 計算數值結果並與 analytic[i] 比較，並記錄結果；
+// numeric 必須在回傳前還原 x[i]。
+func (r *GradCheckResult) compareWith(opt GradCheckOptions, rnd *rand_fromgo.Rand, name string, x, analytic []float64, numeric func(i int) (float64, error)) (err error) {
+	if len(x) != len(analytic) {
+		return fmt.Errorf("Error: %s has %d gradients, should be %d", name, len(analytic), len(x))
+	}
+	for _, i := range sampleIndices(rnd, len(x), opt.MaxChecks) {
+		num, err := numeric(i)
+		if err != nil {
+			return err
+		}
+		rel := math.Abs(num-analytic[i]) / math.Max(opt.Floor, math.Abs(num)+math.Abs(analytic[i]))
+		r.Checked++
+		r.MaxRelErr = math.Max(r.MaxRelErr, rel)
+		if rel > opt.Tolerance || math.IsNaN(rel) {
+			r.Mismatches = append(r.Mismatches, GradMismatch{Name: name, Index: i, Analytic: analytic[i], Numeric: num, RelErr: rel})
+		}
+	}
+	return nil
+}
+
+// OK 會回傳是否所有檢查過的元素都在容許誤差內。
+func (r GradCheckResult) OK() bool { return len(r.Mismatches) == 0 }
+
+// Err 會在有不符的元素時回傳列出前幾個不符元素的錯誤，否則回傳 nil。
+func (r GradCheckResult) Err() error {
+	if r.OK() {
+		return nil
+	}
+	const show = 5
+	lines := make([]string, 0, show+1)
+	for n, m := range r.Mismatches {
+		if n == show {
+			lines = append(lines, fmt.Sprintf("... and %d more", len(r.Mismatches)-show))
+			break
+		}
+		lines = append(lines, fmt.Sprintf("%s[%d]: analytic %g, numeric %g, relative error %.3g", m.Name, m.Index, m.Analytic, m.Numeric, m.RelErr))
+	}
+	return fmt.Errorf("Error: gradient check failed for %d of %d elements\n%s", len(r.Mismatches), r.Checked, strings.Join(lines, "\n"))
+}
+
+// endregion method
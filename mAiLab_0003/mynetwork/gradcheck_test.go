@@ -0,0 +1,274 @@
+package mynetwork
+
+import (
+	"math"
+	"testing"
+
+	"github.com/oneleo/LeNetPractice/mAiLab_0002/rand_fromgo"
+	"github.com/oneleo/LeNetPractice/mAiLab_0003/mytensor"
+)
+
+// checkLayerGrad 會以 GradCheck 檢查 l 對輸入及參數的梯度，相對誤差超過 tol 即測試失敗。
+func checkLayerGrad(t *testing.T, l Layer, in *mytensor.Tensor, tol float64) {
+	t.Helper()
+	res, err := GradCheck(l, in, GradCheckOptions{Tolerance: tol})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = res.Err(); err != nil {
+		t.Error(err)
+	}
+}
+
+// checkLayerHess 會以 HessCheck 檢查 l 對輸入及參數的二次導數，相對誤差超過 tol 即測試失敗。
+func checkLayerHess(t *testing.T, l Layer2, in *mytensor.Tensor, tol float64) {
+	t.Helper()
+	res, err := HessCheck(l, in, GradCheckOptions{Tolerance: tol, Floor: 1e-6})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = res.Err(); err != nil {
+		t.Error(err)
+	}
+}
+
+// plainLayer 只保留 Layer 的方法，用來確認沒有實作 Backward2 的層會被 Model.Backward2 拒絕。
+type plainLayer struct {
+	Layer
+}
+
+// brokenDense 是反向傳播故意少乘 2 的全連接層，用來確認 GradCheck 能找出錯誤。
+type brokenDense struct {
+	*Dense
+}
+
+// Backward 會將正確的輸入梯度除以 2。
+func (l brokenDense) Backward(gradOut *mytensor.Tensor) (gradIn *mytensor.Tensor, err error) {
+	gradIn, err = l.Dense.Backward(gradOut)
+	for i := range gradIn.Data {
+		gradIn.Data[i] /= 2
+	}
+	return gradIn, err
+}
+
+// Test_GradCheckLayers 是以 GradCheck 檢查每一種池化層，以及整個 LeNet-5 模型。
+func Test_GradCheckLayers(t *testing.T) {
+	var tests = []struct {
+		name  string
+		layer Layer
+		in    *mytensor.Tensor
+	}{
+		{"max", NewMaxPool2D(2, 2), randomInput(1, 2, 6, 6)},
+		{"avg", NewAvgPool2D(2, 2), randomInput(2, 2, 6, 6)},
+		{"subsampling", NewSubsampling(2, 2), randomInput(3, 2, 6, 6)},
+		{"overlapping max", NewMaxPool2D(3, 2), randomInput(4, 1, 7, 7)},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			checkLayerGrad(t, test.layer, test.in, 1e-6)
+		})
+	}
+
+	// 整個模型的參數很多，每一個 Tensor 只抽樣檢查部份元素；
+	// RBF 輸出的數值較大，差分的捨入誤差約為 1e-9，因此提高分母下限 Floor，讓極小的梯度以絕對誤差比較。
+	for _, cfg := range []Config{ClassicConfig(), PaperConfig()} {
+		m, err := NewLeNet5(cfg, rand_fromgo.New(rand_fromgo.NewSource(11)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		res, err := GradCheck(m, randomInput(5, 1, InputRows, InputCols), GradCheckOptions{Tolerance: 1e-4, Floor: 1e-4, MaxChecks: 20})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err = res.Err(); err != nil {
+			t.Errorf("Error %s model: %v", cfg.Output, err)
+		}
+		// 輸入 32x32 抽 20 個，加上每一組參數各最多 20 個。
+		if max := 20 * (1 + len(m.Params())); res.Checked == 0 || res.Checked > max {
+			t.Errorf("Error checked %d elements, should be in (0, %d].", res.Checked, max)
+		}
+	}
+}
+
+// Test_GradCheckDetects 是確認 GradCheck 會找出錯誤的反向傳播，並且不會改變輸入與參數值。
+func Test_GradCheckDetects(t *testing.T) {
+	dense, _ := NewDense(4, 3, rand_fromgo.New(rand_fromgo.NewSource(12)))
+	l := brokenDense{dense}
+	in := randomInput(6, 4)
+	before, weight := in.Clone(), l.Weight.Value.Clone()
+	res, err := GradCheck(l, in, GradCheckOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.OK() || res.Err() == nil || len(res.Mismatches) != 4 {
+		t.Errorf("Error broken layer should fail on all 4 inputs, got %+v.", res.Mismatches)
+	}
+	for _, m := range res.Mismatches {
+		if m.Name != "input" {
+			t.Errorf("Error mismatch on %s, only input gradients are broken.", m.Name)
+		}
+	}
+	for i := range in.Data {
+		if in.Data[i] != before.Data[i] {
+			t.Fatal("Error input was changed by GradCheck.")
+		}
+	}
+	for i := range weight.Data {
+		if l.Weight.Value.Data[i] != weight.Data[i] {
+			t.Fatal("Error weight was changed by GradCheck.")
+		}
+	}
+}
+
+// Test_HessCheckLayers 是以 HessCheck 檢查每一種層的 Backward2 與對角 Gauss-Newton 二次導數相符。
+func Test_HessCheckLayers(t *testing.T) {
+	rnd := rand_fromgo.New(rand_fromgo.NewSource(13))
+	dense, _ := NewDense(5, 3, rnd)
+	full, _ := NewConv2D(2, 3, 3, nil, rnd)
+	partial, _ := NewConv2D(6, 16, 3, C3Table, rnd)
+	rbf, _ := NewRBF()
+	var tests = []struct {
+		name  string
+		layer Layer2
+		in    *mytensor.Tensor
+	}{
+		{"dense", dense, randomInput(1, 5)},
+		{"conv", full, randomInput(2, 2, 5, 6)},
+		{"conv c3", partial, randomInput(3, 6, 5, 5)},
+		{"sigmoid", NewSigmoid(), randomInput(4, 7)},
+		{"tanh", NewTanh(), randomInput(5, 7)},
+		{"lecun tanh", NewLeCunTanh(), randomInput(6, 7)},
+		{"relu", NewReLU(), randomInput(7, 7)},
+		{"leaky relu", NewLeakyReLU(0.1), randomInput(8, 7)},
+		{"softmax", NewSoftmax(), randomInput(9, Classes)},
+		{"log softmax", NewLogSoftmax(), randomInput(10, Classes)},
+		{"max", NewMaxPool2D(2, 2), randomInput(11, 2, 6, 6)},
+		{"overlapping max", NewMaxPool2D(3, 2), randomInput(12, 1, 7, 7)},
+		{"avg", NewAvgPool2D(2, 2), randomInput(13, 2, 6, 6)},
+		{"subsampling", NewSubsampling(2, 2), randomInput(14, 2, 6, 6)},
+		{"rbf", rbf, randomInput(15, 84)},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			checkLayerHess(t, test.layer, test.in, 1e-5)
+			for _, p := range test.layer.Params() {
+				for _, h := range p.Hess.Data {
+					if h < 0 {
+						t.Fatalf("Error %s has negative second derivative %g.", p.Name, h)
+					}
+				}
+			}
+		})
+	}
+}
+
+// Test_HessCheckLoss 是以梯度的數值差分檢查每一種損失函數回傳的二次導數。
+func Test_HessCheckLoss(t *testing.T) {
+	var tests = []struct {
+		name string
+		loss Loss2
+	}{
+		{"cross-entropy", NewCrossEntropy()},
+		{"mse", NewMSE()},
+		{"hinge", NewHinge(1)},
+		{"map", NewMAPLoss(0.1)},
+	}
+	for _, test := range tests {
+		out := randomInput(16, Classes)
+		res, err := HessCheckLoss(test.loss, out, 7, GradCheckOptions{Tolerance: 1e-5, Floor: 1e-6})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err = res.Err(); err != nil {
+			t.Errorf("Error %s: %v", test.name, err)
+		}
+		if _, err = test.loss.Hess(out, Classes); err == nil {
+			t.Errorf("Error %s with out-of-range label should return an error.", test.name)
+		}
+	}
+}
+
+// Test_ModelBackward2 是測試 Dense-ReLU-Dense 搭配均方誤差時（此時 Gauss-Newton 近似沒有誤差），
+// Model.Backward2 累加的 Hess 與損失對每一個參數的數值二次導數 (E(w+h)-2E(w)+E(w-h))/h² 相符；
+// 並確認各種 LeNet-5 都可以執行 Backward2，以及不支援 Backward2 的層會回傳錯誤。
+func Test_ModelBackward2(t *testing.T) {
+	rnd := rand_fromgo.New(rand_fromgo.NewSource(17))
+	m := &Model{}
+	d1, _ := NewDense(3, 4, rnd)
+	d2, _ := NewDense(4, 2, rnd)
+	m.Add("d1", d1)
+	m.Add("relu", NewReLU())
+	m.Add("d2", d2)
+	loss := &MSE{On: 1, Off: 0}
+	in := randomInput(18, 3)
+	energy := func() float64 {
+		out, _ := m.Forward(in)
+		e, _, _ := loss.Loss(out, 1)
+		return e
+	}
+	out, _ := m.Forward(in)
+	hess, err := loss.Hess(out, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ZeroHess(m.Params())
+	if _, err = m.Backward2(hess); err != nil {
+		t.Fatal(err)
+	}
+	const step = 1e-4
+	for _, p := range m.Params() {
+		for i, old := range p.Value.Data {
+			e := energy()
+			p.Value.Data[i] = old + step
+			ep := energy()
+			p.Value.Data[i] = old - step
+			em := energy()
+			p.Value.Data[i] = old
+			num := (ep - 2*e + em) / (step * step)
+			if math.Abs(num-p.Hess.Data[i]) > 1e-5*math.Max(1, math.Abs(num)) {
+				t.Errorf("Error %s[%d] second derivative %g, numeric %g.", p.Name, i, p.Hess.Data[i], num)
+			}
+		}
+	}
+
+	for _, cfg := range []Config{ClassicConfig(), ModernConfig(), PaperConfig()} {
+		lenet, err := NewLeNet5(cfg, rand_fromgo.New(rand_fromgo.NewSource(19)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		out, err := lenet.Forward(randomInput(20, 1, InputRows, InputCols))
+		if err != nil {
+			t.Fatal(err)
+		}
+		h := mytensor.New(out.Shape...)
+		h.Fill(1)
+		ZeroHess(lenet.Params())
+		if _, err = lenet.Backward2(h); err != nil {
+			t.Fatalf("Error %+v: %v", cfg, err)
+		}
+		for _, p := range lenet.Params() {
+			sum := 0.0
+			for _, v := range p.Hess.Data {
+				if v < 0 || math.IsNaN(v) {
+					t.Fatalf("Error %+v: %s has invalid second derivative %g.", cfg, p.Name, v)
+				}
+				sum += v
+			}
+			if sum == 0 {
+				t.Errorf("Error %+v: %s has no second derivative.", cfg, p.Name)
+			}
+		}
+	}
+
+	// 最後一層不支援 Backward2 時，前面層的 Hess 也不會被修改。
+	bad := &Model{}
+	bad.Add("d1", d1)
+	bad.Add("plain", plainLayer{NewReLU()})
+	ZeroHess(bad.Params())
+	if _, err = bad.Forward(in); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = bad.Backward2(mytensor.New(4)); err == nil {
+		t.Error("Error Backward2 through a layer without Backward2 should return an error.")
+	}
+}
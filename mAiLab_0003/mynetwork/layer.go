@@ -0,0 +1,81 @@
+package mynetwork
+
+import (
+	"fmt"
+
+	"github.com/oneleo/LeNetPractice/mAiLab_0003/mytensor"
+)
+
+// region interface
+
+// Layer 是神經網路中的一層。
+// Forward 會計算一筆輸入的輸出，並保留反向傳播所需的資訊；
+// Backward 會根據輸出的梯度 gradOut 計算輸入的梯度，並將參數梯度「累加」至 Params 的 Grad 內，
+// 因此 Backward 必須在對應的 Forward 之後呼叫。
+type Layer interface {
+	Forward(in *mytensor.Tensor) (out *mytensor.Tensor, err error)
+	Backward(gradOut *mytensor.Tensor) (gradIn *mytensor.Tensor, err error)
+	Params() []*Param
+}
+
+// Layer2 是可以反向傳播二次導數的層，用於 LeNet 論文附錄 C 隨機對角 Levenberg-Marquardt 法的曲率估計。
+// Backward2 會根據損失對輸出的二次導數 hessOut 計算對輸入的二次導數，並將參數的二次導數「累加」至 Params 的 Hess 內。
+// 與論文相同採用對角 Gauss-Newton 近似：一次導數中的權重 w 與激活函數導數 f'(a) 換成 w² 與 f'(a)²，
+// 並忽略激活函數的二次導數與非對角項，因此結果一定不是負數。Backward2 必須在對應的 Forward 之後呼叫。
+type Layer2 interface {
+	Layer
+	Backward2(hessOut *mytensor.Tensor) (hessIn *mytensor.Tensor, err error)
+}
+
+// endregion interface
+
+// region struct
+
+// Param 是一組可訓練的參數，Value 為參數值，Grad 為累加中的梯度，Hess 為 Backward2 累加中的二次導數，三者形狀相同。
+type Param struct {
+	Name  string
+	Value *mytensor.Tensor
+	Grad  *mytensor.Tensor
+	Hess  *mytensor.Tensor
+}
+
+// endregion struct
+
+// region function
+
+// NewParam 函數會建立一組名為 name、形狀為 shape 且值、梯度與二次導數皆為 0 的參數。
+func NewParam(name string, shape ...int) *Param {
+	return &Param{Name: name, Value: mytensor.New(shape...), Grad: mytensor.New(shape...), Hess: mytensor.New(shape...)}
+}
+
+// ZeroGrads 函數會將 params 的梯度全部清為 0，通常在每一個 mini-batch 開始前呼叫。
+func ZeroGrads(params []*Param) {
+	for _, p := range params {
+		p.Grad.Zero()
+	}
+}
+
+// ZeroHess 函數會將 params 的二次導數全部清為 0，通常在每一筆樣本的 Backward2 之前呼叫。
+func ZeroHess(params []*Param) {
+	for _, p := range params {
+		p.Hess.Zero()
+	}
+}
+
+// checkDims 函數會確認 t 的維度數為 dims，否則回傳錯誤。
+func checkDims(layer string, t *mytensor.Tensor, dims int) (err error) {
+	if t.Dims() != dims {
+		return fmt.Errorf("Error: %s expects a %d-D tensor, got shape %v", layer, dims, t.Shape)
+	}
+	return nil
+}
+
+// checkShape 函數會確認 t 的形狀與 want 相同，否則回傳錯誤。
+func checkShape(layer string, t, want *mytensor.Tensor) (err error) {
+	if !t.SameShape(want) {
+		return fmt.Errorf("Error: %s expects shape %v, got %v", layer, want.Shape, t.Shape)
+	}
+	return nil
+}
+
+// endregion function
@@ -0,0 +1,174 @@
+package mynetwork
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/oneleo/LeNetPractice/mAiLab_0003/mytensor"
+)
+
+// region interface
+
+// Loss 是損失函數：根據模型輸出 out 與 mymnist 讀入的正確 label，
+// 回傳純量損失以及對 out 的梯度（可直接交給 Model.Backward）。
+type Loss interface {
+	Loss(out *mytensor.Tensor, label byte) (loss float64, grad *mytensor.Tensor, err error)
+}
+
+// Loss2 是可以計算損失對輸出二次導數 ∂²E/∂out_i²（Hessian 的對角項）的損失函數，
+// Hess 的結果可直接交給 Model.Backward2，用於 SDLM 的曲率估計；結果一定不是負數。
+type Loss2 interface {
+	Loss
+	Hess(out *mytensor.Tensor, label byte) (hess *mytensor.Tensor, err error)
+}
+
+// endregion interface
+
+// region struct
+
+// CrossEntropy 是類別交叉熵，輸入為尚未經過 softmax 的 logits。
+// 內部直接以 log-softmax 計算 -log p_label，梯度為 softmax(out) - onehot(label)，
+// 因此模型最後一層不需要（也不應該）再接 Softmax。
+type CrossEntropy struct{}
+
+// MSE 是與 one-hot 目標的均方誤差：(1/N) Σ_i (out_i - t_i)²。
+// 正確類別的目標值為 On，其他為 Off；例如 tanh 輸出可使用 On = 1、Off = -1。
+type MSE struct {
+	On  float64
+	Off float64
+}
+
+// Hinge 是多類別 hinge 損失（Weston-Watkins）：Σ_{i≠label} max(0, Margin + out_i - out_label)。
+type Hinge struct {
+	Margin float64
+}
+
+// endregion struct
+
+// region function
+
+// NewCrossEntropy 函數會建立交叉熵損失函數。
+func NewCrossEntropy() *CrossEntropy { return &CrossEntropy{} }
+
+// NewMSE 函數會建立目標值為 0/1 one-hot 的均方誤差損失函數。
+func NewMSE() *MSE { return &MSE{On: 1, Off: 0} }
+
+// NewHinge 函數會建立邊界為 margin 的多類別 hinge 損失函數。
+func NewHinge(margin float64) *Hinge { return &Hinge{Margin: margin} }
+
+// OneHot 函數會將 label 轉成長度為 classes 的 one-hot 向量，正確類別為 on，其他為 off。
+func OneHot(label byte, classes int, on, off float64) (t *mytensor.Tensor, err error) {
+	if err = checkLabel(label, classes); err != nil {
+		return nil, err
+	}
+	t = mytensor.New(classes)
+	t.Fill(off)
+	t.Data[label] = on
+	return t, nil
+}
+
+// OneHotLabels 函數會將 mymnist.ReadMnistLabels 讀入的所有 label 轉成形狀為 [len(lbls), classes] 的 0/1 one-hot 矩陣。
+func OneHotLabels(lbls []byte, classes int) (t *mytensor.Tensor, err error) {
+	t = mytensor.New(len(lbls), classes)
+	for n, l := range lbls {
+		if err = checkLabel(l, classes); err != nil {
+			return nil, fmt.Errorf("label %d: %v", n, err)
+		}
+		t.Data[n*classes+int(l)] = 1
+	}
+	return t, nil
+}
+
+// checkLabel 函數會確認 label 介於 [0, classes)。
+func checkLabel(label byte, classes int) (err error) {
+	if int(label) >= classes {
+		return fmt.Errorf("Error: label %d out of range [0, %d)", label, classes)
+	}
+	return nil
+}
+
+// endregion function
+
+// region method
+
+// Loss 會回傳 -log softmax(out)[label] 以及梯度 softmax(out) - onehot(label)。
+func (l *CrossEntropy) Loss(out *mytensor.Tensor, label byte) (loss float64, grad *mytensor.Tensor, err error) {
+	if err = checkLabel(label, out.Len()); err != nil {
+		return 0, nil, err
+	}
+	lp := LogSoftmaxOf(out.Data)
+	grad = mytensor.New(out.Shape...)
+	for i, v := range lp {
+		grad.Data[i] = math.Exp(v)
+	}
+	grad.Data[label]--
+	return -lp[label], grad, nil
+}
+
+// Hess 會回傳 softmax 交叉熵對 out 的二次導數 p_i(1-p_i)，p 為 softmax(out)。
+func (l *CrossEntropy) Hess(out *mytensor.Tensor, label byte) (hess *mytensor.Tensor, err error) {
+	if err = checkLabel(label, out.Len()); err != nil {
+		return nil, err
+	}
+	hess = mytensor.New(out.Shape...)
+	for i, v := range LogSoftmaxOf(out.Data) {
+		p := math.Exp(v)
+		hess.Data[i] = p * (1 - p)
+	}
+	return hess, nil
+}
+
+// Loss 會回傳 out 與 one-hot 目標的均方誤差，以及梯度 2(out_i - t_i)/N。
+func (l *MSE) Loss(out *mytensor.Tensor, label byte) (loss float64, grad *mytensor.Tensor, err error) {
+	target, err := OneHot(label, out.Len(), l.On, l.Off)
+	if err != nil {
+		return 0, nil, err
+	}
+	n := float64(out.Len())
+	grad = mytensor.New(out.Shape...)
+	for i, v := range out.Data {
+		d := v - target.Data[i]
+		loss += d * d / n
+		grad.Data[i] = 2 * d / n
+	}
+	return loss, grad, nil
+}
+
+// Hess 會回傳均方誤差對 out 的二次導數 2/N。
+func (l *MSE) Hess(out *mytensor.Tensor, label byte) (hess *mytensor.Tensor, err error) {
+	if err = checkLabel(label, out.Len()); err != nil {
+		return nil, err
+	}
+	hess = mytensor.New(out.Shape...)
+	hess.Fill(2 / float64(out.Len()))
+	return hess, nil
+}
+
+// Loss 會回傳多類別 hinge 損失；每一個違反邊界的類別 i 梯度為 +1，正確類別的梯度為 -(違反邊界的類別數)。
+func (l *Hinge) Loss(out *mytensor.Tensor, label byte) (loss float64, grad *mytensor.Tensor, err error) {
+	if err = checkLabel(label, out.Len()); err != nil {
+		return 0, nil, err
+	}
+	grad = mytensor.New(out.Shape...)
+	for i, v := range out.Data {
+		if i == int(label) {
+			continue
+		}
+		if m := l.Margin + v - out.Data[label]; m > 0 {
+			loss += m
+			grad.Data[i]++
+			grad.Data[label]--
+		}
+	}
+	return loss, grad, nil
+}
+
+// Hess 會回傳全為 0 的二次導數，因為 hinge 損失是分段線性的。
+func (l *Hinge) Hess(out *mytensor.Tensor, label byte) (hess *mytensor.Tensor, err error) {
+	if err = checkLabel(label, out.Len()); err != nil {
+		return nil, err
+	}
+	return mytensor.New(out.Shape...), nil
+}
+
+// endregion method
@@ -0,0 +1,70 @@
+package mynetwork
+
+import (
+	"math"
+	"testing"
+)
+
+// Test_LossGrad 是以數值梯度檢查每一種損失函數回傳的梯度。
+func Test_LossGrad(t *testing.T) {
+	var tests = []struct {
+		name string
+		loss Loss
+	}{
+		{"cross-entropy", NewCrossEntropy()},
+		{"mse", NewMSE()},
+		{"mse(-1,1)", &MSE{On: 1, Off: -1}},
+		{"hinge", NewHinge(1)},
+		{"map", NewMAPLoss(0.1)},
+	}
+	for _, test := range tests {
+		out := randomInput(8, Classes)
+		res, err := GradCheckLoss(test.loss, out, 7, GradCheckOptions{Tolerance: 1e-6})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err = res.Err(); err != nil {
+			t.Errorf("Error %s: %v", test.name, err)
+		}
+		if _, _, err = test.loss.Loss(out, Classes); err == nil {
+			t.Errorf("Error %s with out-of-range label should return an error.", test.name)
+		}
+	}
+}
+
+// Test_CrossEntropy 是測試均勻 logits 的交叉熵為 log(N)，且極大的 logits 不會溢位。
+func Test_CrossEntropy(t *testing.T) {
+	out := randomInput(9, Classes)
+	out.Fill(3)
+	loss, _, _ := NewCrossEntropy().Loss(out, 0)
+	if math.Abs(loss-math.Log(Classes)) > 1e-12 {
+		t.Errorf("Error loss = %g, should be log(%d).", loss, Classes)
+	}
+	out.Data[0] = 1e5
+	loss, grad, _ := NewCrossEntropy().Loss(out, 0)
+	if loss != 0 || math.IsNaN(grad.Data[0]) {
+		t.Errorf("Error loss = %g, grad = %v.", loss, grad.Data)
+	}
+}
+
+// Test_OneHotLabels 是測試 label 轉 one-hot 矩陣，以及超出範圍的 label 會回傳錯誤。
+func Test_OneHotLabels(t *testing.T) {
+	m, err := OneHotLabels([]byte{5, 0, 4}, Classes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for n, l := range []int{5, 0, 4} {
+		for c := 0; c < Classes; c++ {
+			w := 0.0
+			if c == l {
+				w = 1
+			}
+			if m.At(n, c) != w {
+				t.Errorf("Error one-hot[%d][%d] = %g, should be %g.", n, c, m.At(n, c), w)
+			}
+		}
+	}
+	if _, err = OneHotLabels([]byte{1, 12}, Classes); err == nil {
+		t.Errorf("Error out-of-range label should return an error.")
+	}
+}
@@ -0,0 +1,267 @@
+package mynetwork
+
+import (
+	"fmt"
+
+	"github.com/oneleo/LeNetPractice/mAiLab_0002/rand_fromgo"
+	"github.com/oneleo/LeNetPractice/mAiLab_0003/mytensor"
+)
+
+// LeNet-5 的輸入為 32x32 的灰階影像，也就是 mymnist.ImgAddZero(img, 32, 32) 的輸出。
+const (
+	InputRows = 32
+	InputCols = 32
+	Classes   = 10
+)
+
+// Config 中 Activation、Pooling、Output 可用的值。
+const (
+	ActLeCunTanh = "lecun_tanh"
+	ActTanh      = "tanh"
+	ActSigmoid   = "sigmoid"
+	ActReLU      = "relu"
+
+	PoolSubsampling = "subsampling"
+	PoolMax         = "max"
+	PoolAvg         = "avg"
+
+	OutputDense = "dense"
+	OutputRBF   = "rbf"
+)
+
+// region struct
+
+// Config 描述 LeNet-5 的變化型，以字串記錄以便寫入檔案後重建相同的模型。
+type Config struct {
+	// Activation 為每一層卷積、子取樣與 F6 之後的激活函數。
+	Activation string
+	// Pooling 為 S2、S4 使用的取樣方式。
+	Pooling string
+	// FullC3 為 true 時 C3 與 S2 全連接，否則使用論文的 C3Table。
+	FullC3 bool
+	// Output 為輸出層的種類。
+	Output string
+}
+
+// Model 是依序串接的多層網路，本身也實作 Layer。
+type Model struct {
+	Config Config
+	Names  []string
+	Layers []Layer
+}
+
+// endregion struct
+
+// region function
+
+// ClassicConfig 函數會回傳論文原始的 LeNet-5 設定：縮放 tanh、可訓練子取樣、C3 部份連接，
+// 但輸出層改為全連接層，以便搭配 softmax 交叉熵訓練。
+func ClassicConfig() Config {
+	return Config{Activation: ActLeCunTanh, Pooling: PoolSubsampling, FullC3: false, Output: OutputDense}
+}
+
+// PaperConfig 函數會回傳與論文完全相同的 LeNet-5 設定：在 ClassicConfig 之外，
+// 輸出層使用固定原型的 RBF 單元，需搭配 MAPLoss 訓練。
+func PaperConfig() Config {
+	cfg := ClassicConfig()
+	cfg.Output = OutputRBF
+	return cfg
+}
+
+// ModernConfig 函數會回傳現代常見的 LeNet-5 變化型：ReLU、最大值池化、C3 全連接。
+func ModernConfig() Config {
+	return Config{Activation: ActReLU, Pooling: PoolMax, FullC3: true, Output: OutputDense}
+}
+
+// NewActivation 函數會依名稱建立激活層，名稱不存在時回傳錯誤。
+func NewActivation(name string) (l Layer, err error) {
+	switch name {
+	case ActLeCunTanh:
+		return NewLeCunTanh(), nil
+	case ActTanh:
+		return NewTanh(), nil
+	case ActSigmoid:
+		return NewSigmoid(), nil
+	case ActReLU:
+		return NewReLU(), nil
+	}
+	return nil, fmt.Errorf("Error: unknown activation %q", name)
+}
+
+// newPooling 函數會依名稱建立 2x2 的取樣層。
+func newPooling(name string, channels int) (l Layer, err error) {
+	switch name {
+	case PoolSubsampling:
+		return NewSubsampling(channels, 2), nil
+	case PoolMax:
+		return NewMaxPool2D(2, 2), nil
+	case PoolAvg:
+		return NewAvgPool2D(2, 2), nil
+	}
+	return nil, fmt.Errorf("Error: unknown pooling %q", name)
+}
+
+// NewLeNet5 函數會依 cfg 建立 LeNet-5：
+//
+//	輸入 1@32x32 → C1 6@28x28 → S2 6@14x14 → C3 16@10x10 → S4 16@5x5
+//	→ C5 120@1x1 → F6 84 → 輸出 10
+//
+// 每一個卷積層、F6 之後都接一個激活層；使用 Subsampling 時，S2、S4 之後也接激活層（與論文相同）。
+// 權重以 rnd 產生的亂數初始化。
+func NewLeNet5(cfg Config, rnd *rand_fromgo.Rand) (m *Model, err error) {
+	// 先確認激活函數與取樣方式的名稱正確，之後建立各層時就不會再出錯。
+	if _, err = NewActivation(cfg.Activation); err != nil {
+		return nil, err
+	}
+	if _, err = newPooling(cfg.Pooling, 1); err != nil {
+		return nil, err
+	}
+	m = &Model{Config: cfg}
+	// addAct 會在 name 層之後加上激活層。
+	addAct := func(name string) {
+		act, _ := NewActivation(cfg.Activation)
+		m.Add(name+"_act", act)
+	}
+	// addPool 會加上取樣層，若為可訓練子取樣則再加上激活層。
+	addPool := func(name string, channels int) {
+		pool, _ := newPooling(cfg.Pooling, channels)
+		m.Add(name, pool)
+		if cfg.Pooling == PoolSubsampling {
+			addAct(name)
+		}
+	}
+
+	c1, _ := NewConv2D(1, 6, 5, nil, rnd)
+	m.Add("c1", c1)
+	addAct("c1")
+	addPool("s2", 6)
+	table := C3Table
+	if cfg.FullC3 {
+		table = nil
+	}
+	c3, _ := NewConv2D(6, 16, 5, table, rnd)
+	m.Add("c3", c3)
+	addAct("c3")
+	addPool("s4", 16)
+	c5, _ := NewConv2D(16, 120, 5, nil, rnd)
+	m.Add("c5", c5)
+	addAct("c5")
+	f6, _ := NewDense(120, 84, rnd)
+	m.Add("f6", f6)
+	addAct("f6")
+
+	switch cfg.Output {
+	case OutputDense:
+		output, _ := NewDense(84, Classes, rnd)
+		m.Add("output", output)
+	case OutputRBF:
+		rbf, err := NewRBF()
+		if err != nil {
+			return nil, err
+		}
+		m.Add("output", rbf)
+	default:
+		return nil, fmt.Errorf("Error: unknown output layer %q", cfg.Output)
+	}
+	return m, nil
+}
+
+// endregion function
+
+// region method
+
+// Add 會將名為 name 的層加到模型最後，並將該層參數名稱加上 "name." 前綴，使整個模型的參數名稱不重覆。
+func (m *Model) Add(name string, l Layer) {
+	for _, p := range l.Params() {
+		p.Name = name + "." + p.Name
+	}
+	m.Names = append(m.Names, name)
+	m.Layers = append(m.Layers, l)
+}
+
+// Forward 會依序呼叫每一層的 Forward，並回傳最後一層的輸出。
+func (m *Model) Forward(in *mytensor.Tensor) (out *mytensor.Tensor, err error) {
+	out = in
+	for n, l := range m.Layers {
+		if out, err = l.Forward(out); err != nil {
+			return nil, fmt.Errorf("%s: %v", m.Names[n], err)
+		}
+	}
+	return out, nil
+}
+
+// Backward 會由最後一層往前依序呼叫每一層的 Backward，並回傳對模型輸入的梯度。
+func (m *Model) Backward(gradOut *mytensor.Tensor) (gradIn *mytensor.Tensor, err error) {
+	gradIn = gradOut
+	for n := len(m.Layers) - 1; n >= 0; n-- {
+		if gradIn, err = m.Layers[n].Backward(gradIn); err != nil {
+			return nil, fmt.Errorf("%s: %v", m.Names[n], err)
+		}
+	}
+	return gradIn, nil
+}
+
+// Backward2 會由最後一層往前依序呼叫每一層的 Backward2，將二次導數累加至參數的 Hess，並回傳對模型輸入的二次導數。
+// 任何一層沒有實作 Layer2 時，不會累加任何二次導數並回傳錯誤。
+func (m *Model) Backward2(hessOut *mytensor.Tensor) (hessIn *mytensor.Tensor, err error) {
+	layers := make([]Layer2, len(m.Layers))
+	for n, l := range m.Layers {
+		var ok bool
+		if layers[n], ok = l.(Layer2); !ok {
+			return nil, fmt.Errorf("Error: %s (%T) does not support Backward2", m.Names[n], l)
+		}
+	}
+	hessIn = hessOut
+	for n := len(layers) - 1; n >= 0; n-- {
+		if hessIn, err = layers[n].Backward2(hessIn); err != nil {
+			return nil, fmt.Errorf("%s: %v", m.Names[n], err)
+		}
+	}
+	return hessIn, nil
+}
+
+// Params 會依層的順序回傳所有可訓練參數。
+func (m *Model) Params() (params []*Param) {
+	for _, l := range m.Layers {
+		params = append(params, l.Params()...)
+	}
+	return params
+}
+
+// NumParams 會回傳可訓練參數的總數；C3 部份連接時，未連接的權重不計入。
+func (m *Model) NumParams() (n int) {
+	for _, l := range m.Layers {
+		if c, ok := l.(*Conv2D); ok {
+			n += c.Connections() + c.Bias.Value.Len()
+			continue
+		}
+		for _, p := range l.Params() {
+			n += p.Value.Len()
+		}
+	}
+	return n
+}
+
+// Predict 會回傳模型對 in 預測的類別。
+func (m *Model) Predict(in *mytensor.Tensor) (label int, err error) {
+	out, err := m.Forward(in)
+	if err != nil {
+		return -1, err
+	}
+	return m.Label(out), nil
+}
+
+// Label 會回傳模型輸出 out 代表的類別：一般為輸出最大的位置，RBF 輸出則為距離最小的位置。
+func (m *Model) Label(out *mytensor.Tensor) (label int) {
+	if m.Config.Output == OutputRBF {
+		return ArgMin(out.Data)
+	}
+	for i, v := range out.Data {
+		if v > out.Data[label] {
+			label = i
+		}
+	}
+	return label
+}
+
+// endregion method
@@ -0,0 +1,82 @@
+package mynetwork
+
+import (
+	"testing"
+
+	"github.com/oneleo/LeNetPractice/mAiLab_0002/rand_fromgo"
+)
+
+// Test_ConvGrad 是以數值梯度檢查卷積層，包含使用 C3Table 部份連接的情況。
+func Test_ConvGrad(t *testing.T) {
+	rnd := rand_fromgo.New(rand_fromgo.NewSource(5))
+	full, _ := NewConv2D(2, 3, 3, nil, rnd)
+	checkLayerGrad(t, full, randomInput(1, 2, 5, 6), 1e-6)
+
+	partial, err := NewConv2D(6, 16, 3, C3Table, rnd)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkLayerGrad(t, partial, randomInput(2, 6, 5, 5), 1e-6)
+	// 未連接的權重必須維持 0，且不會累加梯度。
+	for o := range C3Table {
+		for i, ok := range C3Table[o] {
+			if ok {
+				continue
+			}
+			for k := 0; k < 9; k++ {
+				n := (o*6+i)*9 + k
+				if partial.Weight.Value.Data[n] != 0 || partial.Weight.Grad.Data[n] != 0 {
+					t.Fatalf("Error unconnected weight (%d, %d) is not zero.", o, i)
+				}
+			}
+		}
+	}
+	if _, err = NewConv2D(6, 15, 3, C3Table, rnd); err == nil {
+		t.Errorf("Error mismatched connection table should return an error.")
+	}
+	// 通道數或卷積核大小不是正數時回傳錯誤，而不是留下全為 0 的權重。
+	for _, size := range [][3]int{{2, 3, 0}, {0, 3, 3}, {2, 0, 3}} {
+		if _, err = NewConv2D(size[0], size[1], size[2], nil, rnd); err == nil {
+			t.Errorf("Error convolution %v should return an error.", size)
+		}
+	}
+}
+
+// Test_LeNet5 是測試經典與現代 LeNet-5 的輸出形狀與可訓練參數總數。
+func Test_LeNet5(t *testing.T) {
+	var tests = []struct {
+		cfg    Config
+		params int
+	}{
+		// 論文：C1 156 + S2 12 + C3 1516 + S4 32 + C5 48120 + F6 10164 + 輸出 850。
+		{ClassicConfig(), 60850},
+		// C3 全連接 2416，且最大值池化沒有參數。
+		{ModernConfig(), 61706},
+	}
+	for _, test := range tests {
+		m, err := NewLeNet5(test.cfg, rand_fromgo.New(rand_fromgo.NewSource(1)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n := m.NumParams(); n != test.params {
+			t.Errorf("Error %+v has %d params, should be %d.", test.cfg, n, test.params)
+		}
+		out, err := m.Forward(randomInput(3, 1, InputRows, InputCols))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if out.Dims() != 1 || out.Len() != Classes {
+			t.Errorf("Error output shape %v, should be [%d].", out.Shape, Classes)
+		}
+		gradIn, err := m.Backward(out)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if gradIn.Len() != InputRows*InputCols {
+			t.Errorf("Error input gradient shape %v.", gradIn.Shape)
+		}
+	}
+	if _, err := NewLeNet5(Config{Activation: "none"}, rand_fromgo.New(rand_fromgo.NewSource(1))); err == nil {
+		t.Errorf("Error unknown activation should return an error.")
+	}
+}
@@ -0,0 +1,276 @@
+package mynetwork
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/oneleo/LeNetPractice/mAiLab_0003/mytensor"
+)
+
+// 池化層的輸入與輸出皆為 [C, H, W] 的特徵圖，每一個通道各自獨立地以 Size x Size 的視窗、
+// 每次移動 Stride 格進行取樣，輸出大小為 ((H-Size)/Stride+1) x ((W-Size)/Stride+1)。
+
+// region struct
+
+// pool2D 是三種池化層共用的視窗設定與前一次 Forward 的輸入形狀。
+type pool2D struct {
+	Size   int
+	Stride int
+
+	// in 為前一次 Forward 的輸入，out 為其輸出（只用到形狀）。
+	in  *mytensor.Tensor
+	out *mytensor.Tensor
+}
+
+// MaxPool2D 是取視窗內最大值的池化層。
+type MaxPool2D struct {
+	pool2D
+	// argmax 記錄每一個輸出元素取自輸入的哪一個位置，反向傳播時梯度只會傳回該位置。
+	argmax []int
+}
+
+// AvgPool2D 是取視窗內平均值的池化層。
+type AvgPool2D struct {
+	pool2D
+}
+
+// Subsampling 是 LeNet-5 的 S2、S4 層：將視窗內的輸入加總後，乘上每個通道一個可訓練的係數，
+// 再加上每個通道一個可訓練的偏差值。激活函數則另外以激活層串接。
+type Subsampling struct {
+	pool2D
+	Coef *Param
+	Bias *Param
+}
+
+// endregion struct
+
+// region function
+
+// NewMaxPool2D 函數會建立一個視窗為 size x size、步長為 stride 的最大值池化層。
+func NewMaxPool2D(size, stride int) *MaxPool2D {
+	return &MaxPool2D{pool2D: pool2D{Size: size, Stride: stride}}
+}
+
+// NewAvgPool2D 函數會建立一個視窗為 size x size、步長為 stride 的平均池化層。
+func NewAvgPool2D(size, stride int) *AvgPool2D {
+	return &AvgPool2D{pool2D: pool2D{Size: size, Stride: stride}}
+}
+
+// NewSubsampling 函數會建立一個有 channels 個通道、視窗為 size x size 且不重疊的子取樣層。
+// 係數初始為 1/(size*size)，使其一開始等同平均池化；偏差值初始為 0。
+func NewSubsampling(channels, size int) *Subsampling {
+	s := &Subsampling{
+		pool2D: pool2D{Size: size, Stride: size},
+		Coef:   NewParam("coef", channels),
+		Bias:   NewParam("bias", channels),
+	}
+	s.Coef.Value.Fill(1 / float64(size*size))
+	return s
+}
+
+// endregion function
+
+// region method
+
+// forward 會確認輸入形狀並建立輸出 Tensor。
+func (p *pool2D) forward(layer string, in *mytensor.Tensor) (out *mytensor.Tensor, err error) {
+	if err = checkDims(layer, in, 3); err != nil {
+		return nil, err
+	}
+	if p.Size <= 0 || p.Stride <= 0 {
+		return nil, errors.New("Error: pooling size and stride must be positive")
+	}
+	h, w := in.Shape[1], in.Shape[2]
+	if h < p.Size || w < p.Size {
+		return nil, fmt.Errorf("Error: %s window %d is larger than input %dx%d", layer, p.Size, h, w)
+	}
+	p.in = in
+	p.out = mytensor.New(in.Shape[0], (h-p.Size)/p.Stride+1, (w-p.Size)/p.Stride+1)
+	return p.out, nil
+}
+
+// backward 會確認 gradOut 的形狀，並回傳與輸入同形狀的空梯度；method 為呼叫的方法名稱，只用於錯誤訊息。
+// Backward2 的二次導數也以此建立。
+func (p *pool2D) backward(layer, method string, gradOut *mytensor.Tensor) (gradIn *mytensor.Tensor, err error) {
+	if p.in == nil {
+		return nil, fmt.Errorf("Error: %s %s called before Forward", layer, method)
+	}
+	if err = checkShape(layer, gradOut, p.out); err != nil {
+		return nil, err
+	}
+	return mytensor.New(p.in.Shape...), nil
+}
+
+// each 會依序走訪每一個輸出位置 o，並將其視窗內的輸入位置交給 fn。
+func (p *pool2D) each(fn func(c, o int, window []int)) {
+	ch, h, w := p.in.Shape[0], p.in.Shape[1], p.in.Shape[2]
+	oh, ow := p.out.Shape[1], p.out.Shape[2]
+	window := make([]int, p.Size*p.Size)
+	for c := 0; c < ch; c++ {
+		for i := 0; i < oh; i++ {
+			for j := 0; j < ow; j++ {
+				for m := 0; m < p.Size; m++ {
+					for n := 0; n < p.Size; n++ {
+						window[m*p.Size+n] = (c*h+i*p.Stride+m)*w + j*p.Stride + n
+					}
+				}
+				fn(c, (c*oh+i)*ow+j, window)
+			}
+		}
+	}
+}
+
+// Forward 會回傳每一個視窗內的最大值，並記錄最大值的位置。
+func (l *MaxPool2D) Forward(in *mytensor.Tensor) (out *mytensor.Tensor, err error) {
+	if out, err = l.forward("MaxPool2D", in); err != nil {
+		return nil, err
+	}
+	l.argmax = make([]int, out.Len())
+	l.each(func(c, o int, window []int) {
+		best, max := window[0], math.Inf(-1)
+		for _, k := range window {
+			if in.Data[k] > max {
+				best, max = k, in.Data[k]
+			}
+		}
+		out.Data[o] = max
+		l.argmax[o] = best
+	})
+	return out, nil
+}
+
+// Backward 只會將梯度傳回 Forward 時取得最大值的位置。
+func (l *MaxPool2D) Backward(gradOut *mytensor.Tensor) (gradIn *mytensor.Tensor, err error) {
+	if gradIn, err = l.backward("MaxPool2D", "Backward", gradOut); err != nil {
+		return nil, err
+	}
+	for o, k := range l.argmax {
+		gradIn.Data[k] += gradOut.Data[o]
+	}
+	return gradIn, nil
+}
+
+// Backward2 只會將二次導數傳回 Forward 時取得最大值的位置。
+func (l *MaxPool2D) Backward2(hessOut *mytensor.Tensor) (hessIn *mytensor.Tensor, err error) {
+	if hessIn, err = l.backward("MaxPool2D", "Backward2", hessOut); err != nil {
+		return nil, err
+	}
+	for o, k := range l.argmax {
+		hessIn.Data[k] += hessOut.Data[o]
+	}
+	return hessIn, nil
+}
+
+// Argmax 會回傳前一次 Forward 中，每一個輸出元素取自輸入 Data 的位置。
+func (l *MaxPool2D) Argmax() []int { return l.argmax }
+
+// Params 會回傳 nil，因為最大值池化層沒有可訓練參數。
+func (l *MaxPool2D) Params() []*Param { return nil }
+
+// Forward 會回傳每一個視窗內的平均值。
+func (l *AvgPool2D) Forward(in *mytensor.Tensor) (out *mytensor.Tensor, err error) {
+	if out, err = l.forward("AvgPool2D", in); err != nil {
+		return nil, err
+	}
+	scale := 1 / float64(l.Size*l.Size)
+	l.each(func(c, o int, window []int) {
+		sum := 0.0
+		for _, k := range window {
+			sum += in.Data[k]
+		}
+		out.Data[o] = sum * scale
+	})
+	return out, nil
+}
+
+// Backward 會將梯度平均分配回視窗內的每一個位置。
+func (l *AvgPool2D) Backward(gradOut *mytensor.Tensor) (gradIn *mytensor.Tensor, err error) {
+	if gradIn, err = l.backward("AvgPool2D", "Backward", gradOut); err != nil {
+		return nil, err
+	}
+	scale := 1 / float64(l.Size*l.Size)
+	l.each(func(c, o int, window []int) {
+		for _, k := range window {
+			gradIn.Data[k] += gradOut.Data[o] * scale
+		}
+	})
+	return gradIn, nil
+}
+
+// Backward2 會將二次導數乘上 1/(Size*Size)² 後傳回視窗內的每一個位置。
+func (l *AvgPool2D) Backward2(hessOut *mytensor.Tensor) (hessIn *mytensor.Tensor, err error) {
+	if hessIn, err = l.backward("AvgPool2D", "Backward2", hessOut); err != nil {
+		return nil, err
+	}
+	scale := 1 / float64(l.Size*l.Size)
+	l.each(func(c, o int, window []int) {
+		for _, k := range window {
+			hessIn.Data[k] += hessOut.Data[o] * scale * scale
+		}
+	})
+	return hessIn, nil
+}
+
+// Params 會回傳 nil，因為平均池化層沒有可訓練參數。
+func (l *AvgPool2D) Params() []*Param { return nil }
+
+// Forward 會回傳 Coef[c] * (視窗內加總) + Bias[c]。
+func (l *Subsampling) Forward(in *mytensor.Tensor) (out *mytensor.Tensor, err error) {
+	if out, err = l.forward("Subsampling", in); err != nil {
+		return nil, err
+	}
+	if in.Shape[0] != l.Coef.Value.Len() {
+		return nil, fmt.Errorf("Error: Subsampling has %d channels, got %d", l.Coef.Value.Len(), in.Shape[0])
+	}
+	l.each(func(c, o int, window []int) {
+		sum := 0.0
+		for _, k := range window {
+			sum += in.Data[k]
+		}
+		out.Data[o] = l.Coef.Value.Data[c]*sum + l.Bias.Value.Data[c]
+	})
+	return out, nil
+}
+
+// Backward 會計算輸入梯度，並將係數與偏差值的梯度累加至 Coef.Grad 與 Bias.Grad。
+func (l *Subsampling) Backward(gradOut *mytensor.Tensor) (gradIn *mytensor.Tensor, err error) {
+	if gradIn, err = l.backward("Subsampling", "Backward", gradOut); err != nil {
+		return nil, err
+	}
+	l.each(func(c, o int, window []int) {
+		g := gradOut.Data[o]
+		sum := 0.0
+		for _, k := range window {
+			sum += l.in.Data[k]
+			gradIn.Data[k] += g * l.Coef.Value.Data[c]
+		}
+		l.Coef.Grad.Data[c] += g * sum
+		l.Bias.Grad.Data[c] += g
+	})
+	return gradIn, nil
+}
+
+// Backward2 會回傳輸入的二次導數 Coef[c]² * h，並將係數的 h * (視窗內加總)² 與偏差值的 h 累加至 Hess。
+func (l *Subsampling) Backward2(hessOut *mytensor.Tensor) (hessIn *mytensor.Tensor, err error) {
+	if hessIn, err = l.backward("Subsampling", "Backward2", hessOut); err != nil {
+		return nil, err
+	}
+	l.each(func(c, o int, window []int) {
+		h := hessOut.Data[o]
+		coef := l.Coef.Value.Data[c]
+		sum := 0.0
+		for _, k := range window {
+			sum += l.in.Data[k]
+			hessIn.Data[k] += h * coef * coef
+		}
+		l.Coef.Hess.Data[c] += h * sum * sum
+		l.Bias.Hess.Data[c] += h
+	})
+	return hessIn, nil
+}
+
+// Params 會回傳子取樣層的係數與偏差值。
+func (l *Subsampling) Params() []*Param { return []*Param{l.Coef, l.Bias} }
+
+// endregion method
@@ -0,0 +1,108 @@
+// How to use:
+//
+// 1. Testing
+// (1) > cd "%GOPATH%\src\github.com\oneleo\LeNetPractice\mAiLab_0003\mynetwork"
+// or (1) $ cd "$GOPATH/src/github.com/oneleo/LeNetPractice/mAiLab_0003/mynetwork"
+// (2) $> go test -v
+
+package mynetwork
+
+import (
+	"math"
+	"testing"
+
+	"github.com/oneleo/LeNetPractice/mAiLab_0003/mytensor"
+)
+
+// poolInput 會回傳一個形狀為 [1, 4, 4]、元素為 0～15 的測試輸入。
+func poolInput() *mytensor.Tensor {
+	in := mytensor.New(1, 4, 4)
+	for i := range in.Data {
+		in.Data[i] = float64(i)
+	}
+	return in
+}
+
+// Test_MaxPool2D 是測試最大值池化層的輸出、argmax 記錄以及梯度只傳回最大值位置。
+func Test_MaxPool2D(t *testing.T) {
+	l := NewMaxPool2D(2, 2)
+	out, err := l.Forward(poolInput())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []float64{5, 7, 13, 15}
+	for i, w := range want {
+		if out.Data[i] != w {
+			t.Errorf("Error output[%d] = %g, should be %g.", i, out.Data[i], w)
+		}
+		if l.Argmax()[i] != int(w) {
+			t.Errorf("Error argmax[%d] = %d, should be %g.", i, l.Argmax()[i], w)
+		}
+	}
+	grad := mytensor.New(out.Shape...)
+	grad.Fill(1)
+	gradIn, err := l.Backward(grad)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, g := range gradIn.Data {
+		w := 0.0
+		if i == 5 || i == 7 || i == 13 || i == 15 {
+			w = 1
+		}
+		if g != w {
+			t.Errorf("Error gradIn[%d] = %g, should be %g.", i, g, w)
+		}
+	}
+}
+
+// Test_AvgPool2D 是測試平均池化層在重疊視窗下的輸出與梯度。
+func Test_AvgPool2D(t *testing.T) {
+	l := NewAvgPool2D(2, 1)
+	out, err := l.Forward(poolInput())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out.Shape[1] != 3 || out.Shape[2] != 3 {
+		t.Fatalf("Error output shape %v, should be [1 3 3].", out.Shape)
+	}
+	if out.Data[0] != 2.5 || out.Data[8] != 12.5 {
+		t.Errorf("Error output %v.", out.Data)
+	}
+	grad := mytensor.New(out.Shape...)
+	grad.Fill(1)
+	gradIn, _ := l.Backward(grad)
+	// 中央的輸入位置被四個視窗涵蓋，角落只被一個視窗涵蓋。
+	if gradIn.Data[5] != 1 || gradIn.Data[0] != 0.25 {
+		t.Errorf("Error gradIn %v.", gradIn.Data)
+	}
+}
+
+// Test_Subsampling 是測試 LeNet 子取樣層的輸出，以及係數與偏差值梯度的累加。
+func Test_Subsampling(t *testing.T) {
+	l := NewSubsampling(1, 2)
+	l.Coef.Value.Data[0] = 2
+	l.Bias.Value.Data[0] = 1
+	out, err := l.Forward(poolInput())
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 左上視窗 0+1+4+5 = 10，輸出為 2*10+1。
+	if out.Data[0] != 21 {
+		t.Errorf("Error output[0] = %g, should be 21.", out.Data[0])
+	}
+	grad := mytensor.New(out.Shape...)
+	grad.Fill(1)
+	for n := 0; n < 2; n++ {
+		if _, err = l.Backward(grad); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// 所有輸入加總為 120，兩次 Backward 累加後為 240。
+	if math.Abs(l.Coef.Grad.Data[0]-240) > 1e-12 || l.Bias.Grad.Data[0] != 8 {
+		t.Errorf("Error grads coef = %g, bias = %g.", l.Coef.Grad.Data[0], l.Bias.Grad.Data[0])
+	}
+	if _, err = l.Forward(mytensor.New(2, 4, 4)); err == nil {
+		t.Errorf("Error channel mismatch should return an error.")
+	}
+}
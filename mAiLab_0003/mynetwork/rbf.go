@@ -0,0 +1,211 @@
+package mynetwork
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/oneleo/LeNetPractice/mAiLab_0003/mytensor"
+)
+
+// 每一個數字原型為 7x12 的點陣圖，攤平後正好是 F6 的 84 個輸出。
+const (
+	PrototypeCols = 7
+	PrototypeRows = 12
+)
+
+// region variable
+
+// DigitBitmaps 是 LeNet-5 論文 Fig. 3 風格的 0～9 數字點陣圖，'#' 為前景 (+1)，'.' 為背景 (-1)。
+// 論文以這種「風格化」的影像作為 RBF 單元的固定原型，使相似的字元（如 O 與 0）的輸出也相近。
+var DigitBitmaps = [Classes][PrototypeRows]string{
+	{".......", "..###..", ".#...#.", "#.....#", "#.....#", "#.....#", "#.....#", "#.....#", "#.....#", ".#...#.", "..###..", "......."},
+	{".......", "...#...", "..##...", ".#.#...", "...#...", "...#...", "...#...", "...#...", "...#...", "...#...", ".#####.", "......."},
+	{".......", "..###..", ".#...#.", "#.....#", "......#", ".....#.", "....#..", "...#...", "..#....", ".#.....", "#######", "......."},
+	{".......", ".#####.", "......#", "......#", ".....#.", "..###..", ".....#.", "......#", "......#", "......#", ".#####.", "......."},
+	{".......", "....##.", "...#.#.", "..#..#.", ".#...#.", "#....#.", "#######", ".....#.", ".....#.", ".....#.", ".....#.", "......."},
+	{".......", "#######", "#......", "#......", "#####..", ".....#.", "......#", "......#", "......#", ".....#.", "#####..", "......."},
+	{".......", "...###.", "..#....", ".#.....", "#......", "#.###..", "##...#.", "#.....#", "#.....#", ".#...#.", "..###..", "......."},
+	{".......", "#######", "......#", ".....#.", ".....#.", "....#..", "....#..", "...#...", "...#...", "..#....", "..#....", "......."},
+	{".......", "..###..", ".#...#.", "#.....#", ".#...#.", "..###..", ".#...#.", "#.....#", "#.....#", ".#...#.", "..###..", "......."},
+	{".......", "..###..", ".#...#.", "#.....#", "#.....#", ".#...##", "..###.#", "......#", ".....#.", "....#..", ".###...", "......."},
+}
+
+// endregion variable
+
+// region struct
+
+// RBF 是 LeNet-5 的輸出層：每一個輸出單元 i 計算輸入 x 與其原型 w_i 的歐氏距離平方
+// y_i = Σ_j (x_j - w_ij)²，因此輸出越「小」代表越像該類別。
+// 原型是固定的，不會被訓練，所以 Params 回傳 nil。
+type RBF struct {
+	// Prototypes 形狀為 [Classes, PrototypeRows*PrototypeCols]。
+	Prototypes *mytensor.Tensor
+
+	in *mytensor.Tensor
+}
+
+// MAPLoss 是論文式 (9) 的 MAP 損失函數：
+// E = y_D + log(e^-J + Σ_i e^-y_i)，D 為正確類別。
+// 第一項拉近正確類別的距離，第二項則把其他類別推開；J 是一個常數，
+// 避免所有 RBF 輸出都已經很大時，第二項把距離推得更遠。
+type MAPLoss struct {
+	J float64
+}
+
+// endregion struct
+
+// region function
+
+// Prototypes 函數會將 DigitBitmaps 轉成 RBF 使用的 [Classes, 84] 原型，前景為 +1、背景為 -1。
+func Prototypes() (t *mytensor.Tensor, err error) {
+	t = mytensor.New(Classes, PrototypeRows*PrototypeCols)
+	for c, bitmap := range DigitBitmaps {
+		for i, row := range bitmap {
+			if len(row) != PrototypeCols {
+				return nil, fmt.Errorf("Error: bitmap of digit %d row %d has %d columns", c, i, len(row))
+			}
+			for j, ch := range row {
+				v := -1.0
+				if ch == '#' {
+					v = 1
+				}
+				t.Set(v, c, i*PrototypeCols+j)
+			}
+		}
+	}
+	return t, nil
+}
+
+// NewRBF 函數會建立一個以 DigitBitmaps 為原型的 RBF 輸出層。
+func NewRBF() (l *RBF, err error) {
+	p, err := Prototypes()
+	if err != nil {
+		return nil, err
+	}
+	return &RBF{Prototypes: p}, nil
+}
+
+// NewMAPLoss 函數會建立常數為 j 的 MAP 損失函數。
+func NewMAPLoss(j float64) *MAPLoss {
+	return &MAPLoss{J: j}
+}
+
+// ArgMin 函數會回傳 x 最小值的位置，RBF 輸出時即為預測的類別。
+func ArgMin(x []float64) (n int) {
+	for i, v := range x {
+		if v < x[n] {
+			n = i
+		}
+	}
+	return n
+}
+
+// endregion function
+
+// region method
+
+// Forward 會回傳 in 與每一個原型的歐氏距離平方。
+func (l *RBF) Forward(in *mytensor.Tensor) (out *mytensor.Tensor, err error) {
+	classes, dim := l.Prototypes.Shape[0], l.Prototypes.Shape[1]
+	if in.Len() != dim {
+		return nil, fmt.Errorf("Error: RBF expects %d inputs, got shape %v", dim, in.Shape)
+	}
+	l.in = in
+	out = mytensor.New(classes)
+	for c := 0; c < classes; c++ {
+		w := l.Prototypes.Data[c*dim : (c+1)*dim]
+		sum := 0.0
+		for j, x := range in.Data {
+			d := x - w[j]
+			sum += d * d
+		}
+		out.Data[c] = sum
+	}
+	return out, nil
+}
+
+// Backward 會回傳輸入梯度 gradIn_j = Σ_i g_i * 2(x_j - w_ij)。
+func (l *RBF) Backward(gradOut *mytensor.Tensor) (gradIn *mytensor.Tensor, err error) {
+	if l.in == nil {
+		return nil, errors.New("Error: RBF Backward called before Forward")
+	}
+	classes, dim := l.Prototypes.Shape[0], l.Prototypes.Shape[1]
+	if gradOut.Len() != classes {
+		return nil, fmt.Errorf("Error: RBF expects %d output gradients, got shape %v", classes, gradOut.Shape)
+	}
+	gradIn = mytensor.New(l.in.Shape...)
+	for c, g := range gradOut.Data {
+		w := l.Prototypes.Data[c*dim : (c+1)*dim]
+		for j, x := range l.in.Data {
+			gradIn.Data[j] += 2 * g * (x - w[j])
+		}
+	}
+	return gradIn, nil
+}
+
+// Backward2 會以一次導數的平方傳回二次導數 hessIn_j = Σ_i h_i * 4(x_j - w_ij)²，忽略 ∂²y_i/∂x_j² = 2 的項。
+func (l *RBF) Backward2(hessOut *mytensor.Tensor) (hessIn *mytensor.Tensor, err error) {
+	if l.in == nil {
+		return nil, errors.New("Error: RBF Backward2 called before Forward")
+	}
+	classes, dim := l.Prototypes.Shape[0], l.Prototypes.Shape[1]
+	if hessOut.Len() != classes {
+		return nil, fmt.Errorf("Error: RBF expects %d output second derivatives, got shape %v", classes, hessOut.Shape)
+	}
+	hessIn = mytensor.New(l.in.Shape...)
+	for c, h := range hessOut.Data {
+		w := l.Prototypes.Data[c*dim : (c+1)*dim]
+		for j, x := range l.in.Data {
+			d := x - w[j]
+			hessIn.Data[j] += 4 * h * d * d
+		}
+	}
+	return hessIn, nil
+}
+
+// Params 會回傳 nil，因為 RBF 的原型是固定的。
+func (l *RBF) Params() []*Param { return nil }
+
+// Loss 會回傳 RBF 輸出 out 在正確類別為 label 時的 MAP 損失，以及對 out 的梯度：
+// ∂E/∂y_i = δ_iD - e^-y_i / (e^-J + Σ_k e^-y_k)。
+func (l *MAPLoss) Loss(out *mytensor.Tensor, label byte) (loss float64, grad *mytensor.Tensor, err error) {
+	if err = checkLabel(label, out.Len()); err != nil {
+		return 0, nil, err
+	}
+	// 以 log-sum-exp 計算 log(e^-J + Σ e^-y_i)，避免 y 很大時 e^-y 下溢為 0。
+	neg := make([]float64, out.Len()+1)
+	neg[0] = -l.J
+	for i, y := range out.Data {
+		neg[i+1] = -y
+	}
+	lse := LogSumExp(neg)
+	loss = out.Data[label] + lse
+	grad = mytensor.New(out.Shape...)
+	for i, y := range out.Data {
+		grad.Data[i] = -math.Exp(-y - lse)
+	}
+	grad.Data[label]++
+	return loss, grad, nil
+}
+
+// Hess 會回傳 MAP 損失對 out 的二次導數 q_i(1-q_i)，q_i = e^-y_i / (e^-J + Σ_k e^-y_k)。
+func (l *MAPLoss) Hess(out *mytensor.Tensor, label byte) (hess *mytensor.Tensor, err error) {
+	if err = checkLabel(label, out.Len()); err != nil {
+		return nil, err
+	}
+	neg := make([]float64, out.Len()+1)
+	neg[0] = -l.J
+	for i, y := range out.Data {
+		neg[i+1] = -y
+	}
+	lse := LogSumExp(neg)
+	hess = mytensor.New(out.Shape...)
+	for i, y := range out.Data {
+		q := math.Exp(-y - lse)
+		hess.Data[i] = q * (1 - q)
+	}
+	return hess, nil
+}
+
+// endregion method
@@ -0,0 +1,88 @@
+package mynetwork
+
+import (
+	"math"
+	"testing"
+
+	"github.com/oneleo/LeNetPractice/mAiLab_0002/rand_fromgo"
+	"github.com/oneleo/LeNetPractice/mAiLab_0003/mytensor"
+)
+
+// Test_Prototypes 是測試每一個數字點陣圖皆為 7x12，且任兩個原型都不相同。
+func Test_Prototypes(t *testing.T) {
+	p, err := Prototypes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	rbf := &RBF{Prototypes: p}
+	for c := 0; c < Classes; c++ {
+		proto, _ := mytensor.FromSlice(p.Data[c*84:(c+1)*84], 84)
+		out, _ := rbf.Forward(proto)
+		// 原型與自己的距離為 0，與其他原型的距離必須大於 0。
+		if ArgMin(out.Data) != c || out.Data[c] != 0 {
+			t.Errorf("Error prototype %d distances %v.", c, out.Data)
+		}
+		for o, d := range out.Data {
+			if o != c && d == 0 {
+				t.Errorf("Error prototypes %d and %d are identical.", c, o)
+			}
+		}
+	}
+}
+
+// Test_RBFGrad 是以數值梯度檢查 RBF 層的輸入梯度。
+func Test_RBFGrad(t *testing.T) {
+	rbf, _ := NewRBF()
+	checkLayerGrad(t, rbf, randomInput(4, 84), 1e-6)
+}
+
+// Test_MAPLoss 是以數值梯度檢查 MAP 損失，並確認 y 很大時不會下溢。
+func Test_MAPLoss(t *testing.T) {
+	l := NewMAPLoss(0.1)
+	out := randomInput(5, Classes)
+	for i := range out.Data {
+		out.Data[i] = math.Abs(out.Data[i]) * 10
+	}
+	loss, grad, err := l.Loss(out, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	const h = 1e-6
+	for i := range out.Data {
+		old := out.Data[i]
+		out.Data[i] = old + h
+		fp, _, _ := l.Loss(out, 3)
+		out.Data[i] = old - h
+		fm, _, _ := l.Loss(out, 3)
+		out.Data[i] = old
+		if num := (fp - fm) / (2 * h); math.Abs(num-grad.Data[i]) > 1e-6 {
+			t.Errorf("Error grad[%d]: analytic %g, numeric %g.", i, grad.Data[i], num)
+		}
+	}
+	// 當所有距離都很大時，損失約為 y_D - J。
+	out.Fill(1e4)
+	if loss, _, _ = l.Loss(out, 3); math.Abs(loss-(1e4-0.1)) > 1e-6 {
+		t.Errorf("Error loss = %g, should be about %g.", loss, 1e4-0.1)
+	}
+	if _, _, err = l.Loss(out, 10); err == nil {
+		t.Errorf("Error out-of-range label should return an error.")
+	}
+}
+
+// Test_PaperLeNet5 是測試使用 RBF 輸出的 LeNet-5 參數數量與預測方式。
+func Test_PaperLeNet5(t *testing.T) {
+	m, err := NewLeNet5(PaperConfig(), rand_fromgo.New(rand_fromgo.NewSource(1)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 論文的 LeNet-5 共有 60,000 個可訓練參數，RBF 原型不計入。
+	if n := m.NumParams(); n != 60000 {
+		t.Errorf("Error paper LeNet-5 has %d params, should be 60000.", n)
+	}
+	in := randomInput(6, 1, InputRows, InputCols)
+	out, _ := m.Forward(in)
+	label, _ := m.Predict(in)
+	if label != ArgMin(out.Data) {
+		t.Errorf("Error RBF prediction %d, should be the nearest prototype %d.", label, ArgMin(out.Data))
+	}
+}
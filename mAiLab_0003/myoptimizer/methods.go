@@ -0,0 +1,287 @@
+package myoptimizer
+
+import (
+	"errors"
+	"math"
+
+	"github.com/oneleo/LeNetPractice/mAiLab_0003/mynetwork"
+	"github.com/oneleo/LeNetPractice/mAiLab_0003/mytensor"
+)
+
+// region struct
+
+// SGD 是隨機梯度下降：w -= LR * g。
+type SGD struct {
+	base
+}
+
+// Momentum 是動量法：v = Mu*v - LR*g，w += v。
+// Nesterov 為 true 時改用 Nesterov 加速梯度：w += Mu*v - LR*g（以更新後的 v 往前看一步）。
+type Momentum struct {
+	base
+	Mu       float64
+	Nesterov bool
+}
+
+// AdaGrad 會累加每一個參數的梯度平方 G，並以 LR/(sqrt(G)+Eps) 作為該參數的學習率。
+type AdaGrad struct {
+	base
+	Eps float64
+}
+
+// RMSProp 以梯度平方的指數移動平均 E = Rho*E + (1-Rho)*g² 取代 AdaGrad 的累加，
+// 學習率不會隨時間單調遞減。
+type RMSProp struct {
+	base
+	Rho float64
+	Eps float64
+}
+
+// Adam 同時保存梯度的一階動差 m 與二階動差 v 的指數移動平均，並做偏差修正。
+type Adam struct {
+	base
+	Beta1 float64
+	Beta2 float64
+	Eps   float64
+}
+
+// SDLM 是仿照 LeNet 論文附錄 C 隨機對角 Levenberg-Marquardt 法的最佳化器：
+// 每一個參數 k 的學習率為 LR/(Mu + h_kk)，h_kk 為該參數曲率的估計。
+// h_kk 與論文相同，是以 mynetwork.Model.Backward2 反向傳播的對角 Gauss-Newton 二次導數（Hessian 對角項的近似）。
+// 呼叫端需在少量樣本（論文約 500 筆）上逐筆 ZeroHess、Forward 並以損失的 Hess 呼叫 Backward2，
+// 再呼叫 AccumulateHess 更新估計，估計完後再以 Step 正常訓練。
+type SDLM struct {
+	base
+	Mu float64
+	// Gamma 為 h_kk 移動平均的權重，論文使用 0.01 左右。
+	Gamma float64
+	// estimated 記錄 AccumulateHess 是否至少呼叫過一次。
+	estimated bool
+}
+
+// endregion struct
+
+// region function
+
+// NewSGD 函數會建立學習率為 lr 的 SGD。
+func NewSGD(lr float64) *SGD {
+	return &SGD{base: base{Options: Options{LR: lr}}}
+}
+
+// NewMomentum 函數會建立學習率為 lr、動量係數為 mu 的動量法。
+func NewMomentum(lr, mu float64) *Momentum {
+	return &Momentum{base: base{Options: Options{LR: lr}}, Mu: mu}
+}
+
+// NewNesterov 函數會建立學習率為 lr、動量係數為 mu 的 Nesterov 加速梯度法。
+func NewNesterov(lr, mu float64) *Momentum {
+	return &Momentum{base: base{Options: Options{LR: lr}}, Mu: mu, Nesterov: true}
+}
+
+// NewAdaGrad 函數會建立學習率為 lr 的 AdaGrad。
+func NewAdaGrad(lr float64) *AdaGrad {
+	return &AdaGrad{base: base{Options: Options{LR: lr}}, Eps: 1e-8}
+}
+
+// NewRMSProp 函數會建立學習率為 lr、衰減率為 0.9 的 RMSProp。
+func NewRMSProp(lr float64) *RMSProp {
+	return &RMSProp{base: base{Options: Options{LR: lr}}, Rho: 0.9, Eps: 1e-8}
+}
+
+// NewAdam 函數會建立學習率為 lr、β1 = 0.9、β2 = 0.999 的 Adam。
+func NewAdam(lr float64) *Adam {
+	return &Adam{base: base{Options: Options{LR: lr}}, Beta1: 0.9, Beta2: 0.999, Eps: 1e-8}
+}
+
+// NewSDLM 函數會建立全域學習率為 lr、μ = 0.02 的隨機對角 Levenberg-Marquardt 法（論文的設定）。
+func NewSDLM(lr float64) *SDLM {
+	return &SDLM{base: base{Options: Options{LR: lr}}, Mu: 0.02, Gamma: 0.01}
+}
+
+// endregion function
+
+// region method
+
+// CheckState 會確認 slots 可以還原成 SGD 的狀態，不會修改狀態。
+func (o *SGD) CheckState(params []*mynetwork.Param, step int, slots [][]*mytensor.Tensor) (err error) {
+	return o.checkState(params, step, slots, 0)
+}
+
+// SetState 會還原 SGD 的狀態，SGD 只有更新次數，沒有狀態 Tensor。
+func (o *SGD) SetState(params []*mynetwork.Param, step int, slots [][]*mytensor.Tensor) (err error) {
+	return o.setState(params, step, slots, 0)
+}
+
+// CheckState 會確認 slots 可以還原成動量法的狀態，不會修改狀態。
+func (o *Momentum) CheckState(params []*mynetwork.Param, step int, slots [][]*mytensor.Tensor) (err error) {
+	return o.checkState(params, step, slots, 1)
+}
+
+// SetState 會還原動量法的狀態（每組參數 1 個速度 v）。
+func (o *Momentum) SetState(params []*mynetwork.Param, step int, slots [][]*mytensor.Tensor) (err error) {
+	return o.setState(params, step, slots, 1)
+}
+
+// CheckState 會確認 slots 可以還原成 AdaGrad 的狀態，不會修改狀態。
+func (o *AdaGrad) CheckState(params []*mynetwork.Param, step int, slots [][]*mytensor.Tensor) (err error) {
+	return o.checkState(params, step, slots, 1)
+}
+
+// SetState 會還原 AdaGrad 的狀態（每組參數 1 個梯度平方和 G）。
+func (o *AdaGrad) SetState(params []*mynetwork.Param, step int, slots [][]*mytensor.Tensor) (err error) {
+	return o.setState(params, step, slots, 1)
+}
+
+// CheckState 會確認 slots 可以還原成 RMSProp 的狀態，不會修改狀態。
+func (o *RMSProp) CheckState(params []*mynetwork.Param, step int, slots [][]*mytensor.Tensor) (err error) {
+	return o.checkState(params, step, slots, 1)
+}
+
+// SetState 會還原 RMSProp 的狀態（每組參數 1 個梯度平方平均 E）。
+func (o *RMSProp) SetState(params []*mynetwork.Param, step int, slots [][]*mytensor.Tensor) (err error) {
+	return o.setState(params, step, slots, 1)
+}
+
+// CheckState 會確認 slots 可以還原成 Adam 的狀態，不會修改狀態。
+func (o *Adam) CheckState(params []*mynetwork.Param, step int, slots [][]*mytensor.Tensor) (err error) {
+	return o.checkState(params, step, slots, 2)
+}
+
+// SetState 會還原 Adam 的狀態（每組參數 2 個動差 m、v）。
+func (o *Adam) SetState(params []*mynetwork.Param, step int, slots [][]*mytensor.Tensor) (err error) {
+	return o.setState(params, step, slots, 2)
+}
+
+// Step 會以 w -= LR * g 更新參數。
+func (o *SGD) Step(params []*mynetwork.Param) (err error) {
+	grads, err := o.grads(params)
+	if err != nil {
+		return err
+	}
+	for n, p := range params {
+		for i, g := range grads[n] {
+			p.Value.Data[i] -= o.LR * g
+		}
+	}
+	return nil
+}
+
+// Step 會以動量法（或 Nesterov）更新參數。
+func (o *Momentum) Step(params []*mynetwork.Param) (err error) {
+	grads, err := o.grads(params)
+	if err != nil {
+		return err
+	}
+	for n, p := range params {
+		v := o.slots(p, 1)[0].Data
+		for i, g := range grads[n] {
+			v[i] = o.Mu*v[i] - o.LR*g
+			if o.Nesterov {
+				p.Value.Data[i] += o.Mu*v[i] - o.LR*g
+			} else {
+				p.Value.Data[i] += v[i]
+			}
+		}
+	}
+	return nil
+}
+
+// Step 會以 AdaGrad 更新參數。
+func (o *AdaGrad) Step(params []*mynetwork.Param) (err error) {
+	grads, err := o.grads(params)
+	if err != nil {
+		return err
+	}
+	for n, p := range params {
+		sum := o.slots(p, 1)[0].Data
+		for i, g := range grads[n] {
+			sum[i] += g * g
+			p.Value.Data[i] -= o.LR * g / (math.Sqrt(sum[i]) + o.Eps)
+		}
+	}
+	return nil
+}
+
+// Step 會以 RMSProp 更新參數。
+func (o *RMSProp) Step(params []*mynetwork.Param) (err error) {
+	grads, err := o.grads(params)
+	if err != nil {
+		return err
+	}
+	for n, p := range params {
+		avg := o.slots(p, 1)[0].Data
+		for i, g := range grads[n] {
+			avg[i] = o.Rho*avg[i] + (1-o.Rho)*g*g
+			p.Value.Data[i] -= o.LR * g / (math.Sqrt(avg[i]) + o.Eps)
+		}
+	}
+	return nil
+}
+
+// Step 會以 Adam 更新參數。
+func (o *Adam) Step(params []*mynetwork.Param) (err error) {
+	grads, err := o.grads(params)
+	if err != nil {
+		return err
+	}
+	// 偏差修正：一開始 m、v 都是 0，前幾步的估計會偏小。
+	c1 := 1 - math.Pow(o.Beta1, float64(o.t))
+	c2 := 1 - math.Pow(o.Beta2, float64(o.t))
+	for n, p := range params {
+		s := o.slots(p, 2)
+		m, v := s[0].Data, s[1].Data
+		for i, g := range grads[n] {
+			m[i] = o.Beta1*m[i] + (1-o.Beta1)*g
+			v[i] = o.Beta2*v[i] + (1-o.Beta2)*g*g
+			p.Value.Data[i] -= o.LR * (m[i] / c1) / (math.Sqrt(v[i]/c2) + o.Eps)
+		}
+	}
+	return nil
+}
+
+// AccumulateHess 會以參數目前的 Hess 更新每一個參數的曲率估計 h = (1-Gamma)*h + Gamma*Hess。
+// Hess 應為單一樣本以 Backward2 反向傳播的二次導數。
+func (o *SDLM) AccumulateHess(params []*mynetwork.Param) {
+	for _, p := range params {
+		h := o.slots(p, 1)[0].Data
+		for i, d := range p.Hess.Data {
+			h[i] = (1-o.Gamma)*h[i] + o.Gamma*d
+		}
+	}
+	o.estimated = true
+}
+
+// CheckState 會確認 slots 可以還原成 SDLM 的狀態，不會修改狀態。
+func (o *SDLM) CheckState(params []*mynetwork.Param, step int, slots [][]*mytensor.Tensor) (err error) {
+	return o.checkState(params, step, slots, 1)
+}
+
+// SetState 會還原 SDLM 的狀態（每組參數 1 個曲率估計）；若還原的狀態包含曲率估計，則視為已估計過。
+func (o *SDLM) SetState(params []*mynetwork.Param, step int, slots [][]*mytensor.Tensor) (err error) {
+	if err = o.setState(params, step, slots, 1); err != nil {
+		return err
+	}
+	o.estimated = len(o.state) > 0
+	return nil
+}
+
+// Step 會以每個參數各自的學習率 LR/(Mu + h_kk) 更新參數。
+// 若尚未呼叫過 AccumulateHess，則回傳錯誤。
+func (o *SDLM) Step(params []*mynetwork.Param) (err error) {
+	if !o.estimated {
+		return errors.New("Error: SDLM Step called before AccumulateHess")
+	}
+	grads, err := o.grads(params)
+	if err != nil {
+		return err
+	}
+	for n, p := range params {
+		h := o.slots(p, 1)[0].Data
+		for i, g := range grads[n] {
+			p.Value.Data[i] -= o.LR / (o.Mu + h[i]) * g
+		}
+	}
+	return nil
+}
+
+// endregion method
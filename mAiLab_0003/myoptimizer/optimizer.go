@@ -0,0 +1,184 @@
+package myoptimizer
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/oneleo/LeNetPractice/mAiLab_0003/mynetwork"
+	"github.com/oneleo/LeNetPractice/mAiLab_0003/mytensor"
+)
+
+// region interface
+
+// Optimizer 會根據 mynetwork.Param 內累加的梯度 Grad 更新參數值 Value。
+// Step 不會清除梯度，呼叫端在下一個 mini-batch 前需自行呼叫 mynetwork.ZeroGrads。
+type Optimizer interface {
+	Step(params []*mynetwork.Param) error
+	LearningRate() float64
+	SetLearningRate(lr float64)
+}
+
+// Stateful 是可以匯出與還原內部狀態的最佳化器，供檢查點保存後繼續訓練。
+// CheckState 只確認狀態是否可以還原，不會修改最佳化器；SetState 失敗時同樣不會修改最佳化器。
+// 本套件的所有最佳化器都實作 Stateful。
+type Stateful interface {
+	State(params []*mynetwork.Param) (step int, slots [][]*mytensor.Tensor)
+	CheckState(params []*mynetwork.Param, step int, slots [][]*mytensor.Tensor) error
+	SetState(params []*mynetwork.Param, step int, slots [][]*mytensor.Tensor) error
+}
+
+// endregion interface
+
+// region struct
+
+// Options 是所有最佳化器共用的設定。
+type Options struct {
+	// LR 為學習率。
+	LR float64
+	// WeightDecay 為 L2 正則化係數，更新前會將 WeightDecay*w 加到梯度上。
+	WeightDecay float64
+	// ClipNorm 大於 0 時，若所有參數梯度合起來的 L2 範數超過 ClipNorm，就等比例縮小到 ClipNorm。
+	ClipNorm float64
+	// ClipValue 大於 0 時，每一個梯度元素會被限制在 [-ClipValue, ClipValue]。
+	ClipValue float64
+}
+
+// base 保存共用設定、更新次數，以及每一組參數的狀態（如動量、平方梯度平均）。
+type base struct {
+	Options
+	t     int
+	state map[*mynetwork.Param][]*mytensor.Tensor
+}
+
+// endregion struct
+
+// region method
+
+// LearningRate 會回傳目前的學習率。
+func (b *base) LearningRate() float64 { return b.LR }
+
+// SetLearningRate 會設定學習率，供學習率排程使用。
+func (b *base) SetLearningRate(lr float64) { b.LR = lr }
+
+// slots 會回傳參數 p 的 n 個狀態 Tensor，第一次使用時建立並清為 0。
+func (b *base) slots(p *mynetwork.Param, n int) []*mytensor.Tensor {
+	if b.state == nil {
+		b.state = make(map[*mynetwork.Param][]*mytensor.Tensor)
+	}
+	s, ok := b.state[p]
+	if !ok {
+		s = make([]*mytensor.Tensor, n)
+		for i := range s {
+			s[i] = mytensor.New(p.Value.Shape...)
+		}
+		b.state[p] = s
+	}
+	return s
+}
+
+// State 會回傳已更新的次數，以及 params 中每一組參數的狀態 Tensor（依 params 的順序，尚未更新過的參數為空）。
+func (b *base) State(params []*mynetwork.Param) (step int, slots [][]*mytensor.Tensor) {
+	slots = make([][]*mytensor.Tensor, len(params))
+	for n, p := range params {
+		slots[n] = b.state[p]
+	}
+	return b.t, slots
+}
+
+// checkState 會確認 State 匯出的資料可以還原，供各最佳化器的 CheckState 與 SetState 使用。
+// 每一組參數的狀態 Tensor 必須為空（尚未更新過）或恰好 want 個，且形狀與對應參數相同。
+func (b *base) checkState(params []*mynetwork.Param, step int, slots [][]*mytensor.Tensor, want int) (err error) {
+	if len(slots) != len(params) {
+		return fmt.Errorf("Error: optimizer state has %d parameters, should be %d", len(slots), len(params))
+	}
+	if step < 0 {
+		return fmt.Errorf("Error: optimizer step %d must not be negative", step)
+	}
+	for n, p := range params {
+		if len(slots[n]) == 0 {
+			continue
+		}
+		if len(slots[n]) != want {
+			return fmt.Errorf("Error: optimizer state of %s has %d slots, should be %d", p.Name, len(slots[n]), want)
+		}
+		for i, t := range slots[n] {
+			if t == nil || !t.SameShape(p.Value) {
+				return fmt.Errorf("Error: optimizer state %d of %s does not match shape %v", i, p.Name, p.Value.Shape)
+			}
+		}
+	}
+	return nil
+}
+
+// setState 會在 checkState 通過後以 State 匯出的資料還原狀態，不通過時不會修改任何狀態。
+// 狀態會複製一份，之後的更新不會修改呼叫端的 Tensor。
+func (b *base) setState(params []*mynetwork.Param, step int, slots [][]*mytensor.Tensor, want int) (err error) {
+	if err = b.checkState(params, step, slots, want); err != nil {
+		return err
+	}
+	state := make(map[*mynetwork.Param][]*mytensor.Tensor)
+	for n, p := range params {
+		if len(slots[n]) == 0 {
+			continue
+		}
+		s := make([]*mytensor.Tensor, want)
+		for i, t := range slots[n] {
+			s[i] = t.Clone()
+		}
+		state[p] = s
+	}
+	b.t, b.state = step, state
+	return nil
+}
+
+// grads 會回傳套用權重衰減與梯度裁剪後的梯度副本，不會修改 p.Grad，並將更新次數加 1。
+func (b *base) grads(params []*mynetwork.Param) (grads [][]float64, err error) {
+	if b.LR < 0 {
+		return nil, errors.New("Error: learning rate must not be negative")
+	}
+	grads = make([][]float64, len(params))
+	norm := 0.0
+	for n, p := range params {
+		if p.Grad.Len() != p.Value.Len() {
+			return nil, errors.New("Error: parameter " + p.Name + " has mismatched gradient size")
+		}
+		g := make([]float64, p.Grad.Len())
+		for i, v := range p.Grad.Data {
+			v += b.WeightDecay * p.Value.Data[i]
+			if b.ClipValue > 0 {
+				v = math.Max(-b.ClipValue, math.Min(b.ClipValue, v))
+			}
+			g[i] = v
+			norm += v * v
+		}
+		grads[n] = g
+	}
+	if norm = math.Sqrt(norm); b.ClipNorm > 0 && norm > b.ClipNorm {
+		scale := b.ClipNorm / norm
+		for _, g := range grads {
+			for i := range g {
+				g[i] *= scale
+			}
+		}
+	}
+	b.t++
+	return grads, nil
+}
+
+// endregion method
+
+// region function
+
+// GradNorm 函數會回傳所有參數梯度合起來的 L2 範數，方便觀察是否需要梯度裁剪。
+func GradNorm(params []*mynetwork.Param) float64 {
+	sum := 0.0
+	for _, p := range params {
+		for _, v := range p.Grad.Data {
+			sum += v * v
+		}
+	}
+	return math.Sqrt(sum)
+}
+
+// endregion function
@@ -0,0 +1,147 @@
+// How to use:
+//
+// 1. Testing
+// (1) > cd "%GOPATH%\src\github.com\oneleo\LeNetPractice\mAiLab_0003\myoptimizer"
+// or (1) $ cd "$GOPATH/src/github.com/oneleo/LeNetPractice/mAiLab_0003/myoptimizer"
+// (2) $> go test -v
+
+package myoptimizer
+
+import (
+	"math"
+	"testing"
+
+	"github.com/oneleo/LeNetPractice/mAiLab_0003/mynetwork"
+	"github.com/oneleo/LeNetPractice/mAiLab_0003/mytensor"
+)
+
+// quadratic 會計算 f(w) = Σ c_i*(w_i - 3)² 的梯度並寫入 p.Grad，c_i 讓每個維度的曲率不同。
+func quadratic(p *mynetwork.Param) {
+	for i, w := range p.Value.Data {
+		p.Grad.Data[i] = 2 * float64(i+1) * (w - 3)
+	}
+}
+
+// Test_Converge 是測試每一種最佳化器都能讓二次函數收斂到最小值 w = 3。
+func Test_Converge(t *testing.T) {
+	var tests = []struct {
+		name string
+		opt  Optimizer
+	}{
+		{"sgd", NewSGD(0.05)},
+		{"momentum", NewMomentum(0.02, 0.9)},
+		{"nesterov", NewNesterov(0.02, 0.9)},
+		{"adagrad", NewAdaGrad(0.5)},
+		{"rmsprop", NewRMSProp(0.01)},
+		{"adam", NewAdam(0.05)},
+		{"sdlm", NewSDLM(0.05)},
+	}
+	for _, test := range tests {
+		p := mynetwork.NewParam("w", 4)
+		params := []*mynetwork.Param{p}
+		for step := 0; step < 2000; step++ {
+			mynetwork.ZeroGrads(params)
+			quadratic(p)
+			if sdlm, ok := test.opt.(*SDLM); ok && step%100 == 0 {
+				// 二次函數 (i+1)(w-3)² 的二次導數為 2(i+1)。
+				for i := range p.Hess.Data {
+					p.Hess.Data[i] = 2 * float64(i+1)
+				}
+				sdlm.AccumulateHess(params)
+			}
+			if err := test.opt.Step(params); err != nil {
+				t.Fatal(err)
+			}
+		}
+		for i, w := range p.Value.Data {
+			if math.Abs(w-3) > 1e-2 {
+				t.Errorf("Error %s w[%d] = %g, should converge to 3.", test.name, i, w)
+			}
+		}
+	}
+}
+
+// Test_Options 是測試權重衰減、逐元素裁剪與範數裁剪。
+func Test_Options(t *testing.T) {
+	p := mynetwork.NewParam("w", 2)
+	params := []*mynetwork.Param{p}
+
+	// 權重衰減：梯度為 0 時，w 仍會被 LR*WeightDecay*w 拉向 0。
+	o := NewSGD(0.1)
+	o.WeightDecay = 0.5
+	p.Value.Fill(2)
+	o.Step(params)
+	if p.Value.Data[0] != 1.9 {
+		t.Errorf("Error weight decay w = %g, should be 1.9.", p.Value.Data[0])
+	}
+
+	// 範數裁剪：梯度 (3, 4) 範數為 5，裁剪到 1 後為 (0.6, 0.8)，且不修改 Grad。
+	o = NewSGD(1)
+	o.ClipNorm = 1
+	p.Value.Zero()
+	p.Grad.Data[0], p.Grad.Data[1] = 3, 4
+	o.Step(params)
+	if math.Abs(p.Value.Data[0]+0.6) > 1e-12 || math.Abs(p.Value.Data[1]+0.8) > 1e-12 {
+		t.Errorf("Error clip norm w = %v, should be [-0.6 -0.8].", p.Value.Data)
+	}
+	if GradNorm(params) != 5 {
+		t.Errorf("Error Step should not modify Grad.")
+	}
+
+	// 逐元素裁剪。
+	o = NewSGD(1)
+	o.ClipValue = 2
+	p.Value.Zero()
+	o.Step(params)
+	if p.Value.Data[0] != -2 || p.Value.Data[1] != -2 {
+		t.Errorf("Error clip value w = %v, should be [-2 -2].", p.Value.Data)
+	}
+
+	if err := NewSDLM(0.1).Step(params); err == nil {
+		t.Errorf("Error SDLM without Hessian estimate should return an error.")
+	}
+}
+
+// Test_SetState 是測試還原狀態時會檢查狀態數量與形狀，並複製而不是共用呼叫端的 Tensor。
+func Test_SetState(t *testing.T) {
+	p := mynetwork.NewParam("w", 3)
+	params := []*mynetwork.Param{p}
+	var tests = []struct {
+		name  string
+		opt   Stateful
+		slots [][]*mytensor.Tensor
+		ok    bool
+	}{
+		{"adam", NewAdam(0.1), [][]*mytensor.Tensor{{mytensor.New(3), mytensor.New(3)}}, true},
+		{"adam empty", NewAdam(0.1), [][]*mytensor.Tensor{nil}, true},
+		{"adam one slot", NewAdam(0.1), [][]*mytensor.Tensor{{mytensor.New(3)}}, false},
+		{"momentum two slots", NewMomentum(0.1, 0.9), [][]*mytensor.Tensor{{mytensor.New(3), mytensor.New(3)}}, false},
+		{"sgd one slot", NewSGD(0.1), [][]*mytensor.Tensor{{mytensor.New(3)}}, false},
+		{"rmsprop wrong shape", NewRMSProp(0.1), [][]*mytensor.Tensor{{mytensor.New(4)}}, false},
+		{"adagrad nil slot", NewAdaGrad(0.1), [][]*mytensor.Tensor{{nil}}, false},
+		{"sdlm wrong count", NewSDLM(0.1), [][]*mytensor.Tensor{{mytensor.New(3), mytensor.New(3)}}, false},
+		{"too many parameters", NewSGD(0.1), [][]*mytensor.Tensor{nil, nil}, false},
+	}
+	for _, test := range tests {
+		if err := test.opt.SetState(params, 5, test.slots); (err == nil) != test.ok {
+			t.Errorf("Error %s SetState error = %v, should succeed: %v.", test.name, err, test.ok)
+		}
+	}
+
+	// 還原後更新參數不應修改呼叫端的 Tensor。
+	m, v := mytensor.New(3), mytensor.New(3)
+	opt := NewAdam(0.1)
+	if err := opt.SetState(params, 5, [][]*mytensor.Tensor{{m, v}}); err != nil {
+		t.Fatal(err)
+	}
+	quadratic(p)
+	if err := opt.Step(params); err != nil {
+		t.Fatal(err)
+	}
+	if m.Data[0] != 0 || v.Data[0] != 0 {
+		t.Errorf("Error SetState should copy the slots, got m %v, v %v.", m.Data, v.Data)
+	}
+	if step, slots := opt.State(params); step != 6 || slots[0][0].Data[0] == 0 {
+		t.Errorf("Error Adam state after restore = %d, %v.", step, slots[0][0].Data)
+	}
+}
@@ -0,0 +1,204 @@
+package myoptimizer
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+	"sort"
+)
+
+// region interface
+
+// Schedule 是學習率排程，Rate 會回傳第 epoch 個 epoch（由 0 開始）應使用的學習率。
+type Schedule interface {
+	Rate(epoch int) float64
+}
+
+// PlateauObserver 是需要根據驗證損失調整學習率的排程，每個 epoch 結束時以驗證損失呼叫 Observe。
+type PlateauObserver interface {
+	Observe(loss float64)
+}
+
+// endregion interface
+
+// region variable
+
+var errUnmarshalPlateau = errors.New("Error: invalid ReduceOnPlateau encoding")
+
+// endregion variable
+
+// region struct
+
+// Constant 是固定學習率。
+type Constant struct {
+	LR float64
+}
+
+// StepDecay 每經過 StepSize 個 epoch 就將學習率乘上 Gamma。
+type StepDecay struct {
+	Initial  float64
+	Gamma    float64
+	StepSize int
+}
+
+// Exponential 每個 epoch 都將學習率乘上 Gamma：Initial * Gamma^epoch。
+type Exponential struct {
+	Initial float64
+	Gamma   float64
+}
+
+// Cosine 是餘弦退火：在 Period 個 epoch 內由 Max 沿餘弦曲線降到 Min，之後維持 Min。
+type Cosine struct {
+	Max    float64
+	Min    float64
+	Period int
+}
+
+// Warmup 在前 Epochs 個 epoch 將學習率由 Start 線性增加到 After.Rate(0)，
+// 之後改用 After，且 After 的 epoch 由 0 重新起算。
+type Warmup struct {
+	Epochs int
+	Start  float64
+	After  Schedule
+}
+
+// Piecewise 是分段固定的學習率：epoch < Boundaries[0] 時為 Rates[0]，
+// Boundaries[i-1] <= epoch < Boundaries[i] 時為 Rates[i]，之後為最後一個 Rates。
+// Rates 的長度必須比 Boundaries 多 1。
+type Piecewise struct {
+	Boundaries []int
+	Rates      []float64
+}
+
+// ReduceOnPlateau 在驗證損失連續 Patience 個 epoch 沒有比最佳值再降低 Threshold 以上時，
+// 將學習率乘上 Factor，但不低於 MinLR。
+type ReduceOnPlateau struct {
+	LR        float64
+	Factor    float64
+	Patience  int
+	Threshold float64
+	MinLR     float64
+
+	best    float64
+	bad     int
+	started bool
+}
+
+// endregion struct
+
+// region function
+
+// PaperSchedule 函數會回傳 LeNet 論文搭配 SDLM 使用的全域學習率：
+// 前 2 個 epoch 為 0.0005，接著 3 個為 0.0002，再 3 個為 0.0001，再 4 個為 0.00005，之後為 0.00001。
+func PaperSchedule() *Piecewise {
+	return &Piecewise{
+		Boundaries: []int{2, 5, 8, 12},
+		Rates:      []float64{0.0005, 0.0002, 0.0001, 0.00005, 0.00001},
+	}
+}
+
+// NewReduceOnPlateau 函數會建立初始學習率為 lr、每次乘上 factor、容忍 patience 個 epoch 的排程。
+func NewReduceOnPlateau(lr, factor float64, patience int) *ReduceOnPlateau {
+	return &ReduceOnPlateau{LR: lr, Factor: factor, Patience: patience, Threshold: 1e-4}
+}
+
+// ApplySchedule 函數會將 s 在第 epoch 個 epoch 的學習率設定給 opt，並回傳該學習率。
+func ApplySchedule(opt Optimizer, s Schedule, epoch int) float64 {
+	lr := s.Rate(epoch)
+	opt.SetLearningRate(lr)
+	return lr
+}
+
+// endregion function
+
+// region method
+
+// Rate 會回傳固定的學習率。
+func (s *Constant) Rate(epoch int) float64 { return s.LR }
+
+// Rate 會回傳 Initial * Gamma^(epoch/StepSize)。
+func (s *StepDecay) Rate(epoch int) float64 {
+	if s.StepSize <= 0 {
+		return s.Initial
+	}
+	return s.Initial * math.Pow(s.Gamma, float64(epoch/s.StepSize))
+}
+
+// Rate 會回傳 Initial * Gamma^epoch。
+func (s *Exponential) Rate(epoch int) float64 {
+	return s.Initial * math.Pow(s.Gamma, float64(epoch))
+}
+
+// Rate 會回傳 Min + (Max-Min)*(1+cos(π*epoch/Period))/2。
+func (s *Cosine) Rate(epoch int) float64 {
+	if s.Period <= 0 || epoch >= s.Period {
+		return s.Min
+	}
+	return s.Min + (s.Max-s.Min)*(1+math.Cos(math.Pi*float64(epoch)/float64(s.Period)))/2
+}
+
+// Rate 會回傳暖身期間線性增加的學習率，暖身結束後回傳 After 的學習率。
+func (s *Warmup) Rate(epoch int) float64 {
+	if epoch >= s.Epochs {
+		return s.After.Rate(epoch - s.Epochs)
+	}
+	target := s.After.Rate(0)
+	return s.Start + (target-s.Start)*float64(epoch)/float64(s.Epochs)
+}
+
+// Rate 會回傳 epoch 所在區段的學習率。
+func (s *Piecewise) Rate(epoch int) float64 {
+	// 找出第一個大於 epoch 的邊界，其索引即為區段編號。
+	i := sort.Search(len(s.Boundaries), func(i int) bool { return s.Boundaries[i] > epoch })
+	if i >= len(s.Rates) {
+		i = len(s.Rates) - 1
+	}
+	return s.Rates[i]
+}
+
+// Rate 會回傳目前的學習率；ReduceOnPlateau 只會在 Observe 時改變學習率，與 epoch 無關。
+func (s *ReduceOnPlateau) Rate(epoch int) float64 { return s.LR }
+
+// Observe 會以本 epoch 的驗證損失更新最佳值，必要時降低學習率。
+func (s *ReduceOnPlateau) Observe(loss float64) {
+	if !s.started || loss < s.best-s.Threshold {
+		s.best, s.bad, s.started = loss, 0, true
+		return
+	}
+	if s.bad++; s.bad >= s.Patience {
+		s.LR = math.Max(s.MinLR, s.LR*s.Factor)
+		s.bad = 0
+	}
+}
+
+// MarshalBinary 會將目前的學習率與最佳值、未改善的 epoch 數編碼，讓從檢查點繼續訓練時的學習率與未中斷時相同。
+// Factor、Patience 等設定不會保存。
+func (s *ReduceOnPlateau) MarshalBinary() ([]byte, error) {
+	b := make([]byte, 0, len("plateau:")+8+8+4+1)
+	b = append(b, "plateau:"...)
+	b = binary.BigEndian.AppendUint64(b, math.Float64bits(s.LR))
+	b = binary.BigEndian.AppendUint64(b, math.Float64bits(s.best))
+	b = binary.BigEndian.AppendUint32(b, uint32(s.bad))
+	if s.started {
+		b = append(b, 1)
+	} else {
+		b = append(b, 0)
+	}
+	return b, nil
+}
+
+// UnmarshalBinary 會還原 MarshalBinary 編碼的狀態，內容不正確時不會修改 s 並回傳錯誤。
+func (s *ReduceOnPlateau) UnmarshalBinary(data []byte) error {
+	const prefix = "plateau:"
+	if len(data) != len(prefix)+8+8+4+1 || string(data[:len(prefix)]) != prefix || data[len(data)-1] > 1 {
+		return errUnmarshalPlateau
+	}
+	data = data[len(prefix):]
+	s.LR = math.Float64frombits(binary.BigEndian.Uint64(data))
+	s.best = math.Float64frombits(binary.BigEndian.Uint64(data[8:]))
+	s.bad = int(binary.BigEndian.Uint32(data[16:]))
+	s.started = data[20] == 1
+	return nil
+}
+
+// endregion method
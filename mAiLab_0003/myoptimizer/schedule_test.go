@@ -0,0 +1,89 @@
+package myoptimizer
+
+import (
+	"math"
+	"testing"
+)
+
+// Test_Schedule 是測試各學習率排程在指定 epoch 的學習率。
+func Test_Schedule(t *testing.T) {
+	var tests = []struct {
+		name  string
+		s     Schedule
+		epoch int
+		want  float64
+	}{
+		{"constant", &Constant{LR: 0.1}, 9, 0.1},
+		{"step", &StepDecay{Initial: 1, Gamma: 0.5, StepSize: 3}, 2, 1},
+		{"step", &StepDecay{Initial: 1, Gamma: 0.5, StepSize: 3}, 7, 0.25},
+		{"exponential", &Exponential{Initial: 1, Gamma: 0.9}, 2, 0.81},
+		{"cosine", &Cosine{Max: 1, Min: 0.1, Period: 10}, 0, 1},
+		{"cosine", &Cosine{Max: 1, Min: 0.1, Period: 10}, 5, 0.55},
+		{"cosine", &Cosine{Max: 1, Min: 0.1, Period: 10}, 20, 0.1},
+		{"warmup", &Warmup{Epochs: 4, Start: 0, After: &Constant{LR: 0.2}}, 1, 0.05},
+		{"warmup", &Warmup{Epochs: 4, Start: 0, After: &Exponential{Initial: 0.2, Gamma: 0.5}}, 5, 0.1},
+		{"paper", PaperSchedule(), 0, 0.0005},
+		{"paper", PaperSchedule(), 2, 0.0002},
+		{"paper", PaperSchedule(), 7, 0.0001},
+		{"paper", PaperSchedule(), 11, 0.00005},
+		{"paper", PaperSchedule(), 19, 0.00001},
+	}
+	for _, test := range tests {
+		if got := test.s.Rate(test.epoch); math.Abs(got-test.want) > 1e-12 {
+			t.Errorf("Error %s rate at epoch %d = %g, should be %g.", test.name, test.epoch, got, test.want)
+		}
+	}
+}
+
+// Test_ReduceOnPlateau 是測試驗證損失停滯 Patience 個 epoch 後學習率才會下降，且不低於 MinLR。
+func Test_ReduceOnPlateau(t *testing.T) {
+	s := NewReduceOnPlateau(0.1, 0.5, 2)
+	s.MinLR = 0.03
+	opt := NewSGD(1)
+	var tests = []struct {
+		loss float64
+		want float64
+	}{
+		{1.0, 0.1},
+		{0.9, 0.1},
+		{0.95, 0.1},
+		{0.91, 0.05},
+		{0.92, 0.05},
+		{0.93, 0.03},
+		{0.5, 0.03},
+	}
+	for epoch, test := range tests {
+		s.Observe(test.loss)
+		if lr := ApplySchedule(opt, s, epoch); lr != test.want || opt.LearningRate() != test.want {
+			t.Errorf("Error epoch %d rate = %g, should be %g.", epoch, lr, test.want)
+		}
+	}
+}
+
+// Test_ReduceOnPlateauState 是測試編碼後還原的排程，之後的學習率變化與原本的排程相同；內容不正確時回傳錯誤。
+func Test_ReduceOnPlateauState(t *testing.T) {
+	s := NewReduceOnPlateau(0.1, 0.5, 2)
+	for _, loss := range []float64{1.0, 0.9, 0.95, 0.91, 0.92} {
+		s.Observe(loss)
+	}
+	data, err := s.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := NewReduceOnPlateau(0.1, 0.5, 2)
+	if err = r.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+	for epoch, loss := range []float64{0.93, 0.94, 0.5, 0.6} {
+		s.Observe(loss)
+		r.Observe(loss)
+		if s.Rate(epoch) != r.Rate(epoch) {
+			t.Errorf("Error restored rate = %g, should be %g.", r.Rate(epoch), s.Rate(epoch))
+		}
+	}
+	for _, bad := range [][]byte{nil, data[:len(data)-1], append([]byte("plateaU"), data[7:]...)} {
+		if err = r.UnmarshalBinary(bad); err == nil {
+			t.Errorf("Error invalid encoding %q should return an error.", bad)
+		}
+	}
+}
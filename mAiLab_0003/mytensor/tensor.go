@@ -0,0 +1,145 @@
+package mytensor
+
+import (
+	"errors"
+	"fmt"
+	"image"
+)
+
+// region struct
+
+// Tensor 是以列優先（row-major）順序儲存的多維 float64 陣列。
+// 例如形狀為 [C, H, W] 的特徵圖，第 (c, i, j) 個元素位於 Data[(c*H+i)*W+j]。
+type Tensor struct {
+	// Shape 為每一個維度的大小。
+	Shape []int
+	// Data 為所有元素，長度等於 Shape 各維度的乘積。
+	Data []float64
+}
+
+// endregion struct
+
+// region function
+
+// New 函數會建立一個形狀為 shape 且元素皆為 0 的 Tensor。
+func New(shape ...int) *Tensor {
+	return &Tensor{Shape: append([]int(nil), shape...), Data: make([]float64, size(shape))}
+}
+
+// FromSlice 函數會以 data 作為元素建立形狀為 shape 的 Tensor，data 不會被複製。
+// 若 data 的長度與 shape 不符，則回傳錯誤。
+func FromSlice(data []float64, shape ...int) (t *Tensor, err error) {
+	if len(data) != size(shape) {
+		return nil, fmt.Errorf("Error: data length %d does not match shape %v", len(data), shape)
+	}
+	return &Tensor{Shape: append([]int(nil), shape...), Data: data}, nil
+}
+
+// FromGray 函數會將灰階影像轉成形狀為 [1, H, W] 的 Tensor，像素值 0～255 會縮放至 [0, 1]。
+func FromGray(img *image.Gray) *Tensor {
+	row := img.Bounds().Dy()
+	col := img.Bounds().Dx()
+	t := New(1, row, col)
+	for i := 0; i < row; i++ {
+		for j := 0; j < col; j++ {
+			t.Data[col*i+j] = float64(img.Pix[img.Stride*i+j]) / 255
+		}
+	}
+	return t
+}
+
+// size 函數會回傳 shape 各維度的乘積。
+func size(shape []int) (n int) {
+	n = 1
+	for _, s := range shape {
+		n *= s
+	}
+	return n
+}
+
+// endregion function
+
+// region method
+
+// Len 會回傳 Tensor 的元素總數。
+func (t *Tensor) Len() int { return len(t.Data) }
+
+// Dims 會回傳 Tensor 的維度數。
+func (t *Tensor) Dims() int { return len(t.Shape) }
+
+// Index 會將多維索引 idx 轉換成 Data 內的位置。
+func (t *Tensor) Index(idx ...int) (n int) {
+	for d, i := range idx {
+		n = n*t.Shape[d] + i
+	}
+	return n
+}
+
+// At 會回傳位於多維索引 idx 的元素。
+func (t *Tensor) At(idx ...int) float64 { return t.Data[t.Index(idx...)] }
+
+// Set 會將位於多維索引 idx 的元素設為 v。
+func (t *Tensor) Set(v float64, idx ...int) { t.Data[t.Index(idx...)] = v }
+
+// Zero 會將所有元素清為 0。
+func (t *Tensor) Zero() {
+	for i := range t.Data {
+		t.Data[i] = 0
+	}
+}
+
+// Fill 會將所有元素設為 v。
+func (t *Tensor) Fill(v float64) {
+	for i := range t.Data {
+		t.Data[i] = v
+	}
+}
+
+// Clone 會回傳一個複製所有元素的新 Tensor。
+func (t *Tensor) Clone() *Tensor {
+	return &Tensor{Shape: append([]int(nil), t.Shape...), Data: append([]float64(nil), t.Data...)}
+}
+
+// SameShape 會回傳 t 與 o 的形狀是否完全相同。
+func (t *Tensor) SameShape(o *Tensor) bool {
+	if len(t.Shape) != len(o.Shape) {
+		return false
+	}
+	for d := range t.Shape {
+		if t.Shape[d] != o.Shape[d] {
+			return false
+		}
+	}
+	return true
+}
+
+// Reshape 會回傳一個與 t 共用 Data 但形狀為 shape 的 Tensor。
+// 若元素總數不符，則回傳錯誤。
+func (t *Tensor) Reshape(shape ...int) (r *Tensor, err error) {
+	if size(shape) != len(t.Data) {
+		return nil, fmt.Errorf("Error: cannot reshape %v into %v", t.Shape, shape)
+	}
+	return &Tensor{Shape: append([]int(nil), shape...), Data: t.Data}, nil
+}
+
+// Slice 會回傳第一個維度索引為 i 的子 Tensor（例如 mini-batch 中的第 i 筆樣本），與 t 共用資料。
+func (t *Tensor) Slice(i int) (s *Tensor, err error) {
+	if len(t.Shape) == 0 || i < 0 || i >= t.Shape[0] {
+		return nil, fmt.Errorf("Error: index %d out of range for shape %v", i, t.Shape)
+	}
+	n := len(t.Data) / t.Shape[0]
+	return &Tensor{Shape: append([]int(nil), t.Shape[1:]...), Data: t.Data[i*n : (i+1)*n : (i+1)*n]}, nil
+}
+
+// AddScaled 會將 o 的每一個元素乘上 alpha 後累加至 t（t += alpha*o）。
+func (t *Tensor) AddScaled(alpha float64, o *Tensor) (err error) {
+	if len(t.Data) != len(o.Data) {
+		return errors.New("Error: tensor sizes are not same")
+	}
+	for i, v := range o.Data {
+		t.Data[i] += alpha * v
+	}
+	return nil
+}
+
+// endregion method
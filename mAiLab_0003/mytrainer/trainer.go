@@ -0,0 +1,373 @@
+package mytrainer
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"io"
+	"math"
+
+	"github.com/oneleo/LeNetPractice/mAiLab_0002/rand_fromgo"
+	"github.com/oneleo/LeNetPractice/mAiLab_0003/mymnist"
+	"github.com/oneleo/LeNetPractice/mAiLab_0003/mynetwork"
+	"github.com/oneleo/LeNetPractice/mAiLab_0003/myoptimizer"
+	"github.com/oneleo/LeNetPractice/mAiLab_0003/mytensor"
+)
+
+// region variable
+
+// ErrStop 可由 OnBatch 或 OnEpoch 回傳，讓 Fit 在目前的 mini-batch 或 epoch 結束後正常停止訓練。
+// OnBatch 回傳 ErrStop 時，目前的 epoch 以已訓練的 mini-batch 結算：一樣會計算驗證結果、加入 History、呼叫 OnEpoch，
+// 並將 Trainer.Epoch 加 1，從檢查點繼續訓練時不會重複這個 epoch。
+var ErrStop = errors.New("stop training")
+
+// endregion variable
+
+// region struct
+
+// Config 是訓練流程的設定。
+type Config struct {
+	// Epochs 為最多訓練幾個 epoch。
+	Epochs int
+	// BatchSize 為每個 mini-batch 的樣本數，梯度會取 mini-batch 內的平均。
+	BatchSize int
+	// Seed 為每個 epoch 打亂訓練資料順序所使用 rand_fromgo 亂數產生器的種子。
+	Seed int64
+	// Patience 大於 0 時啟用提早停止：驗證損失連續 Patience 個 epoch 沒有改善就停止，
+	// 並將參數還原成驗證損失最低時的值。提早停止後再呼叫 Fit 不會繼續訓練。
+	Patience int
+	// Schedule 不為 nil 時，每個 epoch 開始前以其設定學習率。
+	Schedule myoptimizer.Schedule
+	// Progress 不為 nil 時，每個 epoch 結束會寫入一行摘要；LogEvery 大於 0 時，每 LogEvery 個 mini-batch 也寫入一行。
+	Progress io.Writer
+	LogEvery int
+	// CurvatureSamples 為最佳化器是 *myoptimizer.SDLM 時，每個 epoch 開始前用來估計曲率的樣本數，
+	// 0 表示使用論文的 500 筆（訓練資料較少時使用全部）。
+	CurvatureSamples int
+}
+
+// BatchStats 是一個 mini-batch 的訓練結果。
+type BatchStats struct {
+	Epoch    int
+	Batch    int
+	Batches  int
+	Loss     float64
+	Accuracy float64
+}
+
+// EpochStats 是一個 epoch 的訓練與驗證結果；沒有驗證資料時 ValLoss、ValAccuracy 為 NaN。
+type EpochStats struct {
+	Epoch         int
+	LearningRate  float64
+	TrainLoss     float64
+	TrainAccuracy float64
+	ValLoss       float64
+	ValAccuracy   float64
+}
+
+// Trainer 以 mini-batch 的方式訓練 Model。
+type Trainer struct {
+	Model     *mynetwork.Model
+	Loss      mynetwork.Loss
+	Optimizer myoptimizer.Optimizer
+	Config    Config
+
+	// Input 會將一張 MNIST 影像轉成模型的輸入，預設為 ImageToInput。
+	Input func(img image.Gray) (*mytensor.Tensor, error)
+	// OnBatch、OnEpoch 不為 nil 時，會在每個 mini-batch、每個 epoch 結束後呼叫；
+	// 回傳 ErrStop 會停止訓練，回傳其他錯誤則會讓 Fit 回傳該錯誤。
+	OnBatch func(s BatchStats) error
+	OnEpoch func(s EpochStats) error
+
+	// History 為每個 epoch 的結果。
+	History []EpochStats
+	// Epoch 為下一個要訓練的 epoch，從檢查點繼續訓練時由此接續。
+	Epoch int
+	// Rand 為打亂資料順序的亂數產生器，第一次 Fit 時以 Config.Seed 建立。
+	Rand *rand_fromgo.Rand
+	// Best、Bad 與 BestParams 為提早停止的狀態：目前最低的損失、連續沒有改善的 epoch 數，
+	// 以及損失最低時的參數值；BestParams 為 nil 時表示還沒有最低損失，Best 視為 +Inf。
+	// 從檢查點繼續訓練時由此接續，提早停止的時機與未中斷的訓練相同。
+	Best       float64
+	Bad        int
+	BestParams []*mytensor.Tensor
+}
+
+// endregion struct
+
+// region function
+
+// New 函數會建立一個 Trainer。
+func New(model *mynetwork.Model, loss mynetwork.Loss, opt myoptimizer.Optimizer, cfg Config) *Trainer {
+	return &Trainer{Model: model, Loss: loss, Optimizer: opt, Config: cfg, Input: ImageToInput}
+}
+
+// ImageToInput 函數會將 28x28 的 MNIST 影像置中補零成 LeNet-5 的 32x32 輸入，像素值縮放至 [0, 1]。
+func ImageToInput(img image.Gray) (t *mytensor.Tensor, err error) {
+	padded, err := mymnist.ImgAddZero(img, mynetwork.InputRows, mynetwork.InputCols)
+	if err != nil {
+		return nil, err
+	}
+	return mytensor.FromGray(padded), nil
+}
+
+// snapshot 函數會複製所有參數值。
+func snapshot(params []*mynetwork.Param) (values []*mytensor.Tensor) {
+	values = make([]*mytensor.Tensor, len(params))
+	for i, p := range params {
+		values[i] = p.Value.Clone()
+	}
+	return values
+}
+
+// restore 函數會將 snapshot 複製的參數值寫回 params。
+func restore(params []*mynetwork.Param, values []*mytensor.Tensor) {
+	if values == nil {
+		return
+	}
+	for i, p := range params {
+		copy(p.Value.Data, values[i].Data)
+	}
+}
+
+// endregion function
+
+// region method
+
+// Fit 會以 train 訓練模型，每個 epoch 結束後若 val 不為 nil 則計算驗證損失與正確率。
+// 回傳值為每個 epoch 的結果（同 t.History）。
+func (t *Trainer) Fit(train, val *mymnist.Dataset) (history []EpochStats, err error) {
+	if t.Config.BatchSize <= 0 {
+		return nil, errors.New("Error: batch size must be positive")
+	}
+	if train.Len() == 0 {
+		return nil, errors.New("Error: training set is empty")
+	}
+	if t.Rand == nil {
+		t.Rand = rand_fromgo.New(rand_fromgo.NewSource(t.Config.Seed))
+	}
+	if t.Input == nil {
+		t.Input = ImageToInput
+	}
+	params := t.Model.Params()
+	if t.BestParams == nil {
+		t.Best = math.Inf(1)
+	}
+	if t.Config.Patience > 0 && t.Bad >= t.Config.Patience {
+		return t.History, nil
+	}
+
+	for ; t.Epoch < t.Config.Epochs; t.Epoch++ {
+		stats, err := t.trainEpoch(train, params)
+		// stopped 為 true 時，仍完成這個 epoch 的結算後才停止。
+		stopped := err == ErrStop
+		if err != nil && !stopped {
+			return t.History, err
+		}
+		if val != nil {
+			if stats.ValLoss, stats.ValAccuracy, err = t.Evaluate(val); err != nil {
+				return t.History, err
+			}
+		}
+		t.History = append(t.History, stats)
+		t.logf("epoch %d: lr %.3g, train loss %.4f, acc %.4f, val loss %.4f, acc %.4f\n",
+			stats.Epoch+1, stats.LearningRate, stats.TrainLoss, stats.TrainAccuracy, stats.ValLoss, stats.ValAccuracy)
+
+		// 驗證損失不存在時，改以訓練損失驅動 ReduceOnPlateau 與提早停止。
+		monitor := stats.ValLoss
+		if math.IsNaN(monitor) {
+			monitor = stats.TrainLoss
+		}
+		if obs, ok := t.Config.Schedule.(myoptimizer.PlateauObserver); ok {
+			obs.Observe(monitor)
+		}
+		if t.OnEpoch != nil {
+			if err = t.OnEpoch(stats); err == ErrStop {
+				stopped = true
+			} else if err != nil {
+				return t.History, err
+			}
+		}
+		if t.Config.Patience > 0 {
+			if monitor < t.Best {
+				t.Best, t.Bad = monitor, 0
+				t.BestParams = snapshot(params)
+			} else if t.Bad++; t.Bad >= t.Config.Patience {
+				t.logf("early stopping after epoch %d, best loss %.4f\n", stats.Epoch+1, t.Best)
+				restore(params, t.BestParams)
+				stopped = true
+			}
+		}
+		if stopped {
+			t.Epoch++
+			break
+		}
+	}
+	return t.History, nil
+}
+
+// trainEpoch 會以打亂後的順序訓練一個 epoch。
+func (t *Trainer) trainEpoch(train *mymnist.Dataset, params []*mynetwork.Param) (stats EpochStats, err error) {
+	stats.Epoch = t.Epoch
+	stats.ValLoss, stats.ValAccuracy = math.NaN(), math.NaN()
+	if t.Config.Schedule != nil {
+		myoptimizer.ApplySchedule(t.Optimizer, t.Config.Schedule, t.Epoch)
+	}
+	stats.LearningRate = t.Optimizer.LearningRate()
+
+	idx := t.Rand.Perm(train.Len())
+	if sdlm, ok := t.Optimizer.(*myoptimizer.SDLM); ok {
+		if err = t.estimateCurvature(sdlm, train, idx, params); err != nil {
+			return stats, err
+		}
+	}
+
+	size := t.Config.BatchSize
+	batches := (len(idx) + size - 1) / size
+	seen, correct := 0, 0
+	for b := 0; b < batches; b++ {
+		end := (b + 1) * size
+		if end > len(idx) {
+			end = len(idx)
+		}
+		mynetwork.ZeroGrads(params)
+		bs := BatchStats{Epoch: t.Epoch, Batch: b, Batches: batches}
+		hit := 0
+		for _, i := range idx[b*size : end] {
+			loss, ok, err := t.sample(train.Images[i], train.Labels[i], true)
+			if err != nil {
+				return stats, fmt.Errorf("sample %d: %v", i, err)
+			}
+			bs.Loss += loss
+			if ok {
+				hit++
+			}
+		}
+		n := end - b*size
+		// 梯度取 mini-batch 內的平均，使學習率不受 BatchSize 影響。
+		for _, p := range params {
+			for k := range p.Grad.Data {
+				p.Grad.Data[k] /= float64(n)
+			}
+		}
+		if err = t.Optimizer.Step(params); err != nil {
+			return stats, err
+		}
+
+		stats.TrainLoss += bs.Loss
+		seen += n
+		correct += hit
+		bs.Loss /= float64(n)
+		bs.Accuracy = float64(hit) / float64(n)
+		if t.Config.LogEvery > 0 && (b+1)%t.Config.LogEvery == 0 {
+			t.logf("epoch %d batch %d/%d: loss %.4f, acc %.4f\n", t.Epoch+1, b+1, batches, bs.Loss, bs.Accuracy)
+		}
+		if t.OnBatch != nil {
+			if err = t.OnBatch(bs); err != nil {
+				stats.TrainLoss /= float64(seen)
+				stats.TrainAccuracy = float64(correct) / float64(seen)
+				return stats, err
+			}
+		}
+	}
+	stats.TrainLoss /= float64(seen)
+	stats.TrainAccuracy = float64(correct) / float64(seen)
+	return stats, nil
+}
+
+// estimateCurvature 會如論文在每個 epoch 開始前，以打亂後順序的前 Config.CurvatureSamples 筆樣本
+// 逐筆以 Backward2 反向傳播損失的二次導數並更新 SDLM 的曲率估計，不會更新參數；
+// 損失函數必須實作 mynetwork.Loss2。
+func (t *Trainer) estimateCurvature(sdlm *myoptimizer.SDLM, train *mymnist.Dataset, idx []int, params []*mynetwork.Param) (err error) {
+	loss, ok := t.Loss.(mynetwork.Loss2)
+	if !ok {
+		return fmt.Errorf("Error: SDLM needs a loss with second derivatives, got %T", t.Loss)
+	}
+	n := t.Config.CurvatureSamples
+	if n <= 0 {
+		n = 500
+	}
+	if n > len(idx) {
+		n = len(idx)
+	}
+	for _, i := range idx[:n] {
+		if err = t.curvature(loss, train.Images[i], train.Labels[i], params); err != nil {
+			return fmt.Errorf("sample %d: %v", i, err)
+		}
+		sdlm.AccumulateHess(params)
+	}
+	return nil
+}
+
+// curvature 會將一筆樣本損失對每一個參數的二次導數寫入參數的 Hess。
+func (t *Trainer) curvature(loss mynetwork.Loss2, img image.Gray, label byte, params []*mynetwork.Param) (err error) {
+	in, err := t.Input(img)
+	if err != nil {
+		return err
+	}
+	out, err := t.Model.Forward(in)
+	if err != nil {
+		return err
+	}
+	hess, err := loss.Hess(out, label)
+	if err != nil {
+		return err
+	}
+	mynetwork.ZeroHess(params)
+	_, err = t.Model.Backward2(hess)
+	return err
+}
+
+// sample 會計算一筆樣本的損失與是否預測正確；backward 為 true 時同時將梯度累加至參數。
+func (t *Trainer) sample(img image.Gray, label byte, backward bool) (loss float64, ok bool, err error) {
+	in, err := t.Input(img)
+	if err != nil {
+		return 0, false, err
+	}
+	out, err := t.Model.Forward(in)
+	if err != nil {
+		return 0, false, err
+	}
+	loss, grad, err := t.Loss.Loss(out, label)
+	if err != nil {
+		return 0, false, err
+	}
+	ok = t.Model.Label(out) == int(label)
+	if backward {
+		if _, err = t.Model.Backward(grad); err != nil {
+			return 0, false, err
+		}
+	}
+	return loss, ok, nil
+}
+
+// Evaluate 會回傳模型在 ds 上的平均損失與正確率，不會更新參數。
+func (t *Trainer) Evaluate(ds *mymnist.Dataset) (loss, accuracy float64, err error) {
+	if ds.Len() == 0 {
+		return math.NaN(), math.NaN(), nil
+	}
+	if t.Input == nil {
+		t.Input = ImageToInput
+	}
+	correct := 0
+	for i := range ds.Labels {
+		l, ok, err := t.sample(ds.Images[i], ds.Labels[i], false)
+		if err != nil {
+			return 0, 0, fmt.Errorf("sample %d: %v", i, err)
+		}
+		loss += l
+		if ok {
+			correct++
+		}
+	}
+	return loss / float64(ds.Len()), float64(correct) / float64(ds.Len()), nil
+}
+
+// logf 會在 Config.Progress 不為 nil 時寫入一行進度。
+func (t *Trainer) logf(format string, args ...interface{}) {
+	if t.Config.Progress != nil {
+		fmt.Fprintf(t.Config.Progress, format, args...)
+	}
+}
+
+// endregion method
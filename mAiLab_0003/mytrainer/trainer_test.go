@@ -0,0 +1,188 @@
+// How to use:
+//
+// 1. Testing
+// (1) > cd "%GOPATH%\src\github.com\oneleo\LeNetPractice\mAiLab_0003\mytrainer"
+// or (1) $ cd "$GOPATH/src/github.com/oneleo/LeNetPractice/mAiLab_0003/mytrainer"
+// (2) $> go test -v
+
+package mytrainer
+
+import (
+	"bytes"
+	"image"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/oneleo/LeNetPractice/mAiLab_0002/rand_fromgo"
+	"github.com/oneleo/LeNetPractice/mAiLab_0003/mymnist"
+	"github.com/oneleo/LeNetPractice/mAiLab_0003/mynetwork"
+	"github.com/oneleo/LeNetPractice/mAiLab_0003/myoptimizer"
+	"github.com/oneleo/LeNetPractice/mAiLab_0003/mytensor"
+)
+
+// toyDataset 會產生 n 張 28x28 的測試影像：label 0 為左半邊的直線，label 1 為右半邊的直線，位置隨機。
+func toyDataset(n int, seed int64) *mymnist.Dataset {
+	rnd := rand_fromgo.New(rand_fromgo.NewSource(seed))
+	ds := &mymnist.Dataset{}
+	for i := 0; i < n; i++ {
+		img := image.NewGray(image.Rect(0, 0, 28, 28))
+		label := byte(i % 2)
+		col := 4 + int(rnd.Float64()*8) + 12*int(label)
+		for r := 4; r < 24; r++ {
+			img.Pix[r*28+col] = 255
+		}
+		ds.Images = append(ds.Images, *img)
+		ds.Labels = append(ds.Labels, label)
+	}
+	return ds
+}
+
+// newTrainer 會建立一個以 Adam 訓練現代 LeNet-5 的 Trainer。
+func newTrainer(cfg Config) *Trainer {
+	model, _ := mynetwork.NewLeNet5(mynetwork.ModernConfig(), rand_fromgo.New(rand_fromgo.NewSource(1)))
+	return New(model, mynetwork.NewCrossEntropy(), myoptimizer.NewAdam(0.005), cfg)
+}
+
+// Test_Fit 是測試訓練後正確率提升、進度輸出，以及相同種子得到完全相同的結果。
+func Test_Fit(t *testing.T) {
+	train, val := toyDataset(40, 1), toyDataset(20, 2)
+	var logs [2]bytes.Buffer
+	var history [2][]EpochStats
+	for run := range history {
+		tr := newTrainer(Config{Epochs: 6, BatchSize: 8, Seed: 3, Progress: &logs[run]})
+		batches := 0
+		tr.OnBatch = func(s BatchStats) error {
+			batches++
+			return nil
+		}
+		h, err := tr.Fit(train, val)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if batches != 6*5 {
+			t.Errorf("Error OnBatch called %d times, should be 30.", batches)
+		}
+		history[run] = h
+	}
+	last := history[0][len(history[0])-1]
+	if last.ValAccuracy < 0.9 || last.TrainLoss >= history[0][0].TrainLoss {
+		t.Errorf("Error training did not improve: %+v.", history[0])
+	}
+	for e := range history[0] {
+		if history[0][e] != history[1][e] {
+			t.Errorf("Error epoch %d differs between runs with the same seed.", e)
+		}
+	}
+	if lines := strings.Count(logs[0].String(), "\n"); lines != 6 {
+		t.Errorf("Error progress has %d lines, should be one per epoch:\n%s", lines, logs[0].String())
+	}
+}
+
+// Test_EarlyStopping 是測試驗證損失不再下降時會提早停止，以及 OnEpoch 回傳 ErrStop 會停止訓練。
+func Test_EarlyStopping(t *testing.T) {
+	train := toyDataset(16, 1)
+	tr := newTrainer(Config{Epochs: 50, BatchSize: 4, Seed: 3, Patience: 2})
+	// 學習率為 0 時參數不變，驗證損失不會改善。
+	tr.Optimizer.SetLearningRate(0)
+	h, err := tr.Fit(train, train)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(h) != 3 {
+		t.Errorf("Error trained %d epochs, should stop after 3.", len(h))
+	}
+	// 提早停止後再呼叫 Fit 不會繼續訓練。
+	if h, err = tr.Fit(train, train); err != nil || len(h) != 3 {
+		t.Errorf("Error Fit after early stopping trained %d epochs (%v), should stay at 3.", len(h), err)
+	}
+
+	// 以 ErrStop 中斷後繼續 Fit，提早停止的計數會接續而不是重新開始：
+	// 第 0 個 epoch 為最低損失，第 1 個 epoch 後中斷，之後再 2 個 epoch 沒有改善就停止。
+	tr = newTrainer(Config{Epochs: 50, BatchSize: 4, Seed: 3, Patience: 3})
+	tr.Optimizer.SetLearningRate(0)
+	tr.OnEpoch = func(s EpochStats) error {
+		if s.Epoch == 1 {
+			return ErrStop
+		}
+		return nil
+	}
+	if _, err = tr.Fit(train, train); err != nil {
+		t.Fatal(err)
+	}
+	if tr.Bad != 1 || tr.BestParams == nil || tr.Best != tr.History[0].ValLoss {
+		t.Errorf("Error early stopping state best %g, bad %d after ErrStop.", tr.Best, tr.Bad)
+	}
+	if h, err = tr.Fit(train, train); err != nil || len(h) != 4 {
+		t.Errorf("Error resumed Fit trained %d epochs (%v), should stop after 4.", len(h), err)
+	}
+
+	tr = newTrainer(Config{Epochs: 50, BatchSize: 4, Seed: 3})
+	tr.OnEpoch = func(s EpochStats) error {
+		if s.Epoch == 1 {
+			return ErrStop
+		}
+		return nil
+	}
+	if h, _ = tr.Fit(train, nil); len(h) != 2 || tr.Epoch != 2 {
+		t.Errorf("Error ErrStop should stop after 2 epochs, got %d.", len(h))
+	}
+
+	// OnBatch 回傳 ErrStop 時，部分訓練的 epoch 一樣要驗證、呼叫 OnEpoch 並前進到下一個 epoch，
+	// 之後繼續 Fit 不會重複同一個 epoch。
+	tr = newTrainer(Config{Epochs: 3, BatchSize: 4, Seed: 3})
+	tr.OnBatch = func(s BatchStats) error {
+		if s.Epoch == 1 && s.Batch == 1 {
+			return ErrStop
+		}
+		return nil
+	}
+	epochs := 0
+	tr.OnEpoch = func(s EpochStats) error {
+		epochs++
+		return nil
+	}
+	if h, err = tr.Fit(train, train); err != nil {
+		t.Fatal(err)
+	}
+	if len(h) != 2 || tr.Epoch != 2 || epochs != 2 || math.IsNaN(h[1].ValLoss) {
+		t.Errorf("Error OnBatch ErrStop: %d epochs, Epoch %d, OnEpoch %d times, %+v.", len(h), tr.Epoch, epochs, h)
+	}
+	tr.OnBatch = nil
+	if h, err = tr.Fit(train, train); err != nil {
+		t.Fatal(err)
+	}
+	if len(h) != 3 || h[2].Epoch != 2 {
+		t.Errorf("Error resumed Fit should continue with epoch 2, got %+v.", h)
+	}
+}
+
+// Test_FitSDLM 是測試以 SDLM 訓練時，每個 epoch 開始前會自動估計曲率，不需要呼叫端處理。
+func Test_FitSDLM(t *testing.T) {
+	train, val := toyDataset(40, 1), toyDataset(20, 2)
+	model, _ := mynetwork.NewLeNet5(mynetwork.ModernConfig(), rand_fromgo.New(rand_fromgo.NewSource(1)))
+	tr := New(model, mynetwork.NewCrossEntropy(), myoptimizer.NewSDLM(0.0005), Config{Epochs: 6, BatchSize: 8, Seed: 3, CurvatureSamples: 20})
+	h, err := tr.Fit(train, val)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if last := h[len(h)-1]; last.TrainLoss >= h[0].TrainLoss {
+		t.Errorf("Error SDLM training did not improve: %+v.", h)
+	}
+
+	// 損失函數沒有二次導數時無法估計曲率，回傳錯誤。
+	tr = New(model, plainLoss{mynetwork.NewCrossEntropy()}, myoptimizer.NewSDLM(0.0005), Config{Epochs: 1, BatchSize: 8, Seed: 3})
+	if _, err = tr.Fit(train, nil); err == nil {
+		t.Error("Error SDLM with a loss without Hess should return an error.")
+	}
+}
+
+// plainLoss 只保留 Loss 的方法，用來確認 SDLM 需要 mynetwork.Loss2。
+type plainLoss struct {
+	loss mynetwork.Loss
+}
+
+// Loss 會回傳包裝的損失函數的結果。
+func (l plainLoss) Loss(out *mytensor.Tensor, label byte) (loss float64, grad *mytensor.Tensor, err error) {
+	return l.loss.Loss(out, label)
+}